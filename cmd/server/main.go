@@ -14,6 +14,7 @@ import (
 	"linke/internal/logger"
 	"linke/internal/middleware"
 	"linke/internal/migration"
+	"linke/internal/model"
 	"linke/internal/queue"
 	"linke/internal/repository"
 	"linke/internal/response"
@@ -36,6 +37,10 @@ import (
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Personal access token (lk_...) created at /user/tokens. Only accepted by endpoints that list this scheme, and only with the matching scope.
 func main() {
 	cfg := config.LoadConfig()
 
@@ -65,36 +70,116 @@ func main() {
 	}
 
 	taskQueue := queue.NewTaskQueue(db.Redis)
+	for queueName, priority := range cfg.Queue.Priorities {
+		taskQueue.SetQueuePriority(queueName, priority)
+	}
+	for taskType, queueName := range cfg.Queue.Routes {
+		taskQueue.RouteTaskType(taskType, queueName)
+	}
+	if err := taskQueue.ValidateRoutes(); err != nil {
+		logger.Fatal("Invalid queue configuration", logger.Error2("error", err))
+	}
 	processor := queue.NewTaskProcessor(taskQueue)
 	processor.RegisterHandler("email", queue.EmailTaskHandler)
 	processor.RegisterHandler("notification", queue.NotificationTaskHandler)
 	processor.RegisterHandler("data_processing", queue.DataProcessingTaskHandler)
+	for queueName, limit := range cfg.Queue.Concurrency {
+		processor.SetQueueConcurrency(queueName, limit)
+	}
+	for taskType, limit := range cfg.Queue.TaskTypeConcurrency {
+		processor.SetTypeConcurrency(taskType, limit)
+	}
+
+	scheduler := queue.NewScheduler(db.Redis, taskQueue)
+	if err := scheduler.Register("nightly-cleanup", "0 3 * * *", "data_processing", map[string]interface{}{
+		"data_type": "cleanup",
+	}); err != nil {
+		logger.Fatal("Failed to register schedule", logger.Error2("error", err))
+	}
+	if err := scheduler.Register("weekly-digest", "0 8 * * 1", "data_processing", map[string]interface{}{
+		"data_type": "weekly_digest",
+	}); err != nil {
+		logger.Fatal("Failed to register schedule", logger.Error2("error", err))
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	go processor.ProcessTasks(ctx, "default")
+	processor.Start(ctx, cfg.Queue.Workers, cfg.Queue.StrictPriority)
+	go scheduler.Run(ctx)
 
-	userService := service.NewUserService(db.DB)
-	jwtService := service.NewJWTService(cfg)
-	inviteCodeService := service.NewInviteCodeService(db.DB)
+	auditService := service.NewAuditService(db.DB)
+	userService := service.NewUserService(db.DB, auditService)
+	jwtService, err := service.NewJWTService(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize JWT service", logger.Error2("error", err))
+	}
+	inviteCodeService := service.NewInviteCodeService(db.DB, auditService)
 	inviteCodeUsageService := service.NewInviteCodeUsageService(db.DB)
-	authService := service.NewAuthService(db.DB, userService, jwtService, inviteCodeService)
+	refreshTokenService := service.NewRefreshTokenService(db.DB, cfg)
+	tokenRevocationService := service.NewTokenRevocationService(db.Redis)
+	sessionService := service.NewSessionService(db.DB, refreshTokenService, auditService)
+	mfaService, err := service.NewMFAService(db.DB, cfg, db.Redis, auditService)
+	if err != nil {
+		logger.Fatal("Failed to initialize MFA service", logger.Error2("error", err))
+	}
+	passkeyService, err := service.NewPasskeyService(db.DB, cfg, db.Redis, auditService)
+	if err != nil {
+		logger.Fatal("Failed to initialize passkey service", logger.Error2("error", err))
+	}
+	emailService := service.NewEmailService(taskQueue)
+	emailVerificationService := service.NewEmailVerificationService(db.DB, cfg, db.Redis, userService, jwtService, tokenRevocationService, emailService)
+	passwordHasher, err := service.NewPasswordHasher(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize password hasher", logger.Error2("error", err))
+	}
+	passwordPolicyService, err := service.NewPasswordPolicyService(db.DB, cfg, passwordHasher)
+	if err != nil {
+		logger.Fatal("Failed to initialize password policy", logger.Error2("error", err))
+	}
+	passwordResetService := service.NewPasswordResetService(db.DB, cfg, db.Redis, userService, sessionService, emailService, passwordPolicyService, passwordHasher, auditService)
+	magicLinkService := service.NewMagicLinkService(cfg, db.Redis, userService, jwtService, tokenRevocationService, emailService)
+	loginGuardService := service.NewLoginGuardService(cfg, db.Redis, auditService)
+	personalAccessTokenService := service.NewPersonalAccessTokenService(db.DB, auditService)
+	roleService := service.NewRoleService(db.DB, auditService)
+	organizationService := service.NewOrganizationService(db.DB, inviteCodeService, auditService)
+	authService := service.NewAuthService(db.DB, userService, jwtService, inviteCodeService, refreshTokenService, tokenRevocationService, sessionService, mfaService, passkeyService, emailVerificationService, magicLinkService, loginGuardService, passwordPolicyService, passwordHasher, personalAccessTokenService, auditService)
 	
-	authHandler := handler.NewAuthHandler(cfg, db, authService, jwtService)
-	taskHandler := handler.NewTaskHandler(taskQueue)
-	adminUserHandler := handler.NewAdminUserHandler(userService)
-	userProfileHandler := handler.NewUserProfileHandler(userService)
-	inviteCodeHandler := handler.NewInviteCodeHandler(inviteCodeService, inviteCodeUsageService)
+	oauthService := service.NewOAuthService(cfg, db.Redis)
+	identityService := service.NewIdentityService(db.DB, auditService)
+	impersonationService := service.NewImpersonationService(cfg, userService, roleService, sessionService, jwtService, auditService)
+
+	authHandler := handler.NewAuthHandler(cfg, db, oauthService, authService, jwtService, identityService)
+	taskHandler := handler.NewTaskHandler(taskQueue, roleService)
+	adminUserHandler := handler.NewAdminUserHandler(userService, roleService)
+	userProfileHandler := handler.NewUserProfileHandler(userService, authService)
+	inviteCodeHandler := handler.NewInviteCodeHandler(inviteCodeService, inviteCodeUsageService, roleService)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	mfaHandler := handler.NewMFAHandler(mfaService, userService)
+	passkeyHandler := handler.NewPasskeyHandler(passkeyService, authService)
+	emailVerificationHandler := handler.NewEmailVerificationHandler(emailVerificationService)
+	passwordResetHandler := handler.NewPasswordResetHandler(passwordResetService)
+	magicLinkHandler := handler.NewMagicLinkHandler(magicLinkService, authService)
+	loginLockoutHandler := handler.NewLoginLockoutHandler(loginGuardService, userService)
+	identityHandler := handler.NewIdentityHandler(identityService, oauthService)
+	personalAccessTokenHandler := handler.NewPersonalAccessTokenHandler(personalAccessTokenService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	auditLogHandler := handler.NewAuditLogHandler(auditService)
+	scheduleHandler := handler.NewScheduleHandler(scheduler)
+	impersonationHandler := handler.NewImpersonationHandler(impersonationService, userService)
+	organizationHandler := handler.NewOrganizationHandler(organizationService, inviteCodeService, authService)
 
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 
+	r.Use(middleware.RequestID())
 	r.Use(middleware.Logger())
 	r.Use(middleware.CORS())
 	r.Use(gin.Recovery())
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	r.GET("/.well-known/jwks.json", authHandler.GetJWKS)
+
 	r.GET("/health", func(c *gin.Context) {
 		response.Success(c, gin.H{
 			"status": "ok",
@@ -108,8 +193,8 @@ func main() {
 			response.SuccessWithMessage(c, "pong", nil)
 		})
 
-		v1.POST("/tasks", middleware.AuthMiddleware(authService), taskHandler.CreateTask)
-		v1.GET("/tasks/status", middleware.AuthMiddleware(authService), taskHandler.GetQueueStatus)
+		v1.POST("/tasks", middleware.AuthMiddleware(authService, model.ScopeTasksWrite), middleware.RequirePermission(roleService, model.PermissionTasksEnqueue), taskHandler.CreateTask)
+		v1.GET("/tasks/status", middleware.AuthMiddleware(authService, model.ScopeTasksRead), middleware.RequirePermission(roleService, model.PermissionTasksRead), taskHandler.GetQueueStatus)
 		
 		// Authentication routes
 		auth := v1.Group("/auth")
@@ -123,42 +208,98 @@ func main() {
 			// Local authentication routes
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.LoginLocal)
+			auth.GET("/verify-email", emailVerificationHandler.VerifyEmail)
+			auth.POST("/verify-email/resend", emailVerificationHandler.ResendVerification)
+			auth.POST("/forgot-password", passwordResetHandler.ForgotPassword)
+			auth.POST("/reset-password", passwordResetHandler.ResetPassword)
+			auth.POST("/magic-link", magicLinkHandler.RequestMagicLink)
+			auth.POST("/magic-link/verify", magicLinkHandler.VerifyMagicLink)
+			auth.POST("/mfa/verify", authHandler.VerifyMFA)
+			auth.POST("/passkey/login/begin", passkeyHandler.BeginLogin)
+			auth.POST("/passkey/login/finish", passkeyHandler.FinishLogin)
 			auth.POST("/logout", middleware.AuthMiddleware(authService), authHandler.Logout)
+			auth.POST("/logout-all", middleware.AuthMiddleware(authService), middleware.BlockImpersonation(), authHandler.LogoutAll)
 			auth.POST("/refresh", authHandler.RefreshToken)
-			auth.POST("/change-password", middleware.AuthMiddleware(authService), authHandler.ChangePassword)
+			auth.POST("/change-password", middleware.AuthMiddleware(authService), middleware.BlockImpersonation(), authHandler.ChangePassword)
 			auth.GET("/profile", middleware.AuthMiddleware(authService), authHandler.GetProfile)
 		}
 
 		
-		// Admin routes - require admin privileges
+		// Admin routes - each route requires a permission of the user's role
 		admin := v1.Group("/admin")
 		admin.Use(middleware.AuthMiddleware(authService))
-		admin.Use(middleware.RequireAdmin())
+		admin.Use(middleware.BlockImpersonation())
+		admin.Use(middleware.RequireMFAEnrollment(mfaService))
 		{
+			readUsers := middleware.RequirePermission(roleService, model.PermissionUsersRead)
+			writeUsers := middleware.RequirePermission(roleService, model.PermissionUsersWrite)
+			deleteUsers := middleware.RequirePermission(roleService, model.PermissionUsersDelete)
+			manageUserSecurity := middleware.RequirePermission(roleService, model.PermissionUsersSecurity)
+			impersonateUsers := middleware.RequirePermission(roleService, model.PermissionUsersImpersonate)
+			readRoles := middleware.RequirePermission(roleService, model.PermissionRolesRead)
+			manageRoles := middleware.RequirePermission(roleService, model.PermissionRolesManage)
+			assignRoles := middleware.RequirePermission(roleService, model.PermissionRolesAssign)
+			manageInviteCodes := middleware.RequirePermission(roleService, model.PermissionInviteCodesManage)
+			readAuditLogs := middleware.RequirePermission(roleService, model.PermissionAuditLogsRead)
+			readSchedules := middleware.RequirePermission(roleService, model.PermissionSchedulesRead)
+			manageSchedules := middleware.RequirePermission(roleService, model.PermissionSchedulesManage)
+
 			// Admin user management routes
 			adminUsers := admin.Group("/users")
 			{
-				adminUsers.GET("", adminUserHandler.ListUsers)
-				adminUsers.GET("/deleted", adminUserHandler.ListDeletedUsers)
-				adminUsers.GET("/search", adminUserHandler.SearchUsers)
-				adminUsers.GET("/stats", adminUserHandler.GetUserStats)
-				adminUsers.GET("/provider", adminUserHandler.ListUsersByProvider)
-				adminUsers.GET("/:id", adminUserHandler.GetUser)
-				adminUsers.PUT("/:id", adminUserHandler.UpdateUser)
-				adminUsers.PUT("/:id/role", adminUserHandler.UpdateUserRole)
-				adminUsers.PUT("/:id/status", adminUserHandler.UpdateUserStatus)
-				adminUsers.DELETE("/:id", adminUserHandler.SoftDeleteUser)
-				adminUsers.POST("/:id/restore", adminUserHandler.RestoreUser)
-				adminUsers.DELETE("/:id/hard-delete", adminUserHandler.HardDeleteUser)
-				adminUsers.POST("/batch/delete", adminUserHandler.BatchDeleteUsers)
-				adminUsers.POST("/batch/restore", adminUserHandler.BatchRestoreUsers)
+				adminUsers.GET("", readUsers, adminUserHandler.ListUsers)
+				adminUsers.GET("/deleted", readUsers, adminUserHandler.ListDeletedUsers)
+				adminUsers.GET("/search", readUsers, adminUserHandler.SearchUsers)
+				adminUsers.GET("/stats", readUsers, adminUserHandler.GetUserStats)
+				adminUsers.GET("/provider", readUsers, adminUserHandler.ListUsersByProvider)
+				adminUsers.GET("/:id", readUsers, adminUserHandler.GetUser)
+				adminUsers.PUT("/:id", writeUsers, adminUserHandler.UpdateUser)
+				adminUsers.PUT("/:id/role", assignRoles, adminUserHandler.UpdateUserRole)
+				adminUsers.PUT("/:id/status", writeUsers, adminUserHandler.UpdateUserStatus)
+				adminUsers.DELETE("/:id", deleteUsers, adminUserHandler.SoftDeleteUser)
+				adminUsers.POST("/:id/restore", writeUsers, adminUserHandler.RestoreUser)
+				adminUsers.DELETE("/:id/hard-delete", deleteUsers, adminUserHandler.HardDeleteUser)
+				adminUsers.POST("/batch/delete", deleteUsers, adminUserHandler.BatchDeleteUsers)
+				adminUsers.POST("/batch/restore", writeUsers, adminUserHandler.BatchRestoreUsers)
+				adminUsers.GET("/:id/sessions", readUsers, sessionHandler.ListUserSessions)
+				adminUsers.DELETE("/:id/sessions/:session_id", manageUserSecurity, sessionHandler.RevokeUserSession)
+				adminUsers.DELETE("/:id/mfa", manageUserSecurity, mfaHandler.ResetUserMFA)
+				adminUsers.GET("/:id/lockout", readUsers, loginLockoutHandler.GetUserLockout)
+				adminUsers.DELETE("/:id/lockout", manageUserSecurity, loginLockoutHandler.ClearUserLockout)
+				adminUsers.POST("/:id/impersonate", impersonateUsers, impersonationHandler.ImpersonateUser)
+			}
+
+			// Admin role management routes
+			admin.GET("/permissions", readRoles, roleHandler.ListPermissions)
+			adminRoles := admin.Group("/roles")
+			{
+				adminRoles.GET("", readRoles, roleHandler.ListRoles)
+				adminRoles.POST("", manageRoles, roleHandler.CreateRole)
+				adminRoles.GET("/:id", readRoles, roleHandler.GetRole)
+				adminRoles.PUT("/:id", manageRoles, roleHandler.UpdateRole)
+				adminRoles.DELETE("/:id", manageRoles, roleHandler.DeleteRole)
 			}
 
 			// Admin invite code management routes
 			adminInviteCodes := admin.Group("/invite-codes")
 			{
-				adminInviteCodes.GET("", inviteCodeHandler.ListAllInviteCodes)
-				adminInviteCodes.GET("/stats", inviteCodeHandler.GetInviteCodeStats)
+				adminInviteCodes.GET("", manageInviteCodes, inviteCodeHandler.ListAllInviteCodes)
+				adminInviteCodes.GET("/stats", manageInviteCodes, inviteCodeHandler.GetInviteCodeStats)
+			}
+
+			// Admin audit log routes
+			adminAuditLogs := admin.Group("/audit-logs")
+			{
+				adminAuditLogs.GET("", readAuditLogs, auditLogHandler.ListAuditLogs)
+				adminAuditLogs.GET("/export", readAuditLogs, auditLogHandler.ExportAuditLogs)
+			}
+
+			// Admin recurring task schedule routes
+			adminSchedules := admin.Group("/schedules")
+			{
+				adminSchedules.GET("", readSchedules, scheduleHandler.ListSchedules)
+				adminSchedules.POST("/:name/pause", manageSchedules, scheduleHandler.PauseSchedule)
+				adminSchedules.POST("/:name/resume", manageSchedules, scheduleHandler.ResumeSchedule)
 			}
 		}
 
@@ -166,10 +307,72 @@ func main() {
 		user := v1.Group("/user")
 		user.Use(middleware.AuthMiddleware(authService))
 		{
+			// Account security changes are not available to impersonation tokens
+			notImpersonating := middleware.BlockImpersonation()
+
 			// User profile management only
 			user.GET("/profile", userProfileHandler.GetProfile)
 			user.PUT("/profile", userProfileHandler.UpdateProfile)
-			user.PUT("/password", userProfileHandler.ChangePassword)
+			user.PUT("/password", notImpersonating, userProfileHandler.ChangePassword)
+			user.GET("/permissions", roleHandler.GetMyPermissions)
+
+			// Session management
+			user.GET("/sessions", sessionHandler.ListMySessions)
+			user.DELETE("/sessions/:id", sessionHandler.RevokeMySession)
+
+			// Two-factor authentication
+			user.GET("/mfa", mfaHandler.GetStatus)
+			user.POST("/mfa/totp/setup", notImpersonating, mfaHandler.SetupTOTP)
+			user.POST("/mfa/totp/confirm", notImpersonating, mfaHandler.ConfirmTOTP)
+			user.DELETE("/mfa/totp", notImpersonating, mfaHandler.DisableTOTP)
+			user.POST("/mfa/recovery-codes", notImpersonating, mfaHandler.RegenerateRecoveryCodes)
+
+			// Passkeys
+			user.GET("/passkeys", passkeyHandler.ListPasskeys)
+			user.POST("/passkeys/register/begin", notImpersonating, passkeyHandler.BeginRegistration)
+			user.POST("/passkeys/register/finish", notImpersonating, passkeyHandler.FinishRegistration)
+			user.DELETE("/passkeys/:id", notImpersonating, passkeyHandler.DeletePasskey)
+
+			// Linked login provider accounts
+			user.GET("/identities", identityHandler.ListIdentities)
+			user.POST("/identities/:provider", notImpersonating, identityHandler.LinkIdentity)
+			user.DELETE("/identities/:provider", notImpersonating, identityHandler.UnlinkIdentity)
+
+			// Personal access tokens
+			user.GET("/tokens", personalAccessTokenHandler.ListTokens)
+			user.POST("/tokens", notImpersonating, personalAccessTokenHandler.CreateToken)
+			user.GET("/tokens/:id", personalAccessTokenHandler.GetToken)
+			user.PUT("/tokens/:id", notImpersonating, personalAccessTokenHandler.UpdateToken)
+			user.DELETE("/tokens/:id", notImpersonating, personalAccessTokenHandler.DeleteToken)
+		}
+
+		// Organization routes
+		organizations := v1.Group("/organizations")
+		organizations.Use(middleware.AuthMiddleware(authService))
+		{
+			organizations.GET("", organizationHandler.ListOrganizations)
+			organizations.POST("", organizationHandler.CreateOrganization)
+			organizations.POST("/switch", organizationHandler.SwitchOrganization)
+			organizations.POST("/join", organizationHandler.JoinOrganization)
+
+			// Active organization routes, scoped to the org_id claim of the access token
+			orgMember := middleware.RequireOrganization(organizationService, model.OrganizationRoleMember)
+			orgAdmin := middleware.RequireOrganization(organizationService, model.OrganizationRoleAdmin)
+			orgOwner := middleware.RequireOrganization(organizationService, model.OrganizationRoleOwner)
+			current := organizations.Group("/current")
+			{
+				current.GET("", orgMember, organizationHandler.GetCurrentOrganization)
+				current.PUT("", orgAdmin, organizationHandler.UpdateCurrentOrganization)
+				current.DELETE("", orgOwner, middleware.BlockImpersonation(), organizationHandler.DeleteCurrentOrganization)
+				current.POST("/leave", orgMember, organizationHandler.LeaveOrganization)
+				current.POST("/transfer-ownership", orgOwner, middleware.BlockImpersonation(), organizationHandler.TransferOwnership)
+				current.GET("/members", orgMember, organizationHandler.ListMembers)
+				current.PUT("/members/:user_id/role", orgAdmin, organizationHandler.UpdateMemberRole)
+				current.DELETE("/members/:user_id", orgAdmin, organizationHandler.RemoveMember)
+				current.GET("/invite-codes", orgAdmin, organizationHandler.ListInviteCodes)
+				current.POST("/invite-codes", orgAdmin, organizationHandler.CreateInviteCode)
+				current.DELETE("/invite-codes/:id", orgAdmin, organizationHandler.DeleteInviteCode)
+			}
 		}
 
 		// Invite code routes
@@ -178,14 +381,15 @@ func main() {
 			// Public routes
 			inviteCodes.GET("/validate/:code", inviteCodeHandler.ValidateInviteCode)
 			
-			// Authenticated routes
-			inviteCodes.Use(middleware.AuthMiddleware(authService))
-			inviteCodes.POST("", inviteCodeHandler.CreateInviteCode)
-			inviteCodes.GET("/my", inviteCodeHandler.GetMyInviteCodes)
-			inviteCodes.GET("/:id", inviteCodeHandler.GetInviteCode)
-			inviteCodes.GET("/:id/usages", inviteCodeHandler.GetInviteCodeUsages)
-			inviteCodes.PUT("/:id/status", inviteCodeHandler.UpdateInviteCodeStatus)
-			inviteCodes.DELETE("/:id", inviteCodeHandler.DeleteInviteCode)
+			// Authenticated routes, also available to personal access tokens with the invite code scopes
+			readInviteCodes := middleware.AuthMiddleware(authService, model.ScopeInviteCodesRead)
+			writeInviteCodes := middleware.AuthMiddleware(authService, model.ScopeInviteCodesWrite)
+			inviteCodes.POST("", writeInviteCodes, inviteCodeHandler.CreateInviteCode)
+			inviteCodes.GET("/my", readInviteCodes, inviteCodeHandler.GetMyInviteCodes)
+			inviteCodes.GET("/:id", readInviteCodes, inviteCodeHandler.GetInviteCode)
+			inviteCodes.GET("/:id/usages", readInviteCodes, inviteCodeHandler.GetInviteCodeUsages)
+			inviteCodes.PUT("/:id/status", writeInviteCodes, inviteCodeHandler.UpdateInviteCodeStatus)
+			inviteCodes.DELETE("/:id", writeInviteCodes, inviteCodeHandler.DeleteInviteCode)
 		}
 	}
 
@@ -206,12 +410,27 @@ func main() {
 	<-quit
 	logger.Info("Shutting down server...")
 
+	// Drain the task processor alongside the HTTP server, with its own deadline, so a slow HTTP
+	// shutdown does not cut into the time in-flight tasks get to finish. Tasks still running at
+	// the deadline are redelivered after their lease expires.
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer drainCancel()
+		if err := processor.Shutdown(drainCtx); err != nil {
+			logger.Error("Task processor did not drain before shutdown deadline", logger.Error2("error", err))
+		}
+	}()
+
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	// Keep going on error so the task processor still drains and deferred cleanup runs
 	if err := srv.Shutdown(shutdownCtx); err != nil {
-		logger.Fatal("Server forced to shutdown", logger.Error2("error", err))
+		logger.Error("Server forced to shutdown", logger.Error2("error", err))
 	}
 
+	<-drained
 	logger.Info("Server exited")
 }
\ No newline at end of file
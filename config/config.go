@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,11 +15,17 @@ type Config struct {
 	Redis    RedisConfig
 	OAuth2   OAuth2Config
 	JWT      JWTConfig
+	Auth     AuthConfig
+	Password PasswordConfig
+	MFA      MFAConfig
+	WebAuthn WebAuthnConfig
+	Queue    QueueConfig
 	Log      LogConfig
 }
 
 type ServerConfig struct {
-	Port string
+	Port    string
+	BaseURL string // Public URL of the API, used to build links sent by email
 }
 
 type DatabaseConfig struct {
@@ -45,11 +52,84 @@ type OAuth2Config struct {
 	GitHubRedirectURL   string
 	TelegramBotToken    string
 	TelegramRedirectURL string
+	AllowedReturnURLs   []string // Origins (optionally with a path prefix) the SPA may be sent back to after login
+	OIDCProviders       []OIDCProviderConfig
+}
+
+// OIDCProviderConfig configures a generic OpenID Connect provider such as Keycloak, Okta,
+// Azure AD or GitLab. Endpoints and signing keys are discovered from the issuer.
+type OIDCProviderConfig struct {
+	Key          string // Used in URLs and as the user's provider, e.g. /auth/keycloak
+	DisplayName  string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// Claims mapped to the user profile
+	SubjectClaim  string
+	EmailClaim    string
+	NameClaim     string
+	UsernameClaim string
+	AvatarClaim   string
 }
 
 type JWTConfig struct {
-	Secret     string
-	ExpireHours int
+	Secret               string
+	ExpireHours          int
+	RefreshExpireHours   int
+	SigningAlgorithm     string   // HS256, RS256 or EdDSA
+	PrivateKeyFile       string   // PEM private key used for RS256/EdDSA signing
+	KeyID                string   // kid of the signing key, defaults to the RFC 7638 thumbprint
+	VerificationKeyFiles []string // Extra PEM public keys ("kid=path" or "path") still accepted after rotation
+}
+
+type AuthConfig struct {
+	RequireVerifiedEmail         bool // Reject password logins until the email address is verified
+	EmailVerificationExpireHours int
+	PasswordResetURL             string // Frontend page that reads ?token= and posts it to /auth/reset-password
+	PasswordResetExpireMinutes   int
+	MagicLinkURL                 string // Frontend page that reads ?token= and posts it to /auth/magic-link/verify
+	MagicLinkExpireMinutes       int
+	LoginFailureWindowMinutes    int // Sliding window in which failed password logins are counted
+	MaxLoginFailuresPerEmail     int // Failures within the window before the email is locked out
+	MaxLoginFailuresPerIP        int // Failures within the window before the client IP is locked out
+	LoginLockoutMinutes          int
+	ImpersonationExpireMinutes   int // Lifetime of the access token issued when an admin impersonates a user
+}
+
+type PasswordConfig struct {
+	MinLength           int
+	MaxLength           int    // In bytes, capped at bcrypt's 72-byte limit when bcrypt is used
+	MinCharacterClasses int    // Required number of lowercase, uppercase, digit and symbol classes
+	HistorySize         int    // Number of previous passwords that cannot be reused, including the current one
+	BreachedListFile    string // File of SHA-1 hashes of breached passwords, one per line ("HASH" or "HASH:count")
+	HashAlgorithm       string // argon2id or bcrypt; hashes of the other algorithm are upgraded on login
+	BcryptCost          int
+	Argon2Time          int // Iterations
+	Argon2MemoryKiB     int
+	Argon2Threads       int
+}
+
+type MFAConfig struct {
+	Issuer           string // Issuer shown in authenticator apps
+	RequireForAdmins bool   // Block admin routes for local admin accounts until TOTP is enabled
+	SecretKey        string // Key TOTP secrets are encrypted with at rest, at least 32 characters
+}
+
+type WebAuthnConfig struct {
+	RPID          string   // Relying party ID, the registrable domain passkeys are bound to
+	RPDisplayName string   // Name shown by the authenticator
+	RPOrigins     []string // Origins allowed to perform ceremonies, e.g. https://app.example.com
+}
+
+type QueueConfig struct {
+	Workers             int               // Worker goroutines polling the queues per server instance
+	Priorities          map[string]int    // Priority weight per queue; only listed queues are processed
+	StrictPriority      bool              // Always empty higher priority queues first instead of weighted polling
+	Routes              map[string]string // Queue per task type; other types go to the "default" queue
+	Concurrency         map[string]int    // Max tasks from a queue handled at once per server instance
+	TaskTypeConcurrency map[string]int    // Max tasks of a type handled at once per server instance
 }
 
 type LogConfig struct {
@@ -66,7 +146,8 @@ func LoadConfig() *Config {
 
 	return &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
+			Port:    getEnv("SERVER_PORT", "8080"),
+			BaseURL: getEnv("APP_BASE_URL", "http://localhost:8080"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -90,10 +171,60 @@ func LoadConfig() *Config {
 			GitHubRedirectURL:   getEnv("GITHUB_REDIRECT_URL", "http://localhost:8080/auth/github/callback"),
 			TelegramBotToken:    getEnv("TELEGRAM_BOT_TOKEN", ""),
 			TelegramRedirectURL: getEnv("TELEGRAM_REDIRECT_URL", "http://localhost:8080/auth/telegram/callback"),
+			AllowedReturnURLs:   getEnvList("OAUTH_ALLOWED_RETURN_URLS"),
+			OIDCProviders:       loadOIDCProviders(),
 		},
 		JWT: JWTConfig{
-			Secret:     getEnv("JWT_SECRET", "your-super-secret-jwt-key"),
-			ExpireHours: getEnvInt("JWT_EXPIRE_HOURS", 24),
+			Secret:               getEnv("JWT_SECRET", "your-super-secret-jwt-key"),
+			ExpireHours:          getEnvInt("JWT_EXPIRE_HOURS", 24),
+			RefreshExpireHours:   getEnvInt("JWT_REFRESH_EXPIRE_HOURS", 720),
+			SigningAlgorithm:     getEnv("JWT_SIGNING_ALGORITHM", "HS256"),
+			PrivateKeyFile:       getEnv("JWT_PRIVATE_KEY_FILE", ""),
+			KeyID:                getEnv("JWT_KEY_ID", ""),
+			VerificationKeyFiles: getEnvList("JWT_VERIFICATION_KEY_FILES"),
+		},
+		Auth: AuthConfig{
+			RequireVerifiedEmail:         getEnvBool("AUTH_REQUIRE_VERIFIED_EMAIL", false),
+			EmailVerificationExpireHours: getEnvInt("AUTH_EMAIL_VERIFICATION_EXPIRE_HOURS", 24),
+			PasswordResetURL:             getEnv("AUTH_PASSWORD_RESET_URL", "http://localhost:8080/reset-password"),
+			PasswordResetExpireMinutes:   getEnvInt("AUTH_PASSWORD_RESET_EXPIRE_MINUTES", 30),
+			MagicLinkURL:                 getEnv("AUTH_MAGIC_LINK_URL", "http://localhost:8080/magic-link"),
+			MagicLinkExpireMinutes:       getEnvInt("AUTH_MAGIC_LINK_EXPIRE_MINUTES", 15),
+			LoginFailureWindowMinutes:    getEnvInt("AUTH_LOGIN_FAILURE_WINDOW_MINUTES", 15),
+			MaxLoginFailuresPerEmail:     getEnvInt("AUTH_MAX_LOGIN_FAILURES_PER_EMAIL", 5),
+			MaxLoginFailuresPerIP:        getEnvInt("AUTH_MAX_LOGIN_FAILURES_PER_IP", 20),
+			LoginLockoutMinutes:          getEnvInt("AUTH_LOGIN_LOCKOUT_MINUTES", 15),
+			ImpersonationExpireMinutes:   getEnvInt("AUTH_IMPERSONATION_EXPIRE_MINUTES", 15),
+		},
+		Password: PasswordConfig{
+			MinLength:           getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:           getEnvInt("PASSWORD_MAX_LENGTH", 72),
+			MinCharacterClasses: getEnvInt("PASSWORD_MIN_CHARACTER_CLASSES", 2),
+			HistorySize:         getEnvInt("PASSWORD_HISTORY_SIZE", 5),
+			BreachedListFile:    getEnv("PASSWORD_BREACHED_LIST_FILE", ""),
+			HashAlgorithm:       getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			BcryptCost:          getEnvInt("PASSWORD_BCRYPT_COST", 10),
+			Argon2Time:          getEnvInt("PASSWORD_ARGON2_TIME", 2),
+			Argon2MemoryKiB:     getEnvInt("PASSWORD_ARGON2_MEMORY_KIB", 19456),
+			Argon2Threads:       getEnvInt("PASSWORD_ARGON2_THREADS", 1),
+		},
+		MFA: MFAConfig{
+			Issuer:           getEnv("MFA_ISSUER", "Linke"),
+			RequireForAdmins: getEnvBool("MFA_REQUIRE_FOR_ADMINS", false),
+			SecretKey:        getEnv("MFA_SECRET_KEY", ""),
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:          getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPDisplayName: getEnv("WEBAUTHN_RP_DISPLAY_NAME", "Linke"),
+			RPOrigins:     getEnvList("WEBAUTHN_RP_ORIGINS"),
+		},
+		Queue: QueueConfig{
+			Workers:             getEnvInt("QUEUE_WORKERS", 4),
+			Priorities:          getEnvIntMap("QUEUE_PRIORITIES", map[string]int{"critical": 6, "default": 3, "low": 1}),
+			StrictPriority:      getEnvBool("QUEUE_STRICT_PRIORITY", false),
+			Routes:              getEnvStringMap("QUEUE_ROUTES", map[string]string{"email": "critical", "data_processing": "low"}),
+			Concurrency:         getEnvIntMap("QUEUE_CONCURRENCY", map[string]int{"low": 1}),
+			TaskTypeConcurrency: getEnvIntMap("QUEUE_TASK_TYPE_CONCURRENCY", map[string]int{"data_processing": 1}),
 		},
 		Log: LogConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
@@ -117,4 +248,90 @@ func getEnvInt(key string, defaultValue int) int {
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// loadOIDCProviders reads the providers listed in OIDC_PROVIDERS, each configured with
+// OIDC_<KEY>_* variables, e.g. OIDC_KEYCLOAK_ISSUER for the provider "keycloak"
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, key := range getEnvList("OIDC_PROVIDERS") {
+		key = strings.ToLower(key)
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_")) + "_"
+
+		scopes := getEnvList(prefix + "SCOPES")
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "profile", "email"}
+		}
+
+		providers = append(providers, OIDCProviderConfig{
+			Key:           key,
+			DisplayName:   getEnv(prefix+"DISPLAY_NAME", key),
+			Issuer:        getEnv(prefix+"ISSUER", ""),
+			ClientID:      getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret:  getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:   getEnv(prefix+"REDIRECT_URL", "http://localhost:8080/api/v1/auth/"+key+"/callback"),
+			Scopes:        scopes,
+			SubjectClaim:  getEnv(prefix+"SUBJECT_CLAIM", "sub"),
+			EmailClaim:    getEnv(prefix+"EMAIL_CLAIM", "email"),
+			NameClaim:     getEnv(prefix+"NAME_CLAIM", "name"),
+			UsernameClaim: getEnv(prefix+"USERNAME_CLAIM", "preferred_username"),
+			AvatarClaim:   getEnv(prefix+"AVATAR_CLAIM", "picture"),
+		})
+	}
+	return providers
+}
+
+// getEnvIntMap parses a comma-separated list of name=number pairs, e.g. "default=4,reports=1".
+// Malformed entries are skipped.
+func getEnvIntMap(key string, defaultValue map[string]int) map[string]int {
+	entries := getEnvStringMap(key, nil)
+	if entries == nil {
+		return defaultValue
+	}
+
+	values := make(map[string]int, len(entries))
+	for name, number := range entries {
+		if intValue, err := strconv.Atoi(number); err == nil {
+			values[name] = intValue
+		}
+	}
+	return values
+}
+
+// getEnvStringMap parses a comma-separated list of name=value pairs, e.g. "email=critical".
+// Malformed entries are skipped.
+func getEnvStringMap(key string, defaultValue map[string]string) map[string]string {
+	entries := getEnvList(key)
+	if len(entries) == 0 {
+		return defaultValue
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, value, found := strings.Cut(entry, "=")
+		if !found {
+			continue
+		}
+		values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -15,14 +15,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/invite-codes": {
+        "/admin/audit-logs": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get list of all invite codes with pagination (admin only)",
+                "description": "List audit events, newest first, optionally filtered by actor, target, action and time range (requires audit_logs:read)",
                 "consumes": [
                     "application/json"
                 ],
@@ -30,10 +30,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "invite-codes"
+                    "admin-audit-logs"
                 ],
-                "summary": "[Admin] List all invite codes",
+                "summary": "[Admin] List audit logs",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Actor user ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "user",
+                        "description": "Target type",
+                        "name": "target_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "target_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "user.status_update",
+                        "description": "Action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-01-01T00:00:00Z",
+                        "description": "Start of the time range, inclusive (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-02-01T00:00:00Z",
+                        "description": "End of the time range, exclusive (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "default": 1,
@@ -56,6 +96,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.StandardListResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
@@ -77,42 +123,86 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/invite-codes/stats": {
+        "/admin/audit-logs/export": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get statistics about invite codes (admin only)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Download every audit event matching the filters, oldest first, as CSV or JSON Lines (requires audit_logs:read)",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/x-ndjson"
                 ],
                 "tags": [
-                    "invite-codes"
+                    "admin-audit-logs"
+                ],
+                "summary": "[Admin] Export audit logs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Actor user ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "user",
+                        "description": "Target type",
+                        "name": "target_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Target ID",
+                        "name": "target_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "user.status_update",
+                        "description": "Action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-01-01T00:00:00Z",
+                        "description": "Start of the time range, inclusive (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024-02-01T00:00:00Z",
+                        "description": "End of the time range, exclusive (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
                 ],
-                "summary": "[Admin] Get invite code statistics",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.StandardResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
                         }
                     },
                     "401": {
@@ -126,24 +216,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.ForbiddenResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
-                        }
                     }
                 }
             }
         },
-        "/admin/users": {
+        "/admin/invite-codes": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get list of all users with pagination (admin only)",
+                "description": "Get list of all invite codes with pagination (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -151,9 +235,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
+                    "invite-codes"
                 ],
-                "summary": "[Admin] List all users",
+                "summary": "[Admin] List all invite codes",
                 "parameters": [
                     {
                         "type": "integer",
@@ -198,14 +282,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/batch/delete": {
-            "post": {
+        "/admin/invite-codes/stats": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Soft delete multiple users (admin only)",
+                "description": "Get statistics about invite codes (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -213,37 +297,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
-                ],
-                "summary": "[Admin] Batch delete users",
-                "parameters": [
-                    {
-                        "description": "User IDs",
-                        "name": "ids",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "array",
-                                "items": {
-                                    "type": "integer"
-                                }
-                            }
-                        }
-                    }
+                    "invite-codes"
                 ],
+                "summary": "[Admin] Get invite code statistics",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.StandardResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -267,14 +341,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/batch/restore": {
-            "post": {
+        "/admin/permissions": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Restore multiple soft deleted users (admin only)",
+                "description": "List every permission that can be granted to a role (requires roles:read)",
                 "consumes": [
                     "application/json"
                 ],
@@ -282,37 +356,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
-                ],
-                "summary": "[Admin] Batch restore users",
-                "parameters": [
-                    {
-                        "description": "User IDs",
-                        "name": "ids",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "array",
-                                "items": {
-                                    "type": "integer"
-                                }
-                            }
-                        }
-                    }
+                    "admin-roles"
                 ],
+                "summary": "[Admin] List permissions",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.StandardResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -326,24 +392,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.ForbiddenResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
-                        }
                     }
                 }
             }
         },
-        "/admin/users/deleted": {
+        "/admin/roles": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get list of soft deleted users with pagination (admin only)",
+                "description": "List all roles and their permissions (requires roles:read)",
                 "consumes": [
                     "application/json"
                 ],
@@ -351,30 +411,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
-                ],
-                "summary": "[Admin] List deleted users",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Items per page",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "admin-roles"
                 ],
+                "summary": "[Admin] List roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.StandardListResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.RoleResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -396,16 +455,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/admin/users/provider": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get users filtered by OAuth provider with pagination (admin only)",
+                "description": "Create a custom role (requires roles:manage). Only permissions the caller holds can be granted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -413,112 +470,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
+                    "admin-roles"
                 ],
-                "summary": "[Admin] List users by provider",
+                "summary": "[Admin] Create role",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Provider (google, github, telegram)",
-                        "name": "provider",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Items per page",
-                        "name": "limit",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/response.ProviderFilterResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/response.UnauthorizedResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/response.ForbiddenResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                        "description": "Role name, description and permissions",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                            "$ref": "#/definitions/service.CreateRoleRequest"
                         }
                     }
-                }
-            }
-        },
-        "/admin/users/search": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Search users by name, email, or username with pagination (admin only)",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "admin-users"
-                ],
-                "summary": "[Admin] Search users",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Search query",
-                        "name": "q",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Items per page",
-                        "name": "limit",
-                        "in": "query"
-                    }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/response.SearchResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.RoleResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -539,69 +521,23 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ForbiddenResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/admin/users/stats": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get overall user statistics (admin only)",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "admin-users"
-                ],
-                "summary": "[Admin] Get user statistics",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/response.StandardResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/response.UnauthorizedResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/response.ForbiddenResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                            "$ref": "#/definitions/response.ConflictResponse"
                         }
                     }
                 }
             }
         },
-        "/admin/users/{id}": {
+        "/admin/roles/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get any user information by user ID (admin only)",
+                "description": "Get a role and its permissions (requires roles:read)",
                 "consumes": [
                     "application/json"
                 ],
@@ -609,13 +545,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
+                    "admin-roles"
                 ],
-                "summary": "[Admin] Get user by ID",
+                "summary": "[Admin] Get role",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Role ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -633,7 +569,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.UserResponse"
+                                            "$ref": "#/definitions/model.RoleResponse"
                                         }
                                     }
                                 }
@@ -672,7 +608,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update any user information (admin only)",
+                "description": "Change a role's description and/or replace its permissions (requires roles:manage). The caller must hold every permission of the role before and after the change. The permissions of the admin role cannot be changed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -680,24 +616,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
+                    "admin-roles"
                 ],
-                "summary": "[Admin] Update any user",
+                "summary": "[Admin] Update role",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Role ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User data",
-                        "name": "user",
+                        "description": "New description and/or permissions",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.UserResponse"
+                            "$ref": "#/definitions/service.UpdateRoleRequest"
                         }
                     }
                 ],
@@ -713,7 +649,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.UserResponse"
+                                            "$ref": "#/definitions/model.RoleResponse"
                                         }
                                     }
                                 }
@@ -743,12 +679,6 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.NotFoundResponse"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
-                        }
                     }
                 }
             },
@@ -758,7 +688,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Soft delete any user (admin only)",
+                "description": "Delete a custom role (requires roles:manage). Built-in roles and roles that are still assigned to users cannot be deleted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -766,13 +696,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
+                    "admin-roles"
                 ],
-                "summary": "[Admin] Soft delete user",
+                "summary": "[Admin] Delete role",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Role ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -808,18 +738,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.NotFoundResponse"
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ConflictResponse"
+                        }
                     }
                 }
             }
         },
-        "/admin/users/{id}/hard-delete": {
-            "delete": {
+        "/admin/schedules": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Permanently delete a user from database (admin only)",
+                "description": "List the recurring task schedules with their next and last run (requires schedules:read)",
                 "consumes": [
                     "application/json"
                 ],
@@ -827,29 +763,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
-                ],
-                "summary": "[Admin] Hard delete user",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin-schedules"
                 ],
+                "summary": "[Admin] List schedules",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.MessageOnlyResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/queue.ScheduleStatus"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -864,23 +800,23 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ForbiddenResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.NotFoundResponse"
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/admin/users/{id}/restore": {
+        "/admin/schedules/{name}/pause": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Restore a soft deleted user (admin only)",
+                "description": "Stop a recurring task schedule from firing on every server instance until it is resumed (requires schedules:manage)",
                 "consumes": [
                     "application/json"
                 ],
@@ -888,14 +824,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
+                    "admin-schedules"
                 ],
-                "summary": "[Admin] Restore user",
+                "summary": "[Admin] Pause schedule",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Schedule name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
@@ -904,13 +840,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.MessageOnlyResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
+                            "$ref": "#/definitions/response.StandardResponse"
                         }
                     },
                     "401": {
@@ -930,18 +860,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.NotFoundResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/admin/users/{id}/role": {
-            "put": {
+        "/admin/schedules/{name}/resume": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update user role (admin only)",
+                "description": "Let a paused recurring task schedule fire again from its next run (requires schedules:manage)",
                 "consumes": [
                     "application/json"
                 ],
@@ -949,53 +885,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "admin-users"
+                    "admin-schedules"
                 ],
-                "summary": "[Admin] Update user role",
+                "summary": "[Admin] Resume schedule",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Schedule name",
+                        "name": "name",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Role data",
-                        "name": "role",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.StandardResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.UserResponse"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
+                            "$ref": "#/definitions/response.StandardResponse"
                         }
                     },
                     "401": {
@@ -1015,18 +921,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.NotFoundResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/admin/users/{id}/status": {
-            "put": {
+        "/admin/users": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update user status (admin only)",
+                "description": "Get list of all users with pagination (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1036,51 +948,28 @@ const docTemplate = `{
                 "tags": [
                     "admin-users"
                 ],
-                "summary": "[Admin] Update user status",
+                "summary": "[Admin] List all users",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "Status data",
-                        "name": "status",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.StandardResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.UserResponse"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
+                            "$ref": "#/definitions/response.StandardListResponse"
                         }
                     },
                     "401": {
@@ -1095,23 +984,23 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.ForbiddenResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/response.NotFoundResponse"
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/change-password": {
+        "/admin/users/batch/delete": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Change password for local account users",
+                "description": "Soft delete multiple users (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1119,19 +1008,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin-users"
                 ],
-                "summary": "Change user password",
+                "summary": "[Admin] Batch delete users",
                 "parameters": [
                     {
-                        "description": "Password change data",
-                        "name": "passwords",
+                        "description": "User IDs",
+                        "name": "ids",
                         "in": "body",
                         "required": true,
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "string"
+                                "type": "array",
+                                "items": {
+                                    "type": "integer"
+                                }
                             }
                         }
                     }
@@ -1140,7 +1032,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                            "$ref": "#/definitions/response.StandardResponse"
                         }
                     },
                     "400": {
@@ -1154,13 +1046,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/login": {
+        "/admin/users/batch/restore": {
             "post": {
-                "description": "Login with email and password",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore multiple soft deleted users (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1168,17 +1077,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin-users"
                 ],
-                "summary": "User login with email/password",
+                "summary": "[Admin] Batch restore users",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "credentials",
+                        "description": "User IDs",
+                        "name": "ids",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/service.LoginRequest"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "type": "integer"
+                                }
+                            }
                         }
                     }
                 ],
@@ -1186,19 +1101,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.StandardResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/service.AuthResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/response.StandardResponse"
                         }
                     },
                     "400": {
@@ -1212,46 +1115,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Logout user (client-side token invalidation)",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "User logout",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/profile": {
+        "/admin/users/deleted": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get current user's profile information",
+                "description": "Get list of soft deleted users with pagination (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1259,26 +1146,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin-users"
+                ],
+                "summary": "[Admin] List deleted users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get user profile",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.StandardResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.UserResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/response.StandardListResponse"
                         }
                     },
                     "401": {
@@ -1287,6 +1178,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1296,58 +1193,58 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/providers": {
+        "/admin/users/provider": {
             "get": {
-                "description": "Get list of supported OAuth providers",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get users filtered by OAuth provider with pagination (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "auth"
+                    "admin-users"
                 ],
-                "summary": "Get supported OAuth providers",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/response.StandardResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/refresh": {
-            "post": {
-                "security": [
+                "summary": "[Admin] List users by provider",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "type": "string",
+                        "description": "Provider (google, github, telegram)",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
-                "description": "Refresh an existing JWT token",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Refresh JWT token",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.StandardResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/service.TokenResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/response.ProviderFilterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
                         }
                     },
                     "401": {
@@ -1355,13 +1252,30 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/auth/register": {
-            "post": {
-                "description": "Register a new user with email and password. Username and name are auto-generated from email. Optional invite code can be provided.",
+        "/admin/users/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Search users by name, email, or username with pagination (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1369,66 +1283,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin-users"
                 ],
-                "summary": "User registration",
+                "summary": "[Admin] Search users",
                 "parameters": [
                     {
-                        "description": "Registration data (email, password, and optional invite_code)",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.RegisterRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.StandardResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/service.AuthResponse"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
-                        }
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
                     },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "$ref": "#/definitions/response.ConflictResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/telegram/widget": {
-            "get": {
-                "description": "Get Telegram Login Widget HTML for frontend integration",
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Get Telegram Login Widget",
-                "parameters": [
                     {
-                        "type": "string",
-                        "description": "Bot username (optional)",
-                        "name": "bot_username",
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -1436,7 +1313,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.StandardResponse"
+                            "$ref": "#/definitions/response.SearchResponse"
                         }
                     },
                     "400": {
@@ -1444,70 +1321,46 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.BadRequestResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/{provider}": {
-            "get": {
-                "description": "Initiate OAuth login for various providers",
-                "tags": [
-                    "auth"
-                ],
-                "summary": "OAuth login",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "OAuth provider (google, github, telegram)",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "302": {
-                        "description": "redirect",
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/auth/{provider}/callback": {
+        "/admin/users/stats": {
             "get": {
-                "description": "Handle OAuth callback from providers",
-                "tags": [
-                    "auth"
-                ],
-                "summary": "OAuth callback",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "OAuth provider",
-                        "name": "provider",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Authorization code (for OAuth2)",
-                        "name": "code",
-                        "in": "query"
-                    },
+                "security": [
                     {
-                        "type": "string",
-                        "description": "State parameter (for OAuth2)",
-                        "name": "state",
-                        "in": "query"
+                        "BearerAuth": []
                     }
                 ],
+                "description": "Get overall user statistics (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin-users"
+                ],
+                "summary": "[Admin] Get user statistics",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1515,18 +1368,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.StandardResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/response.BadRequestResponse"
-                        }
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1536,14 +1389,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/invite-codes": {
-            "post": {
+        "/admin/users/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new invite code",
+                "description": "Get any user information by user ID (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1551,23 +1404,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "invite-codes"
+                    "admin-users"
                 ],
-                "summary": "[User] Create invite code",
+                "summary": "[Admin] Get user by ID",
                 "parameters": [
                     {
-                        "description": "Invite code data",
-                        "name": "invite_code",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/service.CreateInviteCodeRequest"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -1577,7 +1428,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.InviteCodeResponse"
+                                            "$ref": "#/definitions/model.UserResponse"
                                         }
                                     }
                                 }
@@ -1596,23 +1447,27 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
                         }
                     }
                 }
-            }
-        },
-        "/invite-codes/my": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get invite codes created by current user",
+                "description": "Update any user information (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1620,68 +1475,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "invite-codes"
+                    "admin-users"
                 ],
-                "summary": "[User] Get my invite codes",
+                "summary": "[Admin] Update any user",
                 "parameters": [
                     {
                         "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Items per page",
-                        "name": "limit",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/response.StandardListResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/response.UnauthorizedResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                            "$ref": "#/definitions/handler.AdminUserUpdateRequest"
                         }
                     }
-                }
-            }
-        },
-        "/invite-codes/validate/{code}": {
-            "get": {
-                "description": "Validate if an invite code can be used",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "invite-codes"
-                ],
-                "summary": "[Public] Validate invite code",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Invite code",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    }
                 ],
                 "responses": {
                     "200": {
@@ -1695,7 +1508,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.InviteCodeResponse"
+                                            "$ref": "#/definitions/model.UserResponse"
                                         }
                                     }
                                 }
@@ -1708,23 +1521,39 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.BadRequestResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/response.NotFoundResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/invite-codes/{id}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get invite code details by ID (only creator or admin can access)",
+                "description": "Soft delete any user (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1732,13 +1561,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "invite-codes"
+                    "admin-users"
                 ],
-                "summary": "[User] Get invite code by ID",
+                "summary": "[Admin] Soft delete user",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invite code ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1748,19 +1577,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/response.StandardResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.InviteCodeResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
                         }
                     },
                     "400": {
@@ -1788,14 +1605,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/users/{id}/hard-delete": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an invite code (only creator or admin can delete)",
+                "description": "Permanently delete a user from database (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1803,13 +1622,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "invite-codes"
+                    "admin-users"
                 ],
-                "summary": "[User] Delete invite code",
+                "summary": "[Admin] Hard delete user",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invite code ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1849,14 +1668,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/invite-codes/{id}/status": {
-            "put": {
+        "/admin/users/{id}/impersonate": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update the status of an invite code (only creator or admin can update)",
+                "description": "Issue a short-lived access token that acts as the user (requires users:impersonate). The caller must hold every permission of the user's role. The token carries the caller in its act claim, cannot be refreshed, is rejected by admin routes and sensitive account actions, and the impersonation is recorded in the audit log.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1864,28 +1683,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "invite-codes"
+                    "admin-users"
                 ],
-                "summary": "[User] Update invite code status",
+                "summary": "[Admin] Impersonate user",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invite code ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "New status",
-                        "name": "status",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
                     }
                 ],
                 "responses": {
@@ -1900,7 +1707,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.InviteCodeResponse"
+                                            "$ref": "#/definitions/service.ImpersonationResponse"
                                         }
                                     }
                                 }
@@ -1930,18 +1737,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.NotFoundResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/invite-codes/{id}/usages": {
+        "/admin/users/{id}/lockout": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get usage records for a specific invite code (only creator or admin can access)",
+                "description": "Get whether a user's email is locked out after repeated failed logins and how many failures are in the current window (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -1949,37 +1762,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "invite-codes"
+                    "admin-users"
                 ],
-                "summary": "[User] Get invite code usages",
+                "summary": "[Admin] Get user login lockout",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Invite code ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Items per page",
-                        "name": "limit",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.StandardListResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.LoginLockoutStatus"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -2005,18 +1816,22 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.NotFoundResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/tasks": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create and enqueue a new task",
+                "description": "Lift a user's login lockout and reset their failed login counter. Lockouts of client IPs expire on their own. (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -2024,25 +1839,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "admin-users"
                 ],
-                "summary": "Create a new task",
+                "summary": "[Admin] Clear user login lockout",
                 "parameters": [
                     {
-                        "description": "Task details",
-                        "name": "task",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/response.StandardResponse"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
                         }
                     },
                     "400": {
@@ -2057,6 +1870,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2066,26 +1891,44 @@ const docTemplate = `{
                 }
             }
         },
-        "/tasks/status": {
-            "get": {
+        "/admin/users/{id}/mfa": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get the current status of the task queue",
+                "description": "Disable TOTP and delete recovery codes of any user, e.g. after a lost device (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "admin-users"
+                ],
+                "summary": "[Admin] Reset user two-factor authentication",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get queue status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.StandardResponse"
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
                         }
                     },
                     "401": {
@@ -2094,6 +1937,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2103,14 +1958,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/password": {
-            "put": {
+        "/admin/users/{id}/restore": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Change user's own password",
+                "description": "Restore a soft deleted user (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -2118,18 +1973,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user-profile"
+                    "admin-users"
                 ],
-                "summary": "[User] Change password",
+                "summary": "[Admin] Restore user",
                 "parameters": [
                     {
-                        "description": "Password change data",
-                        "name": "passwords",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.ChangePasswordRequest"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2151,23 +2004,29 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
                         }
                     }
                 }
             }
         },
-        "/user/profile": {
-            "get": {
+        "/admin/users/{id}/role": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get current user's profile information",
+                "description": "Assign a role to a user (requires roles:assign). The caller must hold every permission of both the user's current role and the new role, and cannot change their own role.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2175,9 +2034,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user-profile"
+                    "admin-users"
+                ],
+                "summary": "[Admin] Update user role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role data",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 ],
-                "summary": "[User] Get own profile",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -2197,27 +2077,41 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/users/{id}/sessions": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update current user's profile information (limited fields)",
+                "description": "List active sessions of any user (admin only)",
                 "consumes": [
                     "application/json"
                 ],
@@ -2225,18 +2119,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user-profile"
+                    "admin-users"
                 ],
-                "summary": "[User] Update own profile",
+                "summary": "[Admin] List user sessions",
                 "parameters": [
                     {
-                        "description": "User profile data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.UserProfileUpdateRequest"
-                        }
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2251,7 +2143,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.UserResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.SessionResponse"
+                                            }
                                         }
                                     }
                                 }
@@ -2270,6 +2165,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.UnauthorizedResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2278,514 +2179,5369 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.ChangePasswordRequest": {
-            "type": "object",
-            "required": [
-                "new_password",
-                "old_password"
-            ],
-            "properties": {
-                "new_password": {
-                    "type": "string",
-                    "minLength": 6
-                },
-                "old_password": {
-                    "type": "string"
-                }
-            }
         },
-        "handler.UserProfileUpdateRequest": {
-            "type": "object",
-            "properties": {
-                "avatar": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "username": {
-                    "type": "string"
+        "/admin/users/{id}/sessions/{session_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke a session of any user (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin-users"
+                ],
+                "summary": "[Admin] Revoke user session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
                 }
             }
         },
-        "model.InviteCodeResponse": {
-            "type": "object",
-            "properties": {
+        "/admin/users/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update user status (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin-users"
+                ],
+                "summary": "[Admin] Update user status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status data",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/change-password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change password for local account users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Change user password",
+                "parameters": [
+                    {
+                        "description": "Password change data",
+                        "name": "passwords",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Email a single-use password reset link to a local account. Always succeeds so it cannot be used to discover registered addresses; at most one email is sent per minute.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request password reset",
+                "parameters": [
+                    {
+                        "description": "Email address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login with email and password. If the account has two-factor authentication enabled, no tokens are returned; instead mfa_required is true and the mfa_token must be exchanged at /auth/mfa/verify. Repeated failures for the same email or client IP are slowed down and then locked out for a while (429 with a Retry-After header).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User login with email/password",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.AuthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/response.TooManyRequestsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke the current access token server-side. If a refresh token is supplied, its token family is revoked as well.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User logout",
+                "parameters": [
+                    {
+                        "description": "Optional refresh token to revoke",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/service.LogoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke every access and refresh token issued to the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out all sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/magic-link": {
+            "post": {
+                "description": "Email a one-time login link. A link sent to an unregistered address creates the account on first use. Always succeeds so it cannot be used to discover registered addresses; at most one email is sent per minute.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request a magic login link",
+                "parameters": [
+                    {
+                        "description": "Email address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.MagicLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/magic-link/verify": {
+            "post": {
+                "description": "Redeem the token from a login link. Returns the same payload as /auth/login. invite_code is only used when the link creates a new account and registration requires one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in with a magic link",
+                "parameters": [
+                    {
+                        "description": "Login link token and optional invite code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.MagicLinkVerifyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.AuthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/verify": {
+            "post": {
+                "description": "Exchange the mfa_token returned by /auth/login and a TOTP code (or an unused recovery code) for access and refresh tokens. The mfa_token is valid for 5 minutes and can only be used once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete login with second factor",
+                "parameters": [
+                    {
+                        "description": "MFA token and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.MFAVerifyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.AuthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkey/login/begin": {
+            "post": {
+                "description": "Start a passwordless WebAuthn login ceremony. Pass options to navigator.credentials.get() and send the result to the finish endpoint together with ceremony_id within 5 minutes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Begin passkey login",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.PasskeyCeremonyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/passkey/login/finish": {
+            "post": {
+                "description": "Verify the passkey assertion and log in. Returns the same payload as /auth/login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Finish passkey login",
+                "parameters": [
+                    {
+                        "description": "Ceremony ID and the PublicKeyCredential",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.PasskeyLoginFinishRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.AuthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get current user's profile information. When the request is made with an impersonation token, the impersonation field identifies the admin acting as the user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get user profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.ProfileResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/providers": {
+            "get": {
+                "description": "Get list of supported OAuth providers",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get supported OAuth providers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token. The refresh token is rotated on every call; reusing an old refresh token revokes all tokens issued from the same login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh JWT token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.TokenResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Register a new user with email and password. Username and name are auto-generated from email. Optional invite code can be provided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "User registration",
+                "parameters": [
+                    {
+                        "description": "Registration data (email, password, and optional invite_code)",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.AuthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ConflictResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Set a new password using the token from the reset email. All existing sessions, access tokens and refresh tokens of the user are revoked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/telegram/widget": {
+            "get": {
+                "description": "Get Telegram Login Widget HTML for frontend integration",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get Telegram Login Widget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bot username (optional)",
+                        "name": "bot_username",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.StandardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "get": {
+                "description": "Verify the email address of a local account using the link sent after registration. Each link can only be used once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify email address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token from the email link",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email/resend": {
+            "post": {
+                "description": "Send a new verification link to an unverified local account. Always succeeds so it cannot be used to discover registered addresses; at most one email is sent per minute.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend verification email",
+                "parameters": [
+                    {
+                        "description": "Email address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.ResendVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}": {
+            "get": {
+                "description": "Initiate OAuth login for various providers. A random single-use state and a PKCE challenge are attached to the provider request. If return_to is given, it must match OAUTH_ALLOWED_RETURN_URLS and the callback redirects there with the tokens in the URL fragment instead of returning JSON.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "OAuth login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "OAuth provider (google, github, telegram or a configured OIDC provider key)",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL to send the browser back to after login (alias: redirect_uri)",
+                        "name": "return_to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "redirect",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/{provider}/callback": {
+            "get": {
+                "description": "Handle OAuth callback from providers. The state must come from /auth/{provider} or /user/identities/{provider} and can only be used once. A provider account that is not linked to any user creates a new account, unless its email is already registered (error account_exists); such accounts have to be linked from the account settings. Accounts with two-factor authentication get mfa_required and mfa_token instead of tokens. If the login was started with return_to, the browser is redirected there with access_token, refresh_token, token_type and expires_in (or mfa_required and mfa_token, linked, or error) in the URL fragment.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "OAuth callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "OAuth provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code (for OAuth2)",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "State parameter (for OAuth2)",
+                        "name": "state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.AuthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "302": {
+                        "description": "redirect to return_to",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invite-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new invite code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invite-codes"
+                ],
+                "summary": "[User] Create invite code",
+                "parameters": [
+                    {
+                        "description": "Invite code data",
+                        "name": "invite_code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateInviteCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.InviteCodeResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invite-codes/my": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get invite codes created by current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invite-codes"
+                ],
+                "summary": "[User] Get my invite codes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.StandardListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invite-codes/validate/{code}": {
+            "get": {
+                "description": "Validate if an invite code can be used",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invite-codes"
+                ],
+                "summary": "[Public] Validate invite code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.InviteCodeResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invite-codes/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get invite code details by ID (only creator or admin can access)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invite-codes"
+                ],
+                "summary": "[User] Get invite code by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.InviteCodeResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Delete an invite code (only creator or admin can delete)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invite-codes"
+                ],
+                "summary": "[User] Delete invite code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invite-codes/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update the status of an invite code (only creator or admin can update)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invite-codes"
+                ],
+                "summary": "[User] Update invite code status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.InviteCodeResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invite-codes/{id}/usages": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get usage records for a specific invite code (only creator or admin can access)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invite-codes"
+                ],
+                "summary": "[User] Get invite code usages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.StandardListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the organizations the current user is a member of, with their role in each",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[User] List my organizations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.OrganizationResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create an organization owned by the current user. Switch to it with /organizations/switch to use the /organizations/current endpoints.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[User] Create organization",
+                "parameters": [
+                    {
+                        "description": "Organization name and description",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.OrganizationResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/current": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the active organization of the access token, with the current user's role in it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] Get active organization",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.OrganizationResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rename the active organization and/or change its description (organization admins)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] Update active organization",
+                "parameters": [
+                    {
+                        "description": "New name and/or description",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.OrganizationResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the active organization (owner only). All members are removed and its invite codes are disabled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] Delete active organization",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/current/invite-codes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the invite codes of the active organization (organization admins)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] List organization invite codes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.StandardListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create an invite code for the active organization (organization admins). Users who register with it or join with it through /organizations/join become members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] Create organization invite code",
+                "parameters": [
+                    {
+                        "description": "Invite code data",
+                        "name": "invite_code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreateInviteCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.InviteCodeResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/current/invite-codes/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete one of the active organization's invite codes (organization admins)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] Delete organization invite code",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Invite code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/current/leave": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Leave the active organization. The owner has to transfer ownership first. Switch to another organization afterwards.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] Leave active organization",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/current/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the members of the active organization, owner first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] List members",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.OrganizationMemberResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/current/members/{user_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a member from the active organization (organization admins). Admins can remove members; only the owner can remove admins. The owner cannot be removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] Remove member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member user ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/current/members/{user_id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make another member of the active organization an admin or a regular member (organization admins). Admins can promote members; only the owner can demote admins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] Update member role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member user ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdateMemberRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.OrganizationMemberResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/current/transfer-ownership": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make another member the owner of the active organization (owner only). The previous owner becomes an admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[Organization] Transfer ownership",
+                "parameters": [
+                    {
+                        "description": "User ID of the new owner",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.TransferOwnershipRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/join": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Join an organization with one of its invite codes. The user becomes a member.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[User] Join organization",
+                "parameters": [
+                    {
+                        "description": "Organization invite code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.JoinOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.OrganizationResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ConflictResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/switch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make an organization the active one of the current session and get a new access token carrying its ID in the org_id claim. Access tokens issued on refresh keep the active organization. Pass organization_id 0 to clear it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "[User] Switch active organization",
+                "parameters": [
+                    {
+                        "description": "Organization to switch to",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.SwitchOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.TokenResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create and enqueue a new task. Set run_at (RFC 3339) or delay_seconds to run it later instead of right away.\nThe task goes to the queue its type is routed to; set queue to use another processed queue of the same or lower priority, or a higher one with the tasks:prioritize permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Create a new task",
+                "parameters": [
+                    {
+                        "description": "Task details",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/response.StandardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/response.ForbiddenResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the current status of the task queues, totalled and per queue in priority order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get queue status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.StandardResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/identities": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the provider accounts (Google, GitHub, Telegram, OIDC providers) linked to the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-identities"
+                ],
+                "summary": "[User] List linked accounts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.UserIdentity"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/identities/{provider}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Link a provider account to the current user. For OAuth and OIDC providers this returns an authorization_url; the browser has to visit it and the provider callback completes the link (with linked or error in the return_to fragment if return_to is given). For telegram, pass the Login Widget data as telegram_auth and the account is linked immediately. A provider account that is linked to another user cannot be linked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-identities"
+                ],
+                "summary": "[User] Link provider account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider (google, github, telegram or a configured OIDC provider key)",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional return_to, or telegram_auth for telegram",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LinkIdentityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.LinkIdentityResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.UserIdentity"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/response.ConflictResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the provider account from the current user. The last remaining login method (password, linked account or passkey) cannot be removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-identities"
+                ],
+                "summary": "[User] Unlink provider account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/mfa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get whether TOTP is enabled for the current user and how many recovery codes are left",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-mfa"
+                ],
+                "summary": "[User] Get two-factor authentication status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.MFAStatusResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/mfa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace all recovery codes with a new set. Requires a current TOTP code or an unused recovery code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-mfa"
+                ],
+                "summary": "[User] Regenerate recovery codes",
+                "parameters": [
+                    {
+                        "description": "TOTP or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.RecoveryCodesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/mfa/totp": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Disable two-factor authentication. Requires a current TOTP code or an unused recovery code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-mfa"
+                ],
+                "summary": "[User] Disable TOTP",
+                "parameters": [
+                    {
+                        "description": "TOTP or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/mfa/totp/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable TOTP by submitting a code from the authenticator app. Returns one-time recovery codes that are only shown once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-mfa"
+                ],
+                "summary": "[User] Confirm TOTP enrollment",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.MFACodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.RecoveryCodesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/mfa/totp/setup": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generate a new TOTP secret and otpauth URI. TOTP is not enabled until the setup is confirmed with a valid code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-mfa"
+                ],
+                "summary": "[User] Start TOTP enrollment",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.TOTPEnrollmentResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/passkeys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the passkeys registered on the current user's account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-passkeys"
+                ],
+                "summary": "[User] List passkeys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.WebAuthnCredentialResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/passkeys/register/begin": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start a WebAuthn registration ceremony. Pass options to navigator.credentials.create() and send the result to the finish endpoint together with ceremony_id within 5 minutes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-passkeys"
+                ],
+                "summary": "[User] Begin passkey registration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.PasskeyCeremonyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/passkeys/register/finish": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Verify the authenticator response and store the new passkey",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-passkeys"
+                ],
+                "summary": "[User] Finish passkey registration",
+                "parameters": [
+                    {
+                        "description": "Ceremony ID, optional name and the PublicKeyCredential",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.PasskeyRegisterFinishRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.WebAuthnCredentialResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/passkeys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a passkey from the current user's account. The last remaining login method of an account cannot be removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-passkeys"
+                ],
+                "summary": "[User] Delete passkey",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Passkey ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change user's own password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-profile"
+                ],
+                "summary": "[User] Change password",
+                "parameters": [
+                    {
+                        "description": "Password change data",
+                        "name": "passwords",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the current user's role and the permissions it grants",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-profile"
+                ],
+                "summary": "[User] Get my permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.UserPermissionsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get current user's profile information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-profile"
+                ],
+                "summary": "[User] Get own profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update current user's profile information (limited fields)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-profile"
+                ],
+                "summary": "[User] Update own profile",
+                "parameters": [
+                    {
+                        "description": "User profile data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UserProfileUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the current user's active sessions (devices). The session making the request is marked as current.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-sessions"
+                ],
+                "summary": "[User] List active sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.SessionResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke one of the current user's sessions, logging that device out",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-sessions"
+                ],
+                "summary": "[User] Revoke a session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the current user's personal access tokens. Token values are never returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-tokens"
+                ],
+                "summary": "[User] List personal access tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.PersonalAccessTokenResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.InternalServerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create an API key for scripts and other machine clients. The token is only returned in this response; send it as \"Authorization: Bearer lk_...\" or \"X-API-Key: lk_...\". Available scopes: tasks:read, tasks:write, invite_codes:read, invite_codes:write.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-tokens"
+                ],
+                "summary": "[User] Create personal access token",
+                "parameters": [
+                    {
+                        "description": "Name, scopes and optional lifetime in days",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.CreatePersonalAccessTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.PersonalAccessTokenResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/tokens/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get one of the current user's personal access tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-tokens"
+                ],
+                "summary": "[User] Get personal access token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.PersonalAccessTokenResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rename one of the current user's personal access tokens and/or replace its scopes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-tokens"
+                ],
+                "summary": "[User] Update personal access token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name and/or scopes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/service.UpdatePersonalAccessTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/response.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.PersonalAccessTokenResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke one of the current user's personal access tokens immediately",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user-tokens"
+                ],
+                "summary": "[User] Delete personal access token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.MessageOnlyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/response.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.NotFoundResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handler.AdminUserUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "handler.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "old_password"
+            ],
+            "properties": {
+                "new_password": {
+                    "description": "Checked against the password policy",
+                    "type": "string"
+                },
+                "old_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LinkIdentityRequest": {
+            "type": "object",
+            "properties": {
+                "return_to": {
+                    "description": "URL to send the browser back to after the provider login, see /auth/{provider}",
+                    "type": "string"
+                },
+                "telegram_auth": {
+                    "description": "Login Widget data, required for telegram",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.LinkIdentityResponse": {
+            "type": "object",
+            "properties": {
+                "authorization_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ProfileResponse": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Timestamp Fields",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Core Identity Fields",
+                    "type": "string"
+                },
+                "email_verified": {
+                    "description": "Verification and Two-Factor Authentication",
+                    "type": "boolean"
+                },
+                "github_id": {
+                    "type": "string"
+                },
+                "google_id": {
+                    "description": "Legacy provider account IDs, kept for existing clients (use /user/identities instead)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Primary Key",
+                    "type": "integer"
+                },
+                "impersonation": {
+                    "description": "Set while an admin is impersonating the user",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.ImpersonationInfo"
+                        }
+                    ]
+                },
+                "invite_code_id": {
+                    "description": "Invite Code Fields",
+                    "type": "integer"
+                },
+                "invite_code_used": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "Authentication Fields (excluding password)",
+                    "type": "string"
+                },
+                "provider_data": {
+                    "description": "Provider Metadata (only show if not empty)",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "telegram_id": {
+                    "type": "string"
+                },
+                "totp_enabled": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UserProfileUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.InviteCodeResponse": {
+            "type": "object",
+            "properties": {
                 "code": {
                     "description": "Invite code string",
                     "type": "string",
-                    "example": "a1b2c3d4e5f6789012345678901234567890abcd"
+                    "example": "a1b2c3d4e5f6789012345678901234567890abcd"
+                },
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "created_by": {
+                    "description": "Optional related data",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.UserResponse"
+                        }
+                    ]
+                },
+                "created_by_id": {
+                    "description": "Creator user ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "description": {
+                    "description": "Description",
+                    "type": "string",
+                    "example": "Friend invitation code"
+                },
+                "id": {
+                    "description": "Invite code ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "max_uses": {
+                    "description": "Maximum number of uses",
+                    "type": "integer",
+                    "example": 10
+                },
+                "organization_id": {
+                    "description": "Organization the code invites to",
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "description": "Invite code status",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "used",
+                        "disabled"
+                    ],
+                    "example": "active"
+                },
+                "updated_at": {
+                    "description": "Last update time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "usage_records": {
+                    "description": "Usage records",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.InviteCodeUsageResponse"
+                    }
+                },
+                "used_count": {
+                    "description": "Current usage count",
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "model.InviteCodeUsageResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "description": "Usage record ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "invite_code": {
+                    "description": "Optional related data",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.InviteCodeResponse"
+                        }
+                    ]
+                },
+                "invite_code_id": {
+                    "description": "Invite code ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "ip_address": {
+                    "description": "IP address of the user",
+                    "type": "string",
+                    "example": "192.168.1.100"
+                },
+                "used_at": {
+                    "description": "When the code was used",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "used_by": {
+                    "description": "User who used the code",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.UserResponse"
+                        }
+                    ]
+                },
+                "used_by_id": {
+                    "description": "User ID who used the code",
+                    "type": "integer",
+                    "example": 2
+                },
+                "user_agent": {
+                    "description": "User agent string",
+                    "type": "string",
+                    "example": "Mozilla/5.0..."
+                }
+            }
+        },
+        "model.OrganizationMemberResponse": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "description": "Member avatar URL",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Member email",
+                    "type": "string",
+                    "example": "jane@acme.com"
+                },
+                "joined_at": {
+                    "description": "Time the user joined",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "name": {
+                    "description": "Member display name",
+                    "type": "string",
+                    "example": "Jane"
+                },
+                "role": {
+                    "description": "Role in the organization",
+                    "type": "string",
+                    "example": "member"
+                },
+                "user_id": {
+                    "description": "Member user ID",
+                    "type": "integer",
+                    "example": 2
+                },
+                "username": {
+                    "description": "Member username",
+                    "type": "string",
+                    "example": "jane"
+                }
+            }
+        },
+        "model.OrganizationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "description": {
+                    "description": "Description",
+                    "type": "string",
+                    "example": "The Acme team"
+                },
+                "id": {
+                    "description": "Organization ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Name",
+                    "type": "string",
+                    "example": "Acme"
+                },
+                "owner_id": {
+                    "description": "User ID of the owner",
+                    "type": "integer",
+                    "example": 1
+                },
+                "role": {
+                    "description": "Role of the current user in the organization",
+                    "type": "string",
+                    "example": "owner"
+                }
+            }
+        },
+        "model.PersonalAccessTokenResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "expires_at": {
+                    "description": "Expiry time, absent if the token never expires",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Token ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "description": "Last time the token was used",
+                    "type": "string"
+                },
+                "last_used_ip": {
+                    "description": "IP address of the last use",
+                    "type": "string",
+                    "example": "10.0.0.1"
+                },
+                "name": {
+                    "description": "Name given by the owner",
+                    "type": "string",
+                    "example": "CI deploy"
+                },
+                "prefix": {
+                    "description": "First characters of the token",
+                    "type": "string",
+                    "example": "lk_3q2-7wEr"
+                },
+                "scopes": {
+                    "description": "Granted scopes",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tasks:write"
+                    ]
+                },
+                "token": {
+                    "description": "Raw token, only returned on creation",
+                    "type": "string"
+                }
+            }
+        },
+        "model.RoleResponse": {
+            "type": "object",
+            "properties": {
+                "built_in": {
+                    "description": "Built-in roles cannot be deleted",
+                    "type": "boolean",
+                    "example": false
+                },
+                "created_at": {
+                    "description": "Creation time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "description": {
+                    "description": "Description",
+                    "type": "string",
+                    "example": "Customer support"
+                },
+                "id": {
+                    "description": "Role ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Role name, stored in the user's role field",
+                    "type": "string",
+                    "example": "support"
+                },
+                "permissions": {
+                    "description": "Granted permissions",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                },
+                "updated_at": {
+                    "description": "Last update time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "model.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Login time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "current": {
+                    "description": "Whether this is the session making the request",
+                    "type": "boolean",
+                    "example": true
+                },
+                "device": {
+                    "description": "Device description",
+                    "type": "string",
+                    "example": "Chrome on macOS"
+                },
+                "expires_at": {
+                    "description": "Time the session ends on its own, if it cannot be refreshed",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Session ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "impersonator_id": {
+                    "description": "Admin impersonating the user in this session",
+                    "type": "integer",
+                    "example": 1
+                },
+                "ip_address": {
+                    "description": "Last known IP address",
+                    "type": "string",
+                    "example": "192.168.1.100"
+                },
+                "last_seen_at": {
+                    "description": "Last activity time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "provider": {
+                    "description": "Login method",
+                    "type": "string",
+                    "example": "local"
+                },
+                "revoked_at": {
+                    "description": "Revocation time",
+                    "type": "string"
+                },
+                "user_agent": {
+                    "description": "User agent string",
+                    "type": "string",
+                    "example": "Mozilla/5.0..."
+                },
+                "user_id": {
+                    "description": "Owner user ID",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "model.UserIdentity": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Timestamp Fields",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email reported by the provider when the account was linked",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Primary Key",
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "e.g. the OIDC \"sub\" claim",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "Core Fields",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.UserResponse": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "description": "Timestamp Fields",
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Core Identity Fields",
+                    "type": "string"
+                },
+                "email_verified": {
+                    "description": "Verification and Two-Factor Authentication",
+                    "type": "boolean"
+                },
+                "github_id": {
+                    "type": "string"
+                },
+                "google_id": {
+                    "description": "Legacy provider account IDs, kept for existing clients (use /user/identities instead)",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Primary Key",
+                    "type": "integer"
+                },
+                "invite_code_id": {
+                    "description": "Invite Code Fields",
+                    "type": "integer"
+                },
+                "invite_code_used": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "Authentication Fields (excluding password)",
+                    "type": "string"
+                },
+                "provider_data": {
+                    "description": "Provider Metadata (only show if not empty)",
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "telegram_id": {
+                    "type": "string"
+                },
+                "totp_enabled": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebAuthnCredentialResponse": {
+            "type": "object",
+            "properties": {
+                "aaguid": {
+                    "description": "Authenticator model",
+                    "type": "string",
+                    "example": "fbfc3007-154e-4ecc-8c0b-6e020557d7bd"
+                },
+                "attachment": {
+                    "description": "platform or cross-platform",
+                    "type": "string",
+                    "example": "platform"
+                },
+                "backup_eligible": {
+                    "description": "Can be synced between devices",
+                    "type": "boolean",
+                    "example": true
+                },
+                "backup_state": {
+                    "description": "Currently synced",
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "description": "Registration time",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "description": "Passkey ID",
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "description": "Last successful login",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "User supplied label",
+                    "type": "string",
+                    "example": "MacBook Touch ID"
                 },
-                "created_at": {
-                    "description": "Creation time",
+                "transports": {
+                    "description": "Supported transports",
                     "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                    "example": "internal,hybrid"
+                }
+            }
+        },
+        "queue.ScheduleStatus": {
+            "type": "object",
+            "properties": {
+                "last_run": {
+                    "description": "Last time a task was enqueued",
+                    "type": "string",
+                    "example": "2024-01-01T03:00:00Z"
                 },
-                "created_by": {
-                    "description": "Optional related data",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.UserResponse"
-                        }
-                    ]
+                "name": {
+                    "description": "Unique schedule name",
+                    "type": "string",
+                    "example": "nightly-cleanup"
                 },
-                "created_by_id": {
-                    "description": "Creator user ID",
-                    "type": "integer",
-                    "example": 1
+                "next_run": {
+                    "description": "Next time the cron expression fires",
+                    "type": "string",
+                    "example": "2024-01-02T03:00:00Z"
                 },
-                "description": {
-                    "description": "Description",
+                "paused": {
+                    "description": "Paused schedules do not fire",
+                    "type": "boolean",
+                    "example": false
+                },
+                "payload": {
+                    "description": "Payload of the enqueued task",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "queue_name": {
+                    "description": "Queue the task type is routed to",
                     "type": "string",
-                    "example": "Friend invitation code"
+                    "example": "low"
                 },
-                "id": {
-                    "description": "Invite code ID",
+                "spec": {
+                    "description": "Cron expression, evaluated in server time",
+                    "type": "string",
+                    "example": "0 3 * * *"
+                },
+                "task_type": {
+                    "description": "Type of the enqueued task",
+                    "type": "string",
+                    "example": "data_processing"
+                }
+            }
+        },
+        "response.BadRequestResponse": {
+            "description": "Bad Request response format",
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "integer",
-                    "example": 1
+                    "example": 4000
                 },
-                "max_uses": {
-                    "description": "Maximum number of uses",
+                "message": {
+                    "type": "string",
+                    "example": "Invalid request parameters"
+                }
+            }
+        },
+        "response.ConflictResponse": {
+            "description": "Conflict response format",
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "integer",
-                    "example": 10
+                    "example": 4009
                 },
-                "status": {
-                    "description": "Invite code status",
+                "message": {
                     "type": "string",
-                    "enum": [
-                        "active",
-                        "used",
-                        "disabled"
-                    ],
-                    "example": "active"
+                    "example": "Resource already exists"
+                }
+            }
+        },
+        "response.ForbiddenResponse": {
+            "description": "Forbidden response format",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 4003
                 },
-                "updated_at": {
-                    "description": "Last update time",
+                "message": {
                     "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                    "example": "Access denied"
+                }
+            }
+        },
+        "response.InternalServerErrorResponse": {
+            "description": "Internal Server Error response format",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 5000
                 },
-                "usage_records": {
-                    "description": "Usage records",
+                "message": {
+                    "type": "string",
+                    "example": "Internal server error"
+                }
+            }
+        },
+        "response.ListDataInfo": {
+            "description": "List response data structure",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "List items",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.InviteCodeUsageResponse"
+                        "type": "object"
                     }
                 },
-                "used_count": {
-                    "description": "Current usage count",
+                "pagination": {
+                    "description": "Pagination information",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/response.PaginationResponse"
+                        }
+                    ]
+                }
+            }
+        },
+        "response.MessageOnlyResponse": {
+            "description": "Message only response format",
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "integer",
                     "example": 0
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Operation completed successfully"
                 }
             }
         },
-        "model.InviteCodeUsageResponse": {
+        "response.NotFoundResponse": {
+            "description": "Not Found response format",
             "type": "object",
             "properties": {
-                "created_at": {
-                    "description": "Creation time",
+                "code": {
+                    "type": "integer",
+                    "example": 4004
+                },
+                "message": {
                     "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                    "example": "Resource not found"
+                }
+            }
+        },
+        "response.PaginationResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
                 },
-                "id": {
-                    "description": "Usage record ID",
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.ProviderFilterResponse": {
+            "description": "Provider filter response format with pagination and provider information",
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "integer",
-                    "example": 1
+                    "example": 0
                 },
-                "invite_code": {
-                    "description": "Optional related data",
+                "data": {
+                    "$ref": "#/definitions/response.ProviderFilterResponseData"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Users retrieved successfully"
+                }
+            }
+        },
+        "response.ProviderFilterResponseData": {
+            "description": "Provider filter response data structure",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "Filtered items",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "pagination": {
+                    "description": "Pagination information",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/model.InviteCodeResponse"
+                            "$ref": "#/definitions/response.PaginationResponse"
                         }
                     ]
                 },
-                "invite_code_id": {
-                    "description": "Invite code ID",
+                "provider": {
+                    "description": "OAuth provider",
+                    "type": "string",
+                    "example": "google"
+                }
+            }
+        },
+        "response.SearchResponse": {
+            "description": "Search response format with pagination and query information",
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "integer",
-                    "example": 1
+                    "example": 0
                 },
-                "ip_address": {
-                    "description": "IP address of the user",
-                    "type": "string",
-                    "example": "192.168.1.100"
+                "data": {
+                    "$ref": "#/definitions/response.SearchResponseData"
                 },
-                "used_at": {
-                    "description": "When the code was used",
+                "message": {
                     "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                    "example": "Search completed"
+                }
+            }
+        },
+        "response.SearchResponseData": {
+            "description": "Search response data structure",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "Search result items",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
                 },
-                "used_by": {
-                    "description": "User who used the code",
+                "pagination": {
+                    "description": "Pagination information",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/model.UserResponse"
+                            "$ref": "#/definitions/response.PaginationResponse"
                         }
                     ]
                 },
-                "used_by_id": {
-                    "description": "User ID who used the code",
+                "query": {
+                    "description": "Search query",
+                    "type": "string",
+                    "example": "search term"
+                }
+            }
+        },
+        "response.StandardListResponse": {
+            "description": "Standard API paginated list response format",
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "integer",
-                    "example": 2
+                    "example": 0
                 },
-                "user_agent": {
-                    "description": "User agent string",
+                "data": {
+                    "$ref": "#/definitions/response.ListDataInfo"
+                },
+                "message": {
                     "type": "string",
-                    "example": "Mozilla/5.0..."
+                    "example": "success"
                 }
             }
         },
-        "model.UserResponse": {
+        "response.StandardResponse": {
+            "description": "Standard API response format",
             "type": "object",
             "properties": {
-                "avatar": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "description": "Timestamp Fields",
-                    "type": "string"
-                },
-                "deleted_at": {
-                    "type": "string"
-                },
-                "email": {
-                    "description": "Core Identity Fields",
-                    "type": "string"
-                },
-                "github_id": {
-                    "type": "string"
-                },
-                "google_id": {
-                    "description": "OAuth Provider IDs (only show if not empty)",
-                    "type": "string"
-                },
-                "id": {
-                    "description": "Primary Key",
-                    "type": "integer"
-                },
-                "invite_code_id": {
-                    "description": "Invite Code Fields",
-                    "type": "integer"
-                },
-                "invite_code_used": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "provider": {
-                    "description": "Authentication Fields (excluding password)",
-                    "type": "string"
-                },
-                "provider_data": {
-                    "description": "Provider Metadata (only show if not empty)",
-                    "type": "string"
-                },
-                "role": {
-                    "type": "string"
-                },
-                "status": {
-                    "type": "string"
-                },
-                "telegram_id": {
-                    "type": "string"
+                "code": {
+                    "description": "Response code (0 for success, non-zero for errors)",
+                    "type": "integer",
+                    "example": 0
                 },
-                "updated_at": {
-                    "type": "string"
+                "data": {
+                    "description": "Response data (optional)",
+                    "type": "object"
                 },
-                "username": {
-                    "type": "string"
+                "message": {
+                    "description": "Response message",
+                    "type": "string",
+                    "example": "success"
                 }
             }
         },
-        "response.BadRequestResponse": {
-            "description": "Bad Request response format",
+        "response.TooManyRequestsResponse": {
+            "description": "Too Many Requests response format",
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
-                    "example": 4000
+                    "example": 4029
                 },
                 "message": {
                     "type": "string",
-                    "example": "Invalid request parameters"
+                    "example": "Too many failed login attempts, please try again later"
                 }
             }
         },
-        "response.ConflictResponse": {
-            "description": "Conflict response format",
+        "response.UnauthorizedResponse": {
+            "description": "Unauthorized response format",
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
-                    "example": 4009
+                    "example": 4001
                 },
                 "message": {
                     "type": "string",
-                    "example": "Resource already exists"
+                    "example": "Authentication required"
                 }
             }
         },
-        "response.ForbiddenResponse": {
-            "description": "Forbidden response format",
+        "service.AuthResponse": {
             "type": "object",
             "properties": {
-                "code": {
+                "email_verification_required": {
+                    "type": "boolean"
+                },
+                "mfa_required": {
+                    "type": "boolean"
+                },
+                "mfa_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "$ref": "#/definitions/service.TokenResponse"
+                },
+                "user": {
+                    "$ref": "#/definitions/model.UserResponse"
+                }
+            }
+        },
+        "service.CreateInviteCodeRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "Description of the invite code",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Friend invitation code"
+                },
+                "max_uses": {
+                    "description": "Maximum number of times the code can be used",
                     "type": "integer",
-                    "example": 4003
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 10
+                }
+            }
+        },
+        "service.CreateOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "The Acme team"
                 },
-                "message": {
+                "name": {
                     "type": "string",
-                    "example": "Access denied"
+                    "maxLength": 100,
+                    "example": "Acme"
                 }
             }
         },
-        "response.InternalServerErrorResponse": {
-            "description": "Internal Server Error response format",
+        "service.CreatePersonalAccessTokenRequest": {
             "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
             "properties": {
-                "code": {
+                "expires_in_days": {
+                    "description": "0 for a token that never expires",
                     "type": "integer",
-                    "example": 5000
+                    "maximum": 3650,
+                    "minimum": 0,
+                    "example": 90
                 },
-                "message": {
+                "name": {
                     "type": "string",
-                    "example": "Internal server error"
+                    "maxLength": 100,
+                    "example": "CI deploy"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tasks:write"
+                    ]
                 }
             }
         },
-        "response.ListDataInfo": {
-            "description": "List response data structure",
+        "service.CreateRoleRequest": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "items": {
-                    "description": "List items",
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Customer support"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "example": "support"
+                },
+                "permissions": {
                     "type": "array",
                     "items": {
-                        "type": "object"
-                    }
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                }
+            }
+        },
+        "service.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.ImpersonationInfo": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "Time the impersonation token expires",
+                    "type": "string",
+                    "example": "2024-01-01T00:15:00Z"
                 },
-                "pagination": {
-                    "description": "Pagination information",
+                "impersonator_email": {
+                    "description": "Email of the admin",
+                    "type": "string",
+                    "example": "admin@linke.dev"
+                },
+                "impersonator_id": {
+                    "description": "Admin acting as the user",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "service.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "description": "Access token acting as the user, cannot be refreshed",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/response.PaginationResponse"
+                            "$ref": "#/definitions/service.TokenResponse"
+                        }
+                    ]
+                },
+                "user": {
+                    "description": "The impersonated user",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.UserResponse"
                         }
                     ]
                 }
             }
         },
-        "response.MessageOnlyResponse": {
-            "description": "Message only response format",
+        "service.JoinOrganizationRequest": {
             "type": "object",
+            "required": [
+                "code"
+            ],
             "properties": {
                 "code": {
-                    "type": "integer",
-                    "example": 0
-                },
-                "message": {
                     "type": "string",
-                    "example": "Operation completed successfully"
+                    "example": "a1b2c3d4e5f6789012345678901234567890abcd"
                 }
             }
         },
-        "response.NotFoundResponse": {
-            "description": "Not Found response format",
+        "service.LoginLockoutStatus": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 4004
+                "email": {
+                    "type": "string"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "Resource not found"
+                "locked": {
+                    "type": "boolean"
+                },
+                "locked_until": {
+                    "type": "string"
+                },
+                "recent_failures": {
+                    "description": "Failed logins within the current window",
+                    "type": "integer"
                 }
             }
         },
-        "response.PaginationResponse": {
+        "service.LoginRequest": {
             "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
             "properties": {
-                "limit": {
-                    "type": "integer"
-                },
-                "page": {
-                    "type": "integer"
+                "email": {
+                    "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.LogoutRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "description": "Optional, revokes the refresh token family as well",
+                    "type": "string"
+                }
+            }
+        },
+        "service.MFACodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "description": "TOTP code or recovery code",
+                    "type": "string"
                 }
             }
         },
-        "response.ProviderFilterResponse": {
-            "description": "Provider filter response format with pagination and provider information",
+        "service.MFAStatusResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 0
+                "enrollment_required": {
+                    "description": "Admin routes stay blocked until TOTP is enabled",
+                    "type": "boolean"
                 },
-                "data": {
-                    "$ref": "#/definitions/response.ProviderFilterResponseData"
+                "recovery_codes_remaining": {
+                    "type": "integer"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "Users retrieved successfully"
+                "totp_enabled": {
+                    "type": "boolean"
+                },
+                "totp_enabled_at": {
+                    "type": "string"
                 }
             }
         },
-        "response.ProviderFilterResponseData": {
-            "description": "Provider filter response data structure",
+        "service.MFAVerifyRequest": {
             "type": "object",
+            "required": [
+                "code",
+                "mfa_token"
+            ],
             "properties": {
-                "items": {
-                    "description": "Filtered items",
-                    "type": "array",
-                    "items": {
-                        "type": "object"
-                    }
-                },
-                "pagination": {
-                    "description": "Pagination information",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/response.PaginationResponse"
-                        }
-                    ]
+                "code": {
+                    "description": "TOTP code or recovery code",
+                    "type": "string"
                 },
-                "provider": {
-                    "description": "OAuth provider",
-                    "type": "string",
-                    "example": "google"
+                "mfa_token": {
+                    "description": "Token returned by /auth/login when MFA is required",
+                    "type": "string"
                 }
             }
         },
-        "response.SearchResponse": {
-            "description": "Search response format with pagination and query information",
+        "service.MagicLinkRequest": {
             "type": "object",
+            "required": [
+                "email"
+            ],
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 0
-                },
-                "data": {
-                    "$ref": "#/definitions/response.SearchResponseData"
-                },
-                "message": {
-                    "type": "string",
-                    "example": "Search completed"
+                "email": {
+                    "type": "string"
                 }
             }
         },
-        "response.SearchResponseData": {
-            "description": "Search response data structure",
+        "service.MagicLinkVerifyRequest": {
             "type": "object",
+            "required": [
+                "token"
+            ],
             "properties": {
-                "items": {
-                    "description": "Search result items",
-                    "type": "array",
-                    "items": {
-                        "type": "object"
-                    }
-                },
-                "pagination": {
-                    "description": "Pagination information",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/response.PaginationResponse"
-                        }
-                    ]
+                "invite_code": {
+                    "description": "Only used when the link creates a new account",
+                    "type": "string"
                 },
-                "query": {
-                    "description": "Search query",
-                    "type": "string",
-                    "example": "search term"
+                "token": {
+                    "type": "string"
                 }
             }
         },
-        "response.StandardListResponse": {
-            "description": "Standard API paginated list response format",
+        "service.PasskeyCeremonyResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 0
-                },
-                "data": {
-                    "$ref": "#/definitions/response.ListDataInfo"
+                "ceremony_id": {
+                    "type": "string"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "success"
+                "options": {
+                    "description": "PublicKeyCredentialCreationOptions or PublicKeyCredentialRequestOptions"
                 }
             }
         },
-        "response.StandardResponse": {
-            "description": "Standard API response format",
+        "service.PasskeyLoginFinishRequest": {
             "type": "object",
+            "required": [
+                "ceremony_id",
+                "credential"
+            ],
             "properties": {
-                "code": {
-                    "description": "Response code (0 for success, non-zero for errors)",
-                    "type": "integer",
-                    "example": 0
+                "ceremony_id": {
+                    "description": "Returned by the begin step",
+                    "type": "string"
                 },
-                "data": {
-                    "description": "Response data (optional)",
+                "credential": {
+                    "description": "PublicKeyCredential from navigator.credentials.get()",
                     "type": "object"
-                },
-                "message": {
-                    "description": "Response message",
-                    "type": "string",
-                    "example": "success"
                 }
             }
         },
-        "response.UnauthorizedResponse": {
-            "description": "Unauthorized response format",
+        "service.PasskeyRegisterFinishRequest": {
             "type": "object",
+            "required": [
+                "ceremony_id",
+                "credential"
+            ],
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 4001
+                "ceremony_id": {
+                    "description": "Returned by the begin step",
+                    "type": "string"
                 },
-                "message": {
+                "credential": {
+                    "description": "PublicKeyCredential from navigator.credentials.create()",
+                    "type": "object"
+                },
+                "name": {
+                    "description": "Optional label for the passkey",
                     "type": "string",
-                    "example": "Authentication required"
+                    "maxLength": 100
                 }
             }
         },
-        "service.AuthResponse": {
+        "service.RecoveryCodesResponse": {
             "type": "object",
             "properties": {
-                "token": {
-                    "$ref": "#/definitions/service.TokenResponse"
-                },
-                "user": {
-                    "$ref": "#/definitions/model.UserResponse"
+                "recovery_codes": {
+                    "description": "Shown once, store them somewhere safe",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "service.CreateInviteCodeRequest": {
+        "service.RefreshTokenRequest": {
             "type": "object",
+            "required": [
+                "refresh_token"
+            ],
             "properties": {
-                "description": {
-                    "description": "Description of the invite code",
-                    "type": "string",
-                    "maxLength": 255,
-                    "example": "Friend invitation code"
-                },
-                "max_uses": {
-                    "description": "Maximum number of times the code can be used",
-                    "type": "integer",
-                    "maximum": 100,
-                    "minimum": 1,
-                    "example": 10
+                "refresh_token": {
+                    "type": "string"
                 }
             }
         },
-        "service.LoginRequest": {
+        "service.RegisterRequest": {
             "type": "object",
             "required": [
                 "email",
@@ -2795,28 +7551,63 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
+                "invite_code": {
+                    "description": "Optional invite code",
+                    "type": "string"
+                },
                 "password": {
+                    "description": "Checked against the password policy",
                     "type": "string"
                 }
             }
         },
-        "service.RegisterRequest": {
+        "service.ResendVerificationRequest": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "email"
             ],
             "properties": {
                 "email": {
                     "type": "string"
+                }
+            }
+        },
+        "service.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "description": "Checked against the password policy",
+                    "type": "string"
                 },
-                "invite_code": {
-                    "description": "Optional invite code",
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.SwitchOrganizationRequest": {
+            "type": "object",
+            "properties": {
+                "organization_id": {
+                    "description": "0 to clear the active organization",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "service.TOTPEnrollmentResponse": {
+            "type": "object",
+            "properties": {
+                "otpauth_uri": {
+                    "description": "otpauth:// URI, usually rendered as a QR code",
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "secret": {
+                    "description": "Base32 secret for manual entry",
+                    "type": "string"
                 }
             }
         },
@@ -2839,9 +7630,114 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "service.TransferOwnershipRequest": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "service.UpdateMemberRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "member"
+                    ],
+                    "example": "admin"
+                }
+            }
+        },
+        "service.UpdateOrganizationRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "The Acme team"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Acme"
+                }
+            }
+        },
+        "service.UpdatePersonalAccessTokenRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "CI deploy"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tasks:read"
+                    ]
+                }
+            }
+        },
+        "service.UpdateRoleRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Customer support"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                }
+            }
+        },
+        "service.UserPermissionsResponse": {
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tasks:enqueue"
+                    ]
+                },
+                "role": {
+                    "type": "string",
+                    "example": "user"
+                }
+            }
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Personal access token (lk_...) created at /user/tokens. Only accepted by endpoints that list this scheme, and only with the matching scope.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "Type \"Bearer\" followed by a space and JWT token.",
             "type": "apiKey",
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 
@@ -12,13 +13,23 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// AdminUserUpdateRequest holds the profile fields an admin can change; omitted fields are left as they are
+type AdminUserUpdateRequest struct {
+	Email    *string `json:"email" binding:"omitempty,email,max=255"`
+	Username *string `json:"username" binding:"omitempty,max=100"`
+	Name     *string `json:"name" binding:"omitempty,max=255"`
+	Avatar   *string `json:"avatar" binding:"omitempty,max=500"`
+}
+
 type AdminUserHandler struct {
 	userService *service.UserService
+	roleService *service.RoleService
 }
 
-func NewAdminUserHandler(userService *service.UserService) *AdminUserHandler {
+func NewAdminUserHandler(userService *service.UserService, roleService *service.RoleService) *AdminUserHandler {
 	return &AdminUserHandler{
 		userService: userService,
+		roleService: roleService,
 	}
 }
 
@@ -102,7 +113,7 @@ func (h *AdminUserHandler) ListUsers(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "User ID"
-// @Param user body model.UserResponse true "User data"
+// @Param user body AdminUserUpdateRequest true "User data"
 // @Success 200 {object} response.StandardResponse{data=model.UserResponse}
 // @Failure 400 {object} response.BadRequestResponse
 // @Failure 401 {object} response.UnauthorizedResponse
@@ -118,14 +129,33 @@ func (h *AdminUserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	var user model.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var req AdminUserUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(c, err.Error())
 		return
 	}
 
-	user.ID = uint(id)
-	if err := h.userService.UpdateUser(c.Request.Context(), &user); err != nil {
+	user, err := h.userService.GetUserByID(c.Request.Context(), uint(id))
+	if err != nil {
+		response.NotFound(c, "User not found")
+		return
+	}
+
+	// Only fields present in the body are changed
+	if req.Email != nil {
+		user.Email = *req.Email
+	}
+	if req.Username != nil {
+		user.Username = *req.Username
+	}
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+	if req.Avatar != nil {
+		user.Avatar = *req.Avatar
+	}
+
+	if err := h.userService.UpdateUser(c.Request.Context(), user); err != nil {
 		logger.Error("Admin failed to update user",
 			logger.Uint("user_id", uint(id)),
 			logger.Error2("error", err),
@@ -134,12 +164,12 @@ func (h *AdminUserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, user)
+	response.Success(c, user.ToResponse())
 }
 
 // UpdateUserRole godoc
 // @Summary [Admin] Update user role
-// @Description Update user role (admin only)
+// @Description Assign a role to a user (requires roles:assign). The caller must hold every permission of both the user's current role and the new role, and cannot change their own role.
 // @Tags admin-users
 // @Accept json
 // @Produce json
@@ -153,6 +183,11 @@ func (h *AdminUserHandler) UpdateUser(c *gin.Context) {
 // @Failure 404 {object} response.NotFoundResponse
 // @Router /admin/users/{id}/role [put]
 func (h *AdminUserHandler) UpdateUserRole(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -161,7 +196,7 @@ func (h *AdminUserHandler) UpdateUserRole(c *gin.Context) {
 	}
 
 	var roleData struct {
-		Role string `json:"role" binding:"required,oneof=user admin"`
+		Role string `json:"role" binding:"required,max=20"`
 	}
 
 	if err := c.ShouldBindJSON(&roleData); err != nil {
@@ -169,17 +204,29 @@ func (h *AdminUserHandler) UpdateUserRole(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.UpdateUserRole(c.Request.Context(), uint(id), roleData.Role)
-	if err != nil {
-		logger.Error("Admin failed to update user role",
-			logger.Uint("user_id", uint(id)),
-			logger.String("role", roleData.Role),
-			logger.Error2("error", err),
-		)
+	if _, err := h.userService.GetUserByID(c.Request.Context(), uint(id)); err != nil {
 		response.NotFound(c, "User not found")
 		return
 	}
 
+	user, err := h.roleService.AssignRole(c.Request.Context(), actor, uint(id), roleData.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPermissionNotHeld):
+			response.Forbidden(c, err.Error())
+		case errors.Is(err, service.ErrRoleNotFound):
+			response.BadRequest(c, err.Error())
+		default:
+			logger.Error("Admin failed to update user role",
+				logger.Uint("user_id", uint(id)),
+				logger.String("role", roleData.Role),
+				logger.Error2("error", err),
+			)
+			response.BadRequest(c, err.Error())
+		}
+		return
+	}
+
 	response.Success(c, user)
 }
 
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogHandler struct {
+	auditService *service.AuditService
+}
+
+func NewAuditLogHandler(auditService *service.AuditService) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditService: auditService,
+	}
+}
+
+// auditLogCSVHeader is the header row of CSV exports
+var auditLogCSVHeader = []string{"id", "created_at", "actor_id", "impersonator_id", "action", "target_type", "target_id", "before", "after", "ip_address", "user_agent", "request_id"}
+
+// ListAuditLogs godoc
+// @Summary [Admin] List audit logs
+// @Description List audit events, newest first, optionally filtered by actor, target, action and time range (requires audit_logs:read)
+// @Tags admin-audit-logs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param actor_id query int false "Actor user ID"
+// @Param target_type query string false "Target type" example(user)
+// @Param target_id query int false "Target ID"
+// @Param action query string false "Action" example(user.status_update)
+// @Param from query string false "Start of the time range, inclusive (RFC 3339)" example(2024-01-01T00:00:00Z)
+// @Param to query string false "End of the time range, exclusive (RFC 3339)" example(2024-02-01T00:00:00Z)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} response.StandardListResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/audit-logs [get]
+func (h *AuditLogHandler) ListAuditLogs(c *gin.Context) {
+	filter, ok := auditEventFilter(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	events, total, err := h.auditService.ListEvents(c.Request.Context(), filter, limit, offset)
+	if err != nil {
+		response.InternalServerError(c, "Failed to retrieve audit logs")
+		return
+	}
+
+	responses := make([]*model.AuditEventResponse, 0, len(events))
+	for _, event := range events {
+		responses = append(responses, event.ToResponse())
+	}
+
+	response.SuccessList(c, responses, page, limit, total)
+}
+
+// ExportAuditLogs godoc
+// @Summary [Admin] Export audit logs
+// @Description Download every audit event matching the filters, oldest first, as CSV or JSON Lines (requires audit_logs:read)
+// @Tags admin-audit-logs
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Param format query string false "Export format" Enums(csv, jsonl) default(csv)
+// @Param actor_id query int false "Actor user ID"
+// @Param target_type query string false "Target type" example(user)
+// @Param target_id query int false "Target ID"
+// @Param action query string false "Action" example(user.status_update)
+// @Param from query string false "Start of the time range, inclusive (RFC 3339)" example(2024-01-01T00:00:00Z)
+// @Param to query string false "End of the time range, exclusive (RFC 3339)" example(2024-02-01T00:00:00Z)
+// @Success 200 {file} file
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Router /admin/audit-logs/export [get]
+func (h *AuditLogHandler) ExportAuditLogs(c *gin.Context) {
+	filter, ok := auditEventFilter(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		response.BadRequest(c, "Format must be csv or jsonl")
+		return
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	// The response is streamed, so errors after the first row can only be logged
+	var write func(*model.AuditEvent) error
+	var flush func() error
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		writer := csv.NewWriter(c.Writer)
+		if err := writer.Write(auditLogCSVHeader); err != nil {
+			return
+		}
+		write = func(event *model.AuditEvent) error {
+			return writer.Write(auditEventCSVRecord(event))
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(c.Writer)
+		write = func(event *model.AuditEvent) error {
+			return encoder.Encode(event.ToResponse())
+		}
+		flush = func() error { return nil }
+	}
+
+	err := h.auditService.ExportEvents(c.Request.Context(), filter, write)
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		logger.Error("Failed to export audit logs",
+			logger.String("format", format),
+			logger.Error2("error", err),
+		)
+	}
+}
+
+// auditEventFilter parses the audit log filters from the query string, responding with 400 if one is invalid
+func auditEventFilter(c *gin.Context) (*service.AuditEventFilter, bool) {
+	filter := &service.AuditEventFilter{
+		TargetType: c.Query("target_type"),
+		Action:     c.Query("action"),
+	}
+
+	if value := c.Query("actor_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			response.BadRequest(c, "Invalid actor ID")
+			return nil, false
+		}
+		filter.ActorID = uint(id)
+	}
+	if value := c.Query("target_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			response.BadRequest(c, "Invalid target ID")
+			return nil, false
+		}
+		filter.TargetID = uint(id)
+	}
+	if value := c.Query("from"); value != "" {
+		from, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.BadRequest(c, "Invalid from time, use RFC 3339")
+			return nil, false
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.BadRequest(c, "Invalid to time, use RFC 3339")
+			return nil, false
+		}
+		filter.To = &to
+	}
+
+	return filter, true
+}
+
+// auditEventCSVRecord converts an audit event into a CSV row matching auditLogCSVHeader
+func auditEventCSVRecord(event *model.AuditEvent) []string {
+	return []string{
+		strconv.FormatUint(uint64(event.ID), 10),
+		event.CreatedAt.UTC().Format(time.RFC3339),
+		optionalID(event.ActorID),
+		optionalID(event.ImpersonatorID),
+		event.Action,
+		event.TargetType,
+		strconv.FormatUint(uint64(event.TargetID), 10),
+		event.Before,
+		event.After,
+		event.IPAddress,
+		csvSafe(event.UserAgent),
+		event.RequestID,
+	}
+}
+
+// optionalID formats a nullable ID, leaving it empty when unset
+func optionalID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
+
+// csvSafe prefixes client-controlled values that spreadsheet applications would evaluate as formulas
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -1,8 +1,13 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"linke/config"
@@ -17,27 +22,30 @@ import (
 )
 
 type AuthHandler struct {
-	cfg          *config.Config
-	db           *repository.Database
-	oauthService *service.OAuthService
-	authService  *service.AuthService
-	jwtService   *service.JWTService
+	cfg             *config.Config
+	db              *repository.Database
+	oauthService    *service.OAuthService
+	authService     *service.AuthService
+	jwtService      *service.JWTService
+	identityService *service.IdentityService
 }
 
-func NewAuthHandler(cfg *config.Config, db *repository.Database, authService *service.AuthService, jwtService *service.JWTService) *AuthHandler {
+func NewAuthHandler(cfg *config.Config, db *repository.Database, oauthService *service.OAuthService, authService *service.AuthService, jwtService *service.JWTService, identityService *service.IdentityService) *AuthHandler {
 	return &AuthHandler{
-		cfg:          cfg,
-		db:           db,
-		oauthService: service.NewOAuthService(cfg),
-		authService:  authService,
-		jwtService:   jwtService,
+		cfg:             cfg,
+		db:              db,
+		oauthService:    oauthService,
+		authService:     authService,
+		jwtService:      jwtService,
+		identityService: identityService,
 	}
 }
 
 // @Summary OAuth login
-// @Description Initiate OAuth login for various providers
+// @Description Initiate OAuth login for various providers. A random single-use state and a PKCE challenge are attached to the provider request. If return_to is given, it must match OAUTH_ALLOWED_RETURN_URLS and the callback redirects there with the tokens in the URL fragment instead of returning JSON.
 // @Tags auth
-// @Param provider path string true "OAuth provider (google, github, telegram)"
+// @Param provider path string true "OAuth provider (google, github, telegram or a configured OIDC provider key)"
+// @Param return_to query string false "URL to send the browser back to after login (alias: redirect_uri)"
 // @Success 302 {string} string "redirect"
 // @Failure 400 {object} response.BadRequestResponse
 // @Router /auth/{provider} [get]
@@ -54,8 +62,12 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	state := "oauth-state-" + provider
-	url, err := h.oauthService.GetAuthURL(provider, state)
+	returnTo := c.Query("return_to")
+	if returnTo == "" {
+		returnTo = c.Query("redirect_uri")
+	}
+
+	url, err := h.oauthService.BeginAuth(c.Request.Context(), provider, returnTo)
 	if err != nil {
 		response.BadRequest(c, err.Error())
 		return
@@ -65,14 +77,16 @@ func (h *AuthHandler) Login(c *gin.Context) {
 }
 
 // @Summary OAuth callback
-// @Description Handle OAuth callback from providers
+// @Description Handle OAuth callback from providers. The state must come from /auth/{provider} or /user/identities/{provider} and can only be used once. A provider account that is not linked to any user creates a new account, unless its email is already registered (error account_exists); such accounts have to be linked from the account settings. Accounts with two-factor authentication get mfa_required and mfa_token instead of tokens. If the login was started with return_to, the browser is redirected there with access_token, refresh_token, token_type and expires_in (or mfa_required and mfa_token, linked, or error) in the URL fragment.
 // @Tags auth
 // @Param provider path string true "OAuth provider"
 // @Param code query string false "Authorization code (for OAuth2)"
 // @Param state query string false "State parameter (for OAuth2)"
-// @Success 200 {object} response.StandardResponse
+// @Success 200 {object} response.StandardResponse{data=service.AuthResponse}
+// @Success 302 {string} string "redirect to return_to"
 // @Failure 400 {object} response.BadRequestResponse
 // @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 409 {object} response.ConflictResponse
 // @Failure 500 {object} response.InternalServerErrorResponse
 // @Router /auth/{provider}/callback [get]
 func (h *AuthHandler) Callback(c *gin.Context) {
@@ -83,49 +97,163 @@ func (h *AuthHandler) Callback(c *gin.Context) {
 		return
 	}
 
-	code := c.Query("code")
-	state := c.Query("state")
+	state, err := h.oauthService.ConsumeState(c.Request.Context(), provider, c.Query("state"))
+	if err != nil {
+		logger.Warn("OAuth callback with invalid state",
+			logger.String("provider", provider),
+			logger.Error2("error", err),
+		)
+		response.BadRequest(c, "Invalid state parameter")
+		return
+	}
 
-	if code == "" {
-		response.BadRequest(c, "Authorization code is required")
+	if providerErr := c.Query("error"); providerErr != "" {
+		if h.redirectOAuthError(c, state, providerErr) {
+			return
+		}
+		response.BadRequest(c, "Authorization failed: "+providerErr)
 		return
 	}
 
-	expectedState := "oauth-state-" + provider
-	if state != expectedState {
-		response.BadRequest(c, "Invalid state parameter")
+	code := c.Query("code")
+	if code == "" {
+		if h.redirectOAuthError(c, state, "missing_code") {
+			return
+		}
+		response.BadRequest(c, "Authorization code is required")
 		return
 	}
 
-	token, err := h.oauthService.ExchangeCodeForToken(c.Request.Context(), provider, code)
+	token, err := h.oauthService.ExchangeCodeForToken(c.Request.Context(), provider, code, state.CodeVerifier)
 	if err != nil {
+		if h.redirectOAuthError(c, state, "token_exchange_failed") {
+			return
+		}
 		response.InternalServerError(c, "Failed to exchange code for token: " + err.Error())
 		return
 	}
 
-	userInfo, err := h.oauthService.GetUserInfo(c.Request.Context(), provider, token)
+	userInfo, err := h.oauthService.GetUserInfo(c.Request.Context(), provider, token, state.Nonce)
 	if err != nil {
+		if h.redirectOAuthError(c, state, "user_info_failed") {
+			return
+		}
 		response.InternalServerError(c, "Failed to get user info: " + err.Error())
 		return
 	}
 
-	user, err := h.createOrUpdateUser(userInfo)
+	if state.LinkUserID != 0 {
+		h.completeLink(c, state, userInfo)
+		return
+	}
+
+	user, err := h.createOrUpdateUser(c.Request.Context(), userInfo)
 	if err != nil {
+		if errors.Is(err, service.ErrEmailAlreadyRegistered) {
+			if h.redirectOAuthError(c, state, "account_exists") {
+				return
+			}
+			response.Conflict(c, err.Error())
+			return
+		}
+		if h.redirectOAuthError(c, state, "login_failed") {
+			return
+		}
 		response.InternalServerError(c, "Failed to create or update user: " + err.Error())
 		return
 	}
 
-	// Generate JWT token for the user
-	jwtToken, err := h.jwtService.GenerateToken(user)
+	authResponse, err := h.authService.LoginWithIdentity(c.Request.Context(), user, userInfo.Provider, clientInfo(c))
 	if err != nil {
-		response.InternalServerError(c, "Failed to generate JWT token: " + err.Error())
+		if h.redirectOAuthError(c, state, "login_failed") {
+			return
+		}
+		response.Unauthorized(c, err.Error())
 		return
 	}
 
-	response.SuccessWithMessage(c, "Authentication successful", gin.H{
-		"user":  user,
-		"token": jwtToken,
-	})
+	if state.ReturnTo != "" {
+		fragment := url.Values{}
+		if authResponse.MFARequired {
+			fragment.Set("mfa_required", "true")
+			fragment.Set("mfa_token", authResponse.MFAToken)
+		} else {
+			fragment.Set("access_token", authResponse.Token.AccessToken)
+			fragment.Set("refresh_token", authResponse.Token.RefreshToken)
+			fragment.Set("token_type", authResponse.Token.TokenType)
+			fragment.Set("expires_in", strconv.Itoa(authResponse.Token.ExpiresIn))
+		}
+		redirectWithFragment(c, state.ReturnTo, fragment)
+		return
+	}
+
+	if authResponse.MFARequired {
+		response.SuccessWithMessage(c, "Two-factor authentication required", authResponse)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Authentication successful", authResponse)
+}
+
+// completeLink attaches the provider account of a callback started from /user/identities/{provider}
+// to the user who started it
+func (h *AuthHandler) completeLink(c *gin.Context, state *service.OAuthState, userInfo *service.UserInfo) {
+	identity, err := h.identityService.Link(c.Request.Context(), state.LinkUserID, userInfo)
+	if err != nil {
+		code := "link_failed"
+		switch {
+		case errors.Is(err, service.ErrIdentityInUse):
+			code = "identity_in_use"
+		case errors.Is(err, service.ErrProviderAlreadyLinked):
+			code = "provider_already_linked"
+		}
+		if h.redirectOAuthError(c, state, code) {
+			return
+		}
+		if code == "link_failed" {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.Conflict(c, err.Error())
+		return
+	}
+
+	if state.ReturnTo != "" {
+		fragment := url.Values{}
+		fragment.Set("linked", identity.Provider)
+		redirectWithFragment(c, state.ReturnTo, fragment)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Account linked successfully", identity)
+}
+
+// redirectOAuthError sends the browser back to the login's return_to URL with an error code
+// in the fragment. It returns false if the login has no return_to URL.
+func (h *AuthHandler) redirectOAuthError(c *gin.Context, state *service.OAuthState, code string) bool {
+	if state.ReturnTo == "" {
+		return false
+	}
+
+	logger.Warn("OAuth login failed",
+		logger.String("provider", state.Provider),
+		logger.String("error", code),
+	)
+
+	fragment := url.Values{}
+	fragment.Set("error", code)
+	redirectWithFragment(c, state.ReturnTo, fragment)
+	return true
+}
+
+// redirectWithFragment redirects to target with its fragment replaced. Fragments are never
+// sent to servers, so tokens passed this way stay out of access logs and Referer headers.
+func redirectWithFragment(c *gin.Context, target string, fragment url.Values) {
+	if i := strings.IndexByte(target, '#'); i >= 0 {
+		target = target[:i]
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target+"#"+fragment.Encode())
 }
 
 // @Summary Get supported OAuth providers
@@ -158,11 +286,29 @@ func (h *AuthHandler) GetProviders(c *gin.Context) {
 		},
 	}
 
+	for _, oidc := range h.oauthService.OIDCProviders() {
+		providers = append(providers, map[string]interface{}{
+			"name":         oidc.DisplayName(),
+			"key":          oidc.Key(),
+			"login_url":    "/api/v1/auth/" + oidc.Key(),
+			"callback_url": "/api/v1/auth/" + oidc.Key() + "/callback",
+			"enabled":      oidc.Enabled(),
+		})
+	}
+
 	response.Success(c, gin.H{
 		"providers": providers,
 	})
 }
 
+// GetJWKS serves the public keys used to sign access tokens as a JSON Web Key Set,
+// so other services can verify Linke tokens offline. It is mounted at /.well-known/jwks.json
+// and, as the format is fixed by RFC 7517, it does not use the standard response envelope.
+func (h *AuthHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtService.JWKS())
+}
+
 // @Summary Get Telegram Login Widget
 // @Description Get Telegram Login Widget HTML for frontend integration
 // @Tags auth
@@ -216,129 +362,92 @@ func (h *AuthHandler) handleTelegramCallback(c *gin.Context) {
 		return
 	}
 
-	user, err := h.createOrUpdateUser(userInfo)
+	user, err := h.createOrUpdateUser(c.Request.Context(), userInfo)
 	if err != nil {
 		response.InternalServerError(c, "Failed to create or update user: " + err.Error())
 		return
 	}
 
-	// Generate JWT token for the user
-	jwtToken, err := h.jwtService.GenerateToken(user)
+	authResponse, err := h.authService.LoginWithIdentity(c.Request.Context(), user, userInfo.Provider, clientInfo(c))
 	if err != nil {
-		response.InternalServerError(c, "Failed to generate JWT token: " + err.Error())
+		response.Unauthorized(c, err.Error())
 		return
 	}
 
-	response.SuccessWithMessage(c, "Telegram authentication successful", gin.H{
-		"user":  user,
-		"token": jwtToken,
-	})
+	if authResponse.MFARequired {
+		response.SuccessWithMessage(c, "Two-factor authentication required", authResponse)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Telegram authentication successful", authResponse)
 }
 
-func (h *AuthHandler) createOrUpdateUser(userInfo *service.UserInfo) (*model.User, error) {
-	var user model.User
-	var userExists bool
-
-	// Find user by provider-specific ID
-	switch userInfo.Provider {
-	case "google":
-		result := h.db.DB.Where("google_id = ? AND status = ?", userInfo.ID, model.UserStatusActive).First(&user)
-		userExists = result.Error == nil
-		if !userExists {
-			user = model.User{
-				Email:    userInfo.Email,
-				Name:     userInfo.Name,
-				Avatar:   userInfo.Avatar,
-				GoogleID: &userInfo.ID,
-				Username: userInfo.Username,
-				Provider: "google",
-				Status:   model.UserStatusActive,
-				Role:     model.UserRoleUser,
-			}
-		}
+// createOrUpdateUser returns the user the provider account is linked to, or signs up a new
+// user with it. Only accounts created with the provider get their profile synced from it.
+func (h *AuthHandler) createOrUpdateUser(ctx context.Context, userInfo *service.UserInfo) (*model.User, error) {
+	user, err := h.identityService.FindUser(ctx, userInfo.Provider, userInfo.ID)
+	if err != nil {
+		return nil, err
+	}
 
-	case "github":
-		result := h.db.DB.Where("github_id = ? AND status = ?", userInfo.ID, model.UserStatusActive).First(&user)
-		userExists = result.Error == nil
-		if !userExists {
-			user = model.User{
-				Email:    userInfo.Email,
-				Name:     userInfo.Name,
-				Avatar:   userInfo.Avatar,
-				GitHubID: &userInfo.ID,
-				Username: userInfo.Username,
-				Provider: "github",
-				Status:   model.UserStatusActive,
-				Role:     model.UserRoleUser,
-			}
+	if user == nil {
+		// Telegram never shares an email address; every other provider must
+		if userInfo.Email == "" && userInfo.Provider != model.ProviderTelegram {
+			return nil, errors.New("provider did not return an email address")
 		}
 
-	case "telegram":
-		result := h.db.DB.Where("telegram_id = ? AND status = ?", userInfo.ID, model.UserStatusActive).First(&user)
-		userExists = result.Error == nil
-		if !userExists {
-			user = model.User{
-				Email:      userInfo.Email,
-				Name:       userInfo.Name,
-				Avatar:     userInfo.Avatar,
-				TelegramID: &userInfo.ID,
-				Username:   userInfo.Username,
-				Provider:   "telegram",
-				Status:     model.UserStatusActive,
-				Role:       model.UserRoleUser,
-			}
+		providerDataBytes, _ := json.Marshal(userInfo)
+		user = &model.User{
+			Email:        userInfo.Email,
+			Name:         userInfo.Name,
+			Avatar:       userInfo.Avatar,
+			Username:     userInfo.Username,
+			Provider:     userInfo.Provider,
+			Status:       model.UserStatusActive,
+			Role:         model.UserRoleUser,
+			ProviderData: string(providerDataBytes),
+		}
+		if err := h.identityService.CreateUser(ctx, user, userInfo); err != nil {
+			return nil, err
 		}
 
-	default:
-		return nil, gin.Error{Err: nil, Type: gin.ErrorTypePrivate}
+		logger.Info("New OAuth user created",
+			logger.String("provider", userInfo.Provider),
+			logger.String("provider_id", userInfo.ID),
+			logger.Uint("user_id", user.ID),
+		)
+		return user, nil
 	}
 
-	// Handle user creation or update
-	if !userExists {
-		// Create new user
+	// Check if user data has changed (only name and avatar)
+	if user.Provider == userInfo.Provider && h.hasUserDataChanged(user, userInfo) {
+		// Update only name and avatar fields
+		user.Name = userInfo.Name
+		user.Avatar = userInfo.Avatar
+
+		// Update provider data to keep it current
 		providerDataBytes, _ := json.Marshal(userInfo)
 		user.ProviderData = string(providerDataBytes)
-		
-		if err := h.db.DB.Create(&user).Error; err != nil {
+
+		if err := h.db.DB.WithContext(ctx).Save(user).Error; err != nil {
 			return nil, err
 		}
-		
-		logger.Info("New OAuth user created",
+
+		logger.Info("OAuth user profile updated",
 			logger.String("provider", userInfo.Provider),
 			logger.String("provider_id", userInfo.ID),
 			logger.Uint("user_id", user.ID),
+			logger.String("updated_fields", "name,avatar"),
 		)
 	} else {
-		// Check if user data has changed (only name and avatar)
-		if h.hasUserDataChanged(&user, userInfo) {
-			// Update only name and avatar fields
-			user.Name = userInfo.Name
-			user.Avatar = userInfo.Avatar
-			
-			// Update provider data to keep it current
-			providerDataBytes, _ := json.Marshal(userInfo)
-			user.ProviderData = string(providerDataBytes)
-			
-			if err := h.db.DB.Save(&user).Error; err != nil {
-				return nil, err
-			}
-			
-			logger.Info("OAuth user profile updated",
-				logger.String("provider", userInfo.Provider),
-				logger.String("provider_id", userInfo.ID),
-				logger.Uint("user_id", user.ID),
-				logger.String("updated_fields", "name,avatar"),
-			)
-		} else {
-			logger.Debug("OAuth user profile unchanged, skipping update",
-				logger.String("provider", userInfo.Provider),
-				logger.String("provider_id", userInfo.ID),
-				logger.Uint("user_id", user.ID),
-			)
-		}
+		logger.Debug("OAuth user profile unchanged, skipping update",
+			logger.String("provider", userInfo.Provider),
+			logger.String("provider_id", userInfo.ID),
+			logger.Uint("user_id", user.ID),
+		)
 	}
 
-	return &user, nil
+	return user, nil
 }
 
 // hasUserDataChanged checks if user data has changed compared to OAuth provider data
@@ -373,7 +482,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	authResponse, err := h.authService.Register(c.Request.Context(), &req)
+	authResponse, err := h.authService.Register(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
 		logger.Error("Registration failed",
 			logger.String("email", req.Email),
@@ -388,7 +497,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // LoginLocal godoc
 // @Summary User login with email/password
-// @Description Login with email and password
+// @Description Login with email and password. If the account has two-factor authentication enabled, no tokens are returned; instead mfa_required is true and the mfa_token must be exchanged at /auth/mfa/verify. Repeated failures for the same email or client IP are slowed down and then locked out for a while (429 with a Retry-After header).
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -396,6 +505,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success 200 {object} response.StandardResponse{data=service.AuthResponse}
 // @Failure 400 {object} response.BadRequestResponse
 // @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 429 {object} response.TooManyRequestsResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) LoginLocal(c *gin.Context) {
 	var req service.LoginRequest
@@ -404,12 +514,47 @@ func (h *AuthHandler) LoginLocal(c *gin.Context) {
 		return
 	}
 
-	authResponse, err := h.authService.Login(c.Request.Context(), &req)
+	authResponse, err := h.authService.Login(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
 		logger.Warn("Login failed",
 			logger.String("email", req.Email),
 			logger.Error2("error", err),
 		)
+
+		var lockoutErr *service.LockoutError
+		if errors.As(err, &lockoutErr) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockoutErr.RetryAfter.Seconds()))))
+			response.TooManyRequests(c, err.Error())
+			return
+		}
+
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	response.Success(c, authResponse)
+}
+
+// VerifyMFA godoc
+// @Summary Complete login with second factor
+// @Description Exchange the mfa_token returned by /auth/login and a TOTP code (or an unused recovery code) for access and refresh tokens. The mfa_token is valid for 5 minutes and can only be used once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.MFAVerifyRequest true "MFA token and code"
+// @Success 200 {object} response.StandardResponse{data=service.AuthResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req service.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	authResponse, err := h.authService.VerifyMFA(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
 		response.Unauthorized(c, err.Error())
 		return
 	}
@@ -419,54 +564,92 @@ func (h *AuthHandler) LoginLocal(c *gin.Context) {
 
 // Logout godoc
 // @Summary User logout
-// @Description Logout user (client-side token invalidation)
+// @Description Revoke the current access token server-side. If a refresh token is supplied, its token family is revoked as well.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param request body service.LogoutRequest false "Optional refresh token to revoke"
 // @Success 200 {object} response.MessageOnlyResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	// For JWT tokens, logout is typically handled client-side by removing the token
-	// Server-side logout would require a token blacklist, which can be implemented later
+	claimsValue, exists := c.Get(middleware.ClaimsContextKey)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	claims, ok := claimsValue.(*service.Claims)
+	if !ok {
+		response.InternalServerError(c, "Invalid token context")
+		return
+	}
+
+	// The body is optional, so binding errors are ignored
+	var req service.LogoutRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if err := h.authService.Logout(c.Request.Context(), claims, req.RefreshToken); err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Logged out successfully", nil)
+}
+
+// LogoutAll godoc
+// @Summary Log out all sessions
+// @Description Revoke every access and refresh token issued to the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
 	user, exists := c.Get(middleware.AuthContextKey)
-	if exists {
-		if u, ok := user.(*model.User); ok {
-			logger.Info("User logged out",
-				logger.Uint("user_id", u.ID),
-				logger.String("email", u.Email),
-			)
-		}
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	u, ok := user.(*model.User)
+	if !ok {
+		response.InternalServerError(c, "Invalid user context")
+		return
+	}
+
+	if err := h.authService.LogoutAll(c.Request.Context(), u.ID); err != nil {
+		response.InternalServerError(c, err.Error())
+		return
 	}
 
-	response.SuccessWithMessage(c, "Logged out successfully. Please remove the token from client storage.", nil)
+	response.SuccessWithMessage(c, "Logged out of all sessions successfully", nil)
 }
 
 // RefreshToken godoc
 // @Summary Refresh JWT token
-// @Description Refresh an existing JWT token
+// @Description Exchange a refresh token for a new access token. The refresh token is rotated on every call; reusing an old refresh token revokes all tokens issued from the same login.
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Security BearerAuth
+// @Param request body service.RefreshTokenRequest true "Refresh token"
 // @Success 200 {object} response.StandardResponse{data=service.TokenResponse}
+// @Failure 400 {object} response.BadRequestResponse
 // @Failure 401 {object} response.UnauthorizedResponse
 // @Router /auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	authHeader := c.GetHeader("Authorization")
-	if authHeader == "" {
-		response.Unauthorized(c, "Authorization header is required")
-		return
-	}
-
-	tokenParts := strings.SplitN(authHeader, " ", 2)
-	if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-		response.Unauthorized(c, "Invalid authorization header format")
+	var req service.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
 		return
 	}
 
-	token := tokenParts[1]
-	newToken, err := h.jwtService.RefreshToken(token)
+	newToken, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
 		logger.Warn("Token refresh failed",
 			logger.Error2("error", err),
@@ -505,7 +688,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 	var req struct {
 		OldPassword string `json:"old_password" binding:"required"`
-		NewPassword string `json:"new_password" binding:"required,min=6"`
+		NewPassword string `json:"new_password" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -525,14 +708,20 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	response.SuccessWithMessage(c, "Password changed successfully", nil)
 }
 
+// ProfileResponse is the current user's profile, marked when an admin is impersonating the user
+type ProfileResponse struct {
+	*model.UserResponse
+	Impersonation *service.ImpersonationInfo `json:"impersonation,omitempty"` // Set while an admin is impersonating the user
+}
+
 // GetProfile godoc
 // @Summary Get user profile
-// @Description Get current user's profile information
+// @Description Get current user's profile information. When the request is made with an impersonation token, the impersonation field identifies the admin acting as the user.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} response.StandardResponse{data=model.UserResponse}
+// @Success 200 {object} response.StandardResponse{data=handler.ProfileResponse}
 // @Failure 401 {object} response.UnauthorizedResponse
 // @Failure 500 {object} response.InternalServerErrorResponse
 // @Router /auth/profile [get]
@@ -549,5 +738,37 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, u.ToResponse())
+	profile := &ProfileResponse{UserResponse: u.ToResponse()}
+	if claimsValue, exists := c.Get(middleware.ClaimsContextKey); exists {
+		if claims, ok := claimsValue.(*service.Claims); ok {
+			profile.Impersonation = claims.Impersonation()
+		}
+	}
+
+	response.Success(c, profile)
+}
+
+// clientInfo extracts the client IP address and user agent from the request
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
 }
+
+// currentUser returns the authenticated user, writing an error response if there is none
+func currentUser(c *gin.Context) (*model.User, bool) {
+	userValue, exists := c.Get(middleware.AuthContextKey)
+	if !exists {
+		response.Unauthorized(c, "Authentication required")
+		return nil, false
+	}
+
+	user, ok := userValue.(*model.User)
+	if !ok {
+		response.Unauthorized(c, "Invalid user context")
+		return nil, false
+	}
+
+	return user, true
+}
\ No newline at end of file
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"linke/internal/logger"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerificationHandler struct {
+	emailVerificationService *service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(emailVerificationService *service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		emailVerificationService: emailVerificationService,
+	}
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Verify the email address of a local account using the link sent after registration. Each link can only be used once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token query string true "Verification token from the email link"
+// @Success 200 {object} response.StandardResponse{data=model.UserResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Router /auth/verify-email [get]
+func (h *EmailVerificationHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.BadRequest(c, "Verification token is required")
+		return
+	}
+
+	user, err := h.emailVerificationService.Verify(c.Request.Context(), token)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Email verified successfully", user.ToResponse())
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new verification link to an unverified local account. Always succeeds so it cannot be used to discover registered addresses; at most one email is sent per minute.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.ResendVerificationRequest true "Email address"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /auth/verify-email/resend [post]
+func (h *EmailVerificationHandler) ResendVerification(c *gin.Context) {
+	var req service.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.emailVerificationService.Resend(c.Request.Context(), req.Email); err != nil {
+		logger.Error("Failed to resend verification email",
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "If the account exists and is not yet verified, a verification email has been sent", nil)
+}
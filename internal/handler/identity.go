@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IdentityHandler struct {
+	identityService *service.IdentityService
+	oauthService    *service.OAuthService
+}
+
+func NewIdentityHandler(identityService *service.IdentityService, oauthService *service.OAuthService) *IdentityHandler {
+	return &IdentityHandler{
+		identityService: identityService,
+		oauthService:    oauthService,
+	}
+}
+
+// LinkIdentityRequest starts linking a provider account to the current user
+type LinkIdentityRequest struct {
+	// URL to send the browser back to after the provider login, see /auth/{provider}
+	ReturnTo string `json:"return_to"`
+	// Login Widget data, required for telegram
+	TelegramAuth map[string]string `json:"telegram_auth"`
+}
+
+// LinkIdentityResponse contains the provider URL the browser has to visit to confirm the link
+type LinkIdentityResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// ListIdentities godoc
+// @Summary [User] List linked accounts
+// @Description List the provider accounts (Google, GitHub, Telegram, OIDC providers) linked to the current user
+// @Tags user-identities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=[]model.UserIdentity}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /user/identities [get]
+func (h *IdentityHandler) ListIdentities(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	identities, err := h.identityService.ListIdentities(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Error("Failed to list identities",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to list linked accounts")
+		return
+	}
+
+	response.Success(c, identities)
+}
+
+// LinkIdentity godoc
+// @Summary [User] Link provider account
+// @Description Link a provider account to the current user. For OAuth and OIDC providers this returns an authorization_url; the browser has to visit it and the provider callback completes the link (with linked or error in the return_to fragment if return_to is given). For telegram, pass the Login Widget data as telegram_auth and the account is linked immediately. A provider account that is linked to another user cannot be linked.
+// @Tags user-identities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider (google, github, telegram or a configured OIDC provider key)"
+// @Param request body LinkIdentityRequest false "Optional return_to, or telegram_auth for telegram"
+// @Success 200 {object} response.StandardResponse{data=LinkIdentityResponse}
+// @Success 201 {object} response.StandardResponse{data=model.UserIdentity}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 409 {object} response.ConflictResponse
+// @Router /user/identities/{provider} [post]
+func (h *IdentityHandler) LinkIdentity(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req LinkIdentityRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	provider := c.Param("provider")
+	if provider != model.ProviderTelegram {
+		authURL, err := h.oauthService.BeginLink(c.Request.Context(), provider, user.ID, req.ReturnTo)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.Success(c, LinkIdentityResponse{AuthorizationURL: authURL})
+		return
+	}
+
+	if len(req.TelegramAuth) == 0 {
+		response.BadRequest(c, "telegram_auth is required")
+		return
+	}
+
+	userInfo, err := h.oauthService.VerifyTelegramAuth(req.TelegramAuth)
+	if err != nil {
+		response.Unauthorized(c, "Invalid Telegram authentication: "+err.Error())
+		return
+	}
+
+	identity, err := h.identityService.Link(c.Request.Context(), user.ID, userInfo)
+	if err != nil {
+		if errors.Is(err, service.ErrIdentityInUse) || errors.Is(err, service.ErrProviderAlreadyLinked) {
+			response.Conflict(c, err.Error())
+			return
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.CreatedWithMessage(c, "Account linked successfully", identity)
+}
+
+// UnlinkIdentity godoc
+// @Summary [User] Unlink provider account
+// @Description Remove the provider account from the current user. The last remaining login method (password, linked account or passkey) cannot be removed.
+// @Tags user-identities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /user/identities/{provider} [delete]
+func (h *IdentityHandler) UnlinkIdentity(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.identityService.Unlink(c.Request.Context(), user.ID, c.Param("provider")); err != nil {
+		if errors.Is(err, service.ErrLastLoginMethod) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Account unlinked successfully", nil)
+}
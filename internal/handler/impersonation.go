@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"linke/internal/logger"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImpersonationHandler struct {
+	impersonationService *service.ImpersonationService
+	userService          *service.UserService
+}
+
+func NewImpersonationHandler(impersonationService *service.ImpersonationService, userService *service.UserService) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		impersonationService: impersonationService,
+		userService:          userService,
+	}
+}
+
+// ImpersonateUser godoc
+// @Summary [Admin] Impersonate user
+// @Description Issue a short-lived access token that acts as the user (requires users:impersonate). The caller must hold every permission of the user's role. The token carries the caller in its act claim, cannot be refreshed, is rejected by admin routes and sensitive account actions, and the impersonation is recorded in the audit log.
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.StandardResponse{data=service.ImpersonationResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/users/{id}/impersonate [post]
+func (h *ImpersonationHandler) ImpersonateUser(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	if _, err := h.userService.GetUserByID(c.Request.Context(), uint(id)); err != nil {
+		response.NotFound(c, "User not found")
+		return
+	}
+
+	result, err := h.impersonationService.Impersonate(c.Request.Context(), actor, uint(id), clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImpersonateSelf), errors.Is(err, service.ErrImpersonateInactiveUser):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, service.ErrPermissionNotHeld):
+			response.Forbidden(c, err.Error())
+		default:
+			logger.Error("Failed to impersonate user",
+				logger.Uint("actor_id", actor.ID),
+				logger.Uint("user_id", uint(id)),
+				logger.Error2("error", err),
+			)
+			response.InternalServerError(c, "Failed to impersonate user")
+		}
+		return
+	}
+
+	response.Success(c, result)
+}
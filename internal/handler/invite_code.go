@@ -15,12 +15,14 @@ import (
 type InviteCodeHandler struct {
 	inviteCodeService      *service.InviteCodeService
 	inviteCodeUsageService *service.InviteCodeUsageService
+	roleService            *service.RoleService
 }
 
-func NewInviteCodeHandler(inviteCodeService *service.InviteCodeService, inviteCodeUsageService *service.InviteCodeUsageService) *InviteCodeHandler {
+func NewInviteCodeHandler(inviteCodeService *service.InviteCodeService, inviteCodeUsageService *service.InviteCodeUsageService, roleService *service.RoleService) *InviteCodeHandler {
 	return &InviteCodeHandler{
 		inviteCodeService:      inviteCodeService,
 		inviteCodeUsageService: inviteCodeUsageService,
+		roleService:            roleService,
 	}
 }
 
@@ -31,6 +33,7 @@ func NewInviteCodeHandler(inviteCodeService *service.InviteCodeService, inviteCo
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param invite_code body service.CreateInviteCodeRequest true "Invite code data"
 // @Success 201 {object} response.StandardResponse{data=model.InviteCodeResponse}
 // @Failure 400 {object} response.BadRequestResponse
@@ -78,6 +81,7 @@ func (h *InviteCodeHandler) CreateInviteCode(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "Invite code ID"
 // @Success 200 {object} response.StandardResponse{data=model.InviteCodeResponse}
 // @Failure 400 {object} response.BadRequestResponse
@@ -116,8 +120,8 @@ func (h *InviteCodeHandler) GetInviteCode(c *gin.Context) {
 		return
 	}
 
-	// Check if user is the creator or admin
-	if inviteCode.CreatedByID != user.ID && !user.IsAdmin() {
+	// Check if user is the creator or may manage all invite codes
+	if inviteCode.CreatedByID != user.ID && !h.canManageAll(c, user) {
 		response.Forbidden(c, "You can only access your own invite codes")
 		return
 	}
@@ -163,6 +167,7 @@ func (h *InviteCodeHandler) ValidateInviteCode(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "Invite code ID"
 // @Param status body map[string]string true "New status"
 // @Success 200 {object} response.StandardResponse{data=model.InviteCodeResponse}
@@ -207,7 +212,7 @@ func (h *InviteCodeHandler) UpdateInviteCodeStatus(c *gin.Context) {
 		return
 	}
 
-	if inviteCode.CreatedByID != user.ID && !user.IsAdmin() {
+	if inviteCode.CreatedByID != user.ID && !h.canManageAll(c, user) {
 		response.Forbidden(c, "You can only update your own invite codes")
 		return
 	}
@@ -233,6 +238,7 @@ func (h *InviteCodeHandler) UpdateInviteCodeStatus(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "Invite code ID"
 // @Success 200 {object} response.MessageOnlyResponse
 // @Failure 400 {object} response.BadRequestResponse
@@ -268,7 +274,7 @@ func (h *InviteCodeHandler) DeleteInviteCode(c *gin.Context) {
 		return
 	}
 
-	if inviteCode.CreatedByID != user.ID && !user.IsAdmin() {
+	if inviteCode.CreatedByID != user.ID && !h.canManageAll(c, user) {
 		response.Forbidden(c, "You can only delete your own invite codes")
 		return
 	}
@@ -362,6 +368,7 @@ func (h *InviteCodeHandler) ListAllInviteCodes(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
 // @Success 200 {object} response.StandardListResponse
@@ -420,6 +427,7 @@ func (h *InviteCodeHandler) GetMyInviteCodes(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path int true "Invite code ID"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
@@ -457,7 +465,7 @@ func (h *InviteCodeHandler) GetInviteCodeUsages(c *gin.Context) {
 		return
 	}
 
-	if inviteCode.CreatedByID != user.ID && !user.IsAdmin() {
+	if inviteCode.CreatedByID != user.ID && !h.canManageAll(c, user) {
 		response.Forbidden(c, "You can only access your own invite codes")
 		return
 	}
@@ -499,4 +507,17 @@ func (h *InviteCodeHandler) GetInviteCodeUsages(c *gin.Context) {
 	}
 
 	response.SuccessList(c, responseData, page, limit, total)
-}
\ No newline at end of file
+}
+
+// canManageAll reports whether the user may access invite codes created by other users
+func (h *InviteCodeHandler) canManageAll(c *gin.Context, user *model.User) bool {
+	allowed, err := h.roleService.HasPermission(c.Request.Context(), user, model.PermissionInviteCodesManage)
+	if err != nil {
+		logger.Error("Failed to check invite code permission",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return false
+	}
+	return allowed
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"strconv"
+
+	"linke/internal/logger"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoginLockoutHandler struct {
+	loginGuard  *service.LoginGuardService
+	userService *service.UserService
+}
+
+func NewLoginLockoutHandler(loginGuard *service.LoginGuardService, userService *service.UserService) *LoginLockoutHandler {
+	return &LoginLockoutHandler{
+		loginGuard:  loginGuard,
+		userService: userService,
+	}
+}
+
+// GetUserLockout godoc
+// @Summary [Admin] Get user login lockout
+// @Description Get whether a user's email is locked out after repeated failed logins and how many failures are in the current window (admin only)
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.StandardResponse{data=service.LoginLockoutStatus}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/users/{id}/lockout [get]
+func (h *LoginLockoutHandler) GetUserLockout(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), uint(id))
+	if err != nil {
+		response.NotFound(c, "User not found")
+		return
+	}
+
+	status, err := h.loginGuard.GetStatus(c.Request.Context(), user.Email)
+	if err != nil {
+		logger.Error("Failed to get login lockout",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to get login lockout")
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// ClearUserLockout godoc
+// @Summary [Admin] Clear user login lockout
+// @Description Lift a user's login lockout and reset their failed login counter. Lockouts of client IPs expire on their own. (admin only)
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/users/{id}/lockout [delete]
+func (h *LoginLockoutHandler) ClearUserLockout(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), uint(id))
+	if err != nil {
+		response.NotFound(c, "User not found")
+		return
+	}
+
+	if err := h.loginGuard.Clear(c.Request.Context(), user); err != nil {
+		logger.Error("Failed to clear login lockout",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to clear login lockout")
+		return
+	}
+
+	logger.Info("Login lockout cleared by admin",
+		logger.Uint("user_id", user.ID),
+	)
+	response.SuccessWithMessage(c, "Login lockout cleared successfully", nil)
+}
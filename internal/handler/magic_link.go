@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"linke/internal/logger"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MagicLinkHandler struct {
+	magicLinkService *service.MagicLinkService
+	authService      *service.AuthService
+}
+
+func NewMagicLinkHandler(magicLinkService *service.MagicLinkService, authService *service.AuthService) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		magicLinkService: magicLinkService,
+		authService:      authService,
+	}
+}
+
+// RequestMagicLink godoc
+// @Summary Request a magic login link
+// @Description Email a one-time login link. A link sent to an unregistered address creates the account on first use. Always succeeds so it cannot be used to discover registered addresses; at most one email is sent per minute.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.MagicLinkRequest true "Email address"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /auth/magic-link [post]
+func (h *MagicLinkHandler) RequestMagicLink(c *gin.Context) {
+	var req service.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.magicLinkService.Send(c.Request.Context(), req.Email); err != nil {
+		logger.Error("Failed to send magic link",
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to send login link")
+		return
+	}
+
+	response.SuccessWithMessage(c, "If the address can be used to log in, a login link has been sent", nil)
+}
+
+// VerifyMagicLink godoc
+// @Summary Log in with a magic link
+// @Description Redeem the token from a login link. Returns the same payload as /auth/login. invite_code is only used when the link creates a new account and registration requires one.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.MagicLinkVerifyRequest true "Login link token and optional invite code"
+// @Success 200 {object} response.StandardResponse{data=service.AuthResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /auth/magic-link/verify [post]
+func (h *MagicLinkHandler) VerifyMagicLink(c *gin.Context) {
+	var req service.MagicLinkVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	authResponse, err := h.authService.LoginWithMagicLink(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		logger.Warn("Magic link login failed",
+			logger.Error2("error", err),
+		)
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	response.Success(c, authResponse)
+}
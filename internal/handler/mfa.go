@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"strconv"
+
+	"linke/internal/logger"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	mfaService  *service.MFAService
+	userService *service.UserService
+}
+
+func NewMFAHandler(mfaService *service.MFAService, userService *service.UserService) *MFAHandler {
+	return &MFAHandler{
+		mfaService:  mfaService,
+		userService: userService,
+	}
+}
+
+// GetStatus godoc
+// @Summary [User] Get two-factor authentication status
+// @Description Get whether TOTP is enabled for the current user and how many recovery codes are left
+// @Tags user-mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=service.MFAStatusResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /user/mfa [get]
+func (h *MFAHandler) GetStatus(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.mfaService.GetStatus(c.Request.Context(), user)
+	if err != nil {
+		logger.Error("Failed to get MFA status",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to get two-factor authentication status")
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// SetupTOTP godoc
+// @Summary [User] Start TOTP enrollment
+// @Description Generate a new TOTP secret and otpauth URI. TOTP is not enabled until the setup is confirmed with a valid code.
+// @Tags user-mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=service.TOTPEnrollmentResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /user/mfa/totp/setup [post]
+func (h *MFAHandler) SetupTOTP(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginTOTPEnrollment(c.Request.Context(), user)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, enrollment)
+}
+
+// ConfirmTOTP godoc
+// @Summary [User] Confirm TOTP enrollment
+// @Description Enable TOTP by submitting a code from the authenticator app. Returns one-time recovery codes that are only shown once.
+// @Tags user-mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.MFACodeRequest true "TOTP code"
+// @Success 200 {object} response.StandardResponse{data=service.RecoveryCodesResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /user/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTPEnrollment(c.Request.Context(), user, req.Code)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Two-factor authentication enabled successfully", &service.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableTOTP godoc
+// @Summary [User] Disable TOTP
+// @Description Disable two-factor authentication. Requires a current TOTP code or an unused recovery code.
+// @Tags user-mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /user/mfa/totp [delete]
+func (h *MFAHandler) DisableTOTP(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.mfaService.DisableTOTP(c.Request.Context(), user, req.Code); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Two-factor authentication disabled successfully", nil)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary [User] Regenerate recovery codes
+// @Description Replace all recovery codes with a new set. Requires a current TOTP code or an unused recovery code.
+// @Tags user-mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} response.StandardResponse{data=service.RecoveryCodesResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /user/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req service.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), user, req.Code)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, &service.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	})
+}
+
+// ResetUserMFA godoc
+// @Summary [Admin] Reset user two-factor authentication
+// @Description Disable TOTP and delete recovery codes of any user, e.g. after a lost device (admin only)
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/users/{id}/mfa [delete]
+func (h *MFAHandler) ResetUserMFA(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	if _, err := h.userService.GetUserByID(c.Request.Context(), uint(id)); err != nil {
+		response.NotFound(c, "User not found")
+		return
+	}
+
+	if err := h.mfaService.ResetTOTP(c.Request.Context(), uint(id)); err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Two-factor authentication reset successfully", nil)
+}
@@ -0,0 +1,605 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"linke/internal/logger"
+	"linke/internal/middleware"
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+	inviteCodeService   *service.InviteCodeService
+	authService         *service.AuthService
+}
+
+func NewOrganizationHandler(organizationService *service.OrganizationService, inviteCodeService *service.InviteCodeService, authService *service.AuthService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+		inviteCodeService:   inviteCodeService,
+		authService:         authService,
+	}
+}
+
+// CreateOrganization godoc
+// @Summary [User] Create organization
+// @Description Create an organization owned by the current user. Switch to it with /organizations/switch to use the /organizations/current endpoints.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreateOrganizationRequest true "Organization name and description"
+// @Success 201 {object} response.StandardResponse{data=model.OrganizationResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req service.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	organization, err := h.organizationService.Create(c.Request.Context(), user.ID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	resp := organization.ToResponse()
+	resp.Role = model.OrganizationRoleOwner
+	response.CreatedWithMessage(c, "Organization created successfully", resp)
+}
+
+// ListOrganizations godoc
+// @Summary [User] List my organizations
+// @Description List the organizations the current user is a member of, with their role in each
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=[]model.OrganizationResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	organizations, err := h.organizationService.ListForUser(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Error("Failed to list organizations",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to list organizations")
+		return
+	}
+
+	response.Success(c, organizations)
+}
+
+// SwitchOrganization godoc
+// @Summary [User] Switch active organization
+// @Description Make an organization the active one of the current session and get a new access token carrying its ID in the org_id claim. Access tokens issued on refresh keep the active organization. Pass organization_id 0 to clear it.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.SwitchOrganizationRequest true "Organization to switch to"
+// @Success 200 {object} response.StandardResponse{data=service.TokenResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Router /organizations/switch [post]
+func (h *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	claimsValue, exists := c.Get(middleware.ClaimsContextKey)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	claims, ok := claimsValue.(*service.Claims)
+	if !ok {
+		response.InternalServerError(c, "Invalid token context")
+		return
+	}
+
+	var req service.SwitchOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.organizationService.SetActiveOrganization(c.Request.Context(), user.ID, claims.SessionID, req.OrganizationID); err != nil {
+		if errors.Is(err, service.ErrNotOrganizationMember) {
+			response.Forbidden(c, err.Error())
+			return
+		}
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	token, err := h.authService.ReissueAccessToken(c.Request.Context(), user, claims)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Active organization switched successfully", token)
+}
+
+// JoinOrganization godoc
+// @Summary [User] Join organization
+// @Description Join an organization with one of its invite codes. The user becomes a member.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.JoinOrganizationRequest true "Organization invite code"
+// @Success 201 {object} response.StandardResponse{data=model.OrganizationResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 409 {object} response.ConflictResponse
+// @Router /organizations/join [post]
+func (h *OrganizationHandler) JoinOrganization(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req service.JoinOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	member, err := h.organizationService.Join(c.Request.Context(), user.ID, req.Code, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, service.ErrAlreadyOrganizationMember) {
+			response.Conflict(c, err.Error())
+			return
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	resp := member.Organization.ToResponse()
+	resp.Role = member.Role
+	response.CreatedWithMessage(c, "Joined organization successfully", resp)
+}
+
+// GetCurrentOrganization godoc
+// @Summary [Organization] Get active organization
+// @Description Get the active organization of the access token, with the current user's role in it
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=model.OrganizationResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Router /organizations/current [get]
+func (h *OrganizationHandler) GetCurrentOrganization(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	resp := member.Organization.ToResponse()
+	resp.Role = member.Role
+	response.Success(c, resp)
+}
+
+// UpdateCurrentOrganization godoc
+// @Summary [Organization] Update active organization
+// @Description Rename the active organization and/or change its description (organization admins)
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.UpdateOrganizationRequest true "New name and/or description"
+// @Success 200 {object} response.StandardResponse{data=model.OrganizationResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Router /organizations/current [put]
+func (h *OrganizationHandler) UpdateCurrentOrganization(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	organization, err := h.organizationService.Update(c.Request.Context(), member.Organization, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	resp := organization.ToResponse()
+	resp.Role = member.Role
+	response.SuccessWithMessage(c, "Organization updated successfully", resp)
+}
+
+// DeleteCurrentOrganization godoc
+// @Summary [Organization] Delete active organization
+// @Description Delete the active organization (owner only). All members are removed and its invite codes are disabled.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Router /organizations/current [delete]
+func (h *OrganizationHandler) DeleteCurrentOrganization(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.Delete(c.Request.Context(), member); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Organization deleted successfully", nil)
+}
+
+// ListMembers godoc
+// @Summary [Organization] List members
+// @Description List the members of the active organization, owner first
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=[]model.OrganizationMemberResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /organizations/current/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), member.OrganizationID)
+	if err != nil {
+		logger.Error("Failed to list organization members",
+			logger.Uint("organization_id", member.OrganizationID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to list members")
+		return
+	}
+
+	responseData := make([]*model.OrganizationMemberResponse, 0, len(members))
+	for _, m := range members {
+		responseData = append(responseData, m.ToResponse())
+	}
+
+	response.Success(c, responseData)
+}
+
+// UpdateMemberRole godoc
+// @Summary [Organization] Update member role
+// @Description Make another member of the active organization an admin or a regular member (organization admins). Admins can promote members; only the owner can demote admins.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path int true "Member user ID"
+// @Param request body service.UpdateMemberRoleRequest true "New role"
+// @Success 200 {object} response.StandardResponse{data=model.OrganizationMemberResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /organizations/current/members/{user_id}/role [put]
+func (h *OrganizationHandler) UpdateMemberRole(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := memberUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	updated, err := h.organizationService.UpdateMemberRole(c.Request.Context(), member, userID, req.Role)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Member role updated successfully", updated.ToResponse())
+}
+
+// RemoveMember godoc
+// @Summary [Organization] Remove member
+// @Description Remove a member from the active organization (organization admins). Admins can remove members; only the owner can remove admins. The owner cannot be removed.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path int true "Member user ID"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /organizations/current/members/{user_id} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	userID, ok := memberUserIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(c.Request.Context(), member, userID); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Member removed successfully", nil)
+}
+
+// LeaveOrganization godoc
+// @Summary [Organization] Leave active organization
+// @Description Leave the active organization. The owner has to transfer ownership first. Switch to another organization afterwards.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Router /organizations/current/leave [post]
+func (h *OrganizationHandler) LeaveOrganization(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.Leave(c.Request.Context(), member); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Left organization successfully", nil)
+}
+
+// TransferOwnership godoc
+// @Summary [Organization] Transfer ownership
+// @Description Make another member the owner of the active organization (owner only). The previous owner becomes an admin.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.TransferOwnershipRequest true "User ID of the new owner"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /organizations/current/transfer-ownership [post]
+func (h *OrganizationHandler) TransferOwnership(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	var req service.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.organizationService.TransferOwnership(c.Request.Context(), member, req.UserID); err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Ownership transferred successfully", nil)
+}
+
+// CreateInviteCode godoc
+// @Summary [Organization] Create organization invite code
+// @Description Create an invite code for the active organization (organization admins). Users who register with it or join with it through /organizations/join become members.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param invite_code body service.CreateInviteCodeRequest true "Invite code data"
+// @Success 201 {object} response.StandardResponse{data=model.InviteCodeResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /organizations/current/invite-codes [post]
+func (h *OrganizationHandler) CreateInviteCode(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	var req service.CreateInviteCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	inviteCode, err := h.inviteCodeService.CreateOrganizationInviteCode(c.Request.Context(), member.UserID, member.OrganizationID, &req)
+	if err != nil {
+		response.InternalServerError(c, "Failed to create invite code")
+		return
+	}
+
+	response.CreatedWithMessage(c, "Invite code created successfully", inviteCode.ToResponse())
+}
+
+// ListInviteCodes godoc
+// @Summary [Organization] List organization invite codes
+// @Description List the invite codes of the active organization (organization admins)
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} response.StandardListResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /organizations/current/invite-codes [get]
+func (h *OrganizationHandler) ListInviteCodes(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	codes, total, err := h.inviteCodeService.ListInviteCodesByOrganization(c.Request.Context(), member.OrganizationID, limit, offset)
+	if err != nil {
+		logger.Error("Failed to list organization invite codes",
+			logger.Uint("organization_id", member.OrganizationID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to get invite codes")
+		return
+	}
+
+	responseData := make([]*model.InviteCodeResponse, 0, len(codes))
+	for _, code := range codes {
+		responseData = append(responseData, code.ToResponse())
+	}
+
+	response.SuccessList(c, responseData, page, limit, total)
+}
+
+// DeleteInviteCode godoc
+// @Summary [Organization] Delete organization invite code
+// @Description Delete one of the active organization's invite codes (organization admins)
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invite code ID"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /organizations/current/invite-codes/{id} [delete]
+func (h *OrganizationHandler) DeleteInviteCode(c *gin.Context) {
+	member, ok := currentMember(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid invite code ID")
+		return
+	}
+
+	inviteCode, err := h.inviteCodeService.GetInviteCodeByID(c.Request.Context(), uint(id))
+	if err != nil || inviteCode.OrganizationID == nil || *inviteCode.OrganizationID != member.OrganizationID {
+		response.NotFound(c, "Invite code not found")
+		return
+	}
+
+	if err := h.inviteCodeService.DeleteInviteCode(c.Request.Context(), inviteCode.ID); err != nil {
+		response.InternalServerError(c, "Failed to delete invite code")
+		return
+	}
+
+	response.SuccessWithMessage(c, "Invite code deleted successfully", nil)
+}
+
+// currentMember returns the membership in the active organization loaded by RequireOrganization,
+// writing an error response if there is none
+func currentMember(c *gin.Context) (*model.OrganizationMember, bool) {
+	memberValue, exists := c.Get(middleware.OrganizationContextKey)
+	if !exists {
+		response.Forbidden(c, "No active organization")
+		return nil, false
+	}
+
+	member, ok := memberValue.(*model.OrganizationMember)
+	if !ok {
+		response.InternalServerError(c, "Invalid organization context")
+		return nil, false
+	}
+
+	return member, true
+}
+
+// respondOrganizationError maps organization service errors to responses
+func respondOrganizationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrganizationMemberNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrNotOrganizationMember), errors.Is(err, service.ErrOrganizationRoleTooLow):
+		response.Forbidden(c, err.Error())
+	default:
+		response.BadRequest(c, err.Error())
+	}
+}
+
+func memberUserIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return 0, false
+	}
+	return uint(id), true
+}
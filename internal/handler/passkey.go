@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasskeyHandler struct {
+	passkeyService *service.PasskeyService
+	authService    *service.AuthService
+}
+
+func NewPasskeyHandler(passkeyService *service.PasskeyService, authService *service.AuthService) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: passkeyService,
+		authService:    authService,
+	}
+}
+
+// BeginRegistration godoc
+// @Summary [User] Begin passkey registration
+// @Description Start a WebAuthn registration ceremony. Pass options to navigator.credentials.create() and send the result to the finish endpoint together with ceremony_id within 5 minutes.
+// @Tags user-passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=service.PasskeyCeremonyResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /user/passkeys/register/begin [post]
+func (h *PasskeyHandler) BeginRegistration(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	ceremony, err := h.passkeyService.BeginRegistration(c.Request.Context(), user)
+	if err != nil {
+		logger.Error("Failed to begin passkey registration",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to begin passkey registration")
+		return
+	}
+
+	response.Success(c, ceremony)
+}
+
+// FinishRegistration godoc
+// @Summary [User] Finish passkey registration
+// @Description Verify the authenticator response and store the new passkey
+// @Tags user-passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.PasskeyRegisterFinishRequest true "Ceremony ID, optional name and the PublicKeyCredential"
+// @Success 201 {object} response.StandardResponse{data=model.WebAuthnCredentialResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /user/passkeys/register/finish [post]
+func (h *PasskeyHandler) FinishRegistration(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req service.PasskeyRegisterFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	credential, err := h.passkeyService.FinishRegistration(c.Request.Context(), user, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.CreatedWithMessage(c, "Passkey registered successfully", credential.ToResponse())
+}
+
+// ListPasskeys godoc
+// @Summary [User] List passkeys
+// @Description List the passkeys registered on the current user's account
+// @Tags user-passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=[]model.WebAuthnCredentialResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /user/passkeys [get]
+func (h *PasskeyHandler) ListPasskeys(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	credentials, err := h.passkeyService.ListCredentials(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Error("Failed to list passkeys",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to list passkeys")
+		return
+	}
+
+	responseData := make([]*model.WebAuthnCredentialResponse, 0, len(credentials))
+	for _, credential := range credentials {
+		responseData = append(responseData, credential.ToResponse())
+	}
+
+	response.Success(c, responseData)
+}
+
+// DeletePasskey godoc
+// @Summary [User] Delete passkey
+// @Description Remove a passkey from the current user's account. The last remaining login method of an account cannot be removed.
+// @Tags user-passkeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Passkey ID"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /user/passkeys/{id} [delete]
+func (h *PasskeyHandler) DeletePasskey(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid passkey ID")
+		return
+	}
+
+	if err := h.passkeyService.DeleteCredential(c.Request.Context(), user.ID, uint(id)); err != nil {
+		if errors.Is(err, service.ErrLastLoginMethod) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Passkey deleted successfully", nil)
+}
+
+// BeginLogin godoc
+// @Summary Begin passkey login
+// @Description Start a passwordless WebAuthn login ceremony. Pass options to navigator.credentials.get() and send the result to the finish endpoint together with ceremony_id within 5 minutes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.StandardResponse{data=service.PasskeyCeremonyResponse}
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /auth/passkey/login/begin [post]
+func (h *PasskeyHandler) BeginLogin(c *gin.Context) {
+	ceremony, err := h.passkeyService.BeginLogin(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to begin passkey login",
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to begin passkey login")
+		return
+	}
+
+	response.Success(c, ceremony)
+}
+
+// FinishLogin godoc
+// @Summary Finish passkey login
+// @Description Verify the passkey assertion and log in. Returns the same payload as /auth/login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.PasskeyLoginFinishRequest true "Ceremony ID and the PublicKeyCredential"
+// @Success 200 {object} response.StandardResponse{data=service.AuthResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /auth/passkey/login/finish [post]
+func (h *PasskeyHandler) FinishLogin(c *gin.Context) {
+	var req service.PasskeyLoginFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	authResponse, err := h.authService.LoginWithPasskey(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	response.Success(c, authResponse)
+}
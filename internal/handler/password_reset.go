@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"linke/internal/logger"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetHandler struct {
+	passwordResetService *service.PasswordResetService
+}
+
+func NewPasswordResetHandler(passwordResetService *service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		passwordResetService: passwordResetService,
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Request password reset
+// @Description Email a single-use password reset link to a local account. Always succeeds so it cannot be used to discover registered addresses; at most one email is sent per minute.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.ForgotPasswordRequest true "Email address"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /auth/forgot-password [post]
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req service.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.passwordResetService.ForgotPassword(c.Request.Context(), req.Email, clientInfo(c)); err != nil {
+		logger.Error("Failed to process forgot password request",
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to send password reset email")
+		return
+	}
+
+	response.SuccessWithMessage(c, "If an account with that email exists, a password reset link has been sent", nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using the token from the reset email. All existing sessions, access tokens and refresh tokens of the user are revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body service.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Router /auth/reset-password [post]
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Password reset successfully, please log in with your new password", nil)
+}
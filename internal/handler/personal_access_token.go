@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"strconv"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PersonalAccessTokenHandler struct {
+	tokenService *service.PersonalAccessTokenService
+}
+
+func NewPersonalAccessTokenHandler(tokenService *service.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// CreateToken godoc
+// @Summary [User] Create personal access token
+// @Description Create an API key for scripts and other machine clients. The token is only returned in this response; send it as "Authorization: Bearer lk_..." or "X-API-Key: lk_...". Available scopes: tasks:read, tasks:write, invite_codes:read, invite_codes:write.
+// @Tags user-tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreatePersonalAccessTokenRequest true "Name, scopes and optional lifetime in days"
+// @Success 201 {object} response.StandardResponse{data=model.PersonalAccessTokenResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Router /user/tokens [post]
+func (h *PersonalAccessTokenHandler) CreateToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req service.CreatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	raw, token, err := h.tokenService.Create(c.Request.Context(), user.ID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	tokenResponse := token.ToResponse()
+	tokenResponse.Token = raw
+	response.CreatedWithMessage(c, "Token created successfully, copy it now as it will not be shown again", tokenResponse)
+}
+
+// ListTokens godoc
+// @Summary [User] List personal access tokens
+// @Description List the current user's personal access tokens. Token values are never returned.
+// @Tags user-tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=[]model.PersonalAccessTokenResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /user/tokens [get]
+func (h *PersonalAccessTokenHandler) ListTokens(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.tokenService.List(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Error("Failed to list personal access tokens",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to list tokens")
+		return
+	}
+
+	responseData := make([]*model.PersonalAccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		responseData = append(responseData, token.ToResponse())
+	}
+
+	response.Success(c, responseData)
+}
+
+// GetToken godoc
+// @Summary [User] Get personal access token
+// @Description Get one of the current user's personal access tokens
+// @Tags user-tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Token ID"
+// @Success 200 {object} response.StandardResponse{data=model.PersonalAccessTokenResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /user/tokens/{id} [get]
+func (h *PersonalAccessTokenHandler) GetToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := tokenIDParam(c)
+	if !ok {
+		return
+	}
+
+	token, err := h.tokenService.Get(c.Request.Context(), user.ID, id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, token.ToResponse())
+}
+
+// UpdateToken godoc
+// @Summary [User] Update personal access token
+// @Description Rename one of the current user's personal access tokens and/or replace its scopes
+// @Tags user-tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Token ID"
+// @Param request body service.UpdatePersonalAccessTokenRequest true "New name and/or scopes"
+// @Success 200 {object} response.StandardResponse{data=model.PersonalAccessTokenResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /user/tokens/{id} [put]
+func (h *PersonalAccessTokenHandler) UpdateToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := tokenIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	token, err := h.tokenService.Get(c.Request.Context(), user.ID, id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	token, err = h.tokenService.Update(c.Request.Context(), token, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Token updated successfully", token.ToResponse())
+}
+
+// DeleteToken godoc
+// @Summary [User] Delete personal access token
+// @Description Revoke one of the current user's personal access tokens immediately
+// @Tags user-tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Token ID"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /user/tokens/{id} [delete]
+func (h *PersonalAccessTokenHandler) DeleteToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := tokenIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.tokenService.Delete(c.Request.Context(), user.ID, id); err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.SuccessWithMessage(c, "Token deleted successfully", nil)
+}
+
+func tokenIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid token ID")
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+func NewRoleHandler(roleService *service.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// ListPermissions godoc
+// @Summary [Admin] List permissions
+// @Description List every permission that can be granted to a role (requires roles:read)
+// @Tags admin-roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=[]string}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Router /admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	response.Success(c, model.AllPermissions)
+}
+
+// ListRoles godoc
+// @Summary [Admin] List roles
+// @Description List all roles and their permissions (requires roles:read)
+// @Tags admin-roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=[]model.RoleResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to list roles", logger.Error2("error", err))
+		response.InternalServerError(c, "Failed to list roles")
+		return
+	}
+
+	responseData := make([]*model.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responseData = append(responseData, role.ToResponse())
+	}
+
+	response.Success(c, responseData)
+}
+
+// GetRole godoc
+// @Summary [Admin] Get role
+// @Description Get a role and its permissions (requires roles:read)
+// @Tags admin-roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} response.StandardResponse{data=model.RoleResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /admin/roles/{id} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	id, ok := roleIDParam(c)
+	if !ok {
+		return
+	}
+
+	role, err := h.roleService.GetRole(c.Request.Context(), id)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, role.ToResponse())
+}
+
+// CreateRole godoc
+// @Summary [Admin] Create role
+// @Description Create a custom role (requires roles:manage). Only permissions the caller holds can be granted.
+// @Tags admin-roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body service.CreateRoleRequest true "Role name, description and permissions"
+// @Success 201 {object} response.StandardResponse{data=model.RoleResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 409 {object} response.ConflictResponse
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req service.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c.Request.Context(), actor, &req)
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	response.CreatedWithMessage(c, "Role created successfully", role.ToResponse())
+}
+
+// UpdateRole godoc
+// @Summary [Admin] Update role
+// @Description Change a role's description and/or replace its permissions (requires roles:manage). The caller must hold every permission of the role before and after the change. The permissions of the admin role cannot be changed.
+// @Tags admin-roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Param request body service.UpdateRoleRequest true "New description and/or permissions"
+// @Success 200 {object} response.StandardResponse{data=model.RoleResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /admin/roles/{id} [put]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := roleIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req service.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(c.Request.Context(), actor, id, &req)
+	if err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Role updated successfully", role.ToResponse())
+}
+
+// DeleteRole godoc
+// @Summary [Admin] Delete role
+// @Description Delete a custom role (requires roles:manage). Built-in roles and roles that are still assigned to users cannot be deleted.
+// @Tags admin-roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Role ID"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Failure 409 {object} response.ConflictResponse
+// @Router /admin/roles/{id} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, ok := roleIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.roleService.DeleteRole(c.Request.Context(), actor, id); err != nil {
+		respondRoleError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Role deleted successfully", nil)
+}
+
+// GetMyPermissions godoc
+// @Summary [User] Get my permissions
+// @Description Get the current user's role and the permissions it grants
+// @Tags user-profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=service.UserPermissionsResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /user/permissions [get]
+func (h *RoleHandler) GetMyPermissions(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	permissions, err := h.roleService.GetUserPermissions(c.Request.Context(), user)
+	if err != nil {
+		logger.Error("Failed to get user permissions",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to get permissions")
+		return
+	}
+
+	response.Success(c, permissions)
+}
+
+// respondRoleError maps role service errors to responses
+func respondRoleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRoleNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrPermissionNotHeld):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, service.ErrRoleExists), errors.Is(err, service.ErrRoleInUse):
+		response.Conflict(c, err.Error())
+	default:
+		response.BadRequest(c, err.Error())
+	}
+}
+
+func roleIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid role ID")
+		return 0, false
+	}
+	return uint(id), true
+}
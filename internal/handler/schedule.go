@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+
+	"linke/internal/logger"
+	"linke/internal/queue"
+	"linke/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduleHandler struct {
+	scheduler *queue.Scheduler
+}
+
+func NewScheduleHandler(scheduler *queue.Scheduler) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduler: scheduler,
+	}
+}
+
+// ListSchedules godoc
+// @Summary [Admin] List schedules
+// @Description List the recurring task schedules with their next and last run (requires schedules:read)
+// @Tags admin-schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=[]queue.ScheduleStatus}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/schedules [get]
+func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.scheduler.List(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to list schedules", logger.Error2("error", err))
+		response.InternalServerError(c, "Failed to list schedules")
+		return
+	}
+
+	response.Success(c, schedules)
+}
+
+// PauseSchedule godoc
+// @Summary [Admin] Pause schedule
+// @Description Stop a recurring task schedule from firing on every server instance until it is resumed (requires schedules:manage)
+// @Tags admin-schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Schedule name"
+// @Success 200 {object} response.StandardResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/schedules/{name}/pause [post]
+func (h *ScheduleHandler) PauseSchedule(c *gin.Context) {
+	h.setPaused(c, true)
+}
+
+// ResumeSchedule godoc
+// @Summary [Admin] Resume schedule
+// @Description Let a paused recurring task schedule fire again from its next run (requires schedules:manage)
+// @Tags admin-schedules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Schedule name"
+// @Success 200 {object} response.StandardResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/schedules/{name}/resume [post]
+func (h *ScheduleHandler) ResumeSchedule(c *gin.Context) {
+	h.setPaused(c, false)
+}
+
+func (h *ScheduleHandler) setPaused(c *gin.Context, paused bool) {
+	actor, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	name := c.Param("name")
+	var err error
+	if paused {
+		err = h.scheduler.Pause(c.Request.Context(), name)
+	} else {
+		err = h.scheduler.Resume(c.Request.Context(), name)
+	}
+	if err != nil {
+		if errors.Is(err, queue.ErrScheduleNotFound) {
+			response.NotFound(c, "Schedule not found")
+			return
+		}
+		logger.Error("Failed to update schedule",
+			logger.String("schedule", name),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to update schedule")
+		return
+	}
+
+	logger.Info("Schedule updated by admin",
+		logger.Uint("actor_id", actor.ID),
+		logger.String("schedule", name),
+		logger.Any("paused", paused),
+	)
+	response.Success(c, gin.H{
+		"name":   name,
+		"paused": paused,
+	})
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"strconv"
+
+	"linke/internal/logger"
+	"linke/internal/middleware"
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionService *service.SessionService
+}
+
+func NewSessionHandler(sessionService *service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// ListMySessions godoc
+// @Summary [User] List active sessions
+// @Description List the current user's active sessions (devices). The session making the request is marked as current.
+// @Tags user-sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.StandardResponse{data=[]model.SessionResponse}
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /user/sessions [get]
+func (h *SessionHandler) ListMySessions(c *gin.Context) {
+	userValue, exists := c.Get(middleware.AuthContextKey)
+	if !exists {
+		response.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	user, ok := userValue.(*model.User)
+	if !ok {
+		response.Unauthorized(c, "Invalid user context")
+		return
+	}
+
+	sessions, err := h.sessionService.ListActiveSessions(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.Error("Failed to list sessions",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to list sessions")
+		return
+	}
+
+	var currentSessionID uint
+	if claimsValue, exists := c.Get(middleware.ClaimsContextKey); exists {
+		if claims, ok := claimsValue.(*service.Claims); ok {
+			currentSessionID = claims.SessionID
+		}
+	}
+
+	responseData := make([]*model.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp := session.ToResponse()
+		resp.Current = session.ID == currentSessionID
+		responseData = append(responseData, resp)
+	}
+
+	response.Success(c, responseData)
+}
+
+// RevokeMySession godoc
+// @Summary [User] Revoke a session
+// @Description Revoke one of the current user's sessions, logging that device out
+// @Tags user-sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /user/sessions/{id} [delete]
+func (h *SessionHandler) RevokeMySession(c *gin.Context) {
+	userValue, exists := c.Get(middleware.AuthContextKey)
+	if !exists {
+		response.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	user, ok := userValue.(*model.User)
+	if !ok {
+		response.Unauthorized(c, "Invalid user context")
+		return
+	}
+
+	idStr := c.Param("id")
+	sessionID, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID")
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), user.ID, uint(sessionID)); err != nil {
+		logger.Warn("Failed to revoke session",
+			logger.Uint("user_id", user.ID),
+			logger.Uint("session_id", uint(sessionID)),
+			logger.Error2("error", err),
+		)
+		response.NotFound(c, "Session not found")
+		return
+	}
+
+	response.SuccessWithMessage(c, "Session revoked successfully", nil)
+}
+
+// ListUserSessions godoc
+// @Summary [Admin] List user sessions
+// @Description List active sessions of any user (admin only)
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.StandardResponse{data=[]model.SessionResponse}
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 500 {object} response.InternalServerErrorResponse
+// @Router /admin/users/{id}/sessions [get]
+func (h *SessionHandler) ListUserSessions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	sessions, err := h.sessionService.ListActiveSessions(c.Request.Context(), uint(id))
+	if err != nil {
+		logger.Error("Admin failed to list user sessions",
+			logger.Uint("user_id", uint(id)),
+			logger.Error2("error", err),
+		)
+		response.InternalServerError(c, "Failed to list sessions")
+		return
+	}
+
+	responseData := make([]*model.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responseData = append(responseData, session.ToResponse())
+	}
+
+	response.Success(c, responseData)
+}
+
+// RevokeUserSession godoc
+// @Summary [Admin] Revoke user session
+// @Description Revoke a session of any user (admin only)
+// @Tags admin-users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param session_id path int true "Session ID"
+// @Success 200 {object} response.MessageOnlyResponse
+// @Failure 400 {object} response.BadRequestResponse
+// @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
+// @Failure 404 {object} response.NotFoundResponse
+// @Router /admin/users/{id}/sessions/{session_id} [delete]
+func (h *SessionHandler) RevokeUserSession(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	sessionIDStr := c.Param("session_id")
+	sessionID, err := strconv.ParseUint(sessionIDStr, 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID")
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), uint(id), uint(sessionID)); err != nil {
+		logger.Error("Admin failed to revoke user session",
+			logger.Uint("user_id", uint(id)),
+			logger.Uint("session_id", uint(sessionID)),
+			logger.Error2("error", err),
+		)
+		response.NotFound(c, "Session not found")
+		return
+	}
+
+	response.SuccessWithMessage(c, "Session revoked successfully", nil)
+}
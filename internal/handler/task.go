@@ -4,38 +4,52 @@ import (
 	"fmt"
 	"time"
 
+	"linke/internal/logger"
+	"linke/internal/model"
 	"linke/internal/queue"
 	"linke/internal/response"
+	"linke/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxTaskDelay is how far ahead a task can be scheduled
+const maxTaskDelay = 30 * 24 * time.Hour
+
 type TaskHandler struct {
-	taskQueue *queue.TaskQueue
+	taskQueue   *queue.TaskQueue
+	roleService *service.RoleService
 }
 
-func NewTaskHandler(taskQueue *queue.TaskQueue) *TaskHandler {
+func NewTaskHandler(taskQueue *queue.TaskQueue, roleService *service.RoleService) *TaskHandler {
 	return &TaskHandler{
-		taskQueue: taskQueue,
+		taskQueue:   taskQueue,
+		roleService: roleService,
 	}
 }
 
 // @Summary Create a new task
-// @Description Create and enqueue a new task
+// @Description Create and enqueue a new task. Set run_at (RFC 3339) or delay_seconds to run it later instead of right away.
+// @Description The task goes to the queue its type is routed to; set queue to use another processed queue of the same or lower priority, or a higher one with the tasks:prioritize permission.
 // @Tags tasks
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param task body object true "Task details"
 // @Success 201 {object} response.StandardResponse
 // @Failure 400 {object} response.BadRequestResponse
 // @Failure 401 {object} response.UnauthorizedResponse
+// @Failure 403 {object} response.ForbiddenResponse
 // @Failure 500 {object} response.InternalServerErrorResponse
 // @Router /tasks [post]
 func (h *TaskHandler) CreateTask(c *gin.Context) {
 	var req struct {
-		Type    string                 `json:"type" binding:"required"`
-		Payload map[string]interface{} `json:"payload" binding:"required"`
+		Type         string                 `json:"type" binding:"required"`
+		Payload      map[string]interface{} `json:"payload" binding:"required"`
+		RunAt        *time.Time             `json:"run_at"`
+		DelaySeconds int                    `json:"delay_seconds" binding:"omitempty,min=0"`
+		Queue        string                 `json:"queue"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -51,40 +65,141 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		MaxRetry: 3,
 	}
 
-	if err := h.taskQueue.Enqueue(c.Request.Context(), "default", task); err != nil {
+	if req.RunAt != nil && req.DelaySeconds > 0 {
+		response.BadRequest(c, "Use either run_at or delay_seconds, not both")
+		return
+	}
+	if time.Duration(req.DelaySeconds) > maxTaskDelay/time.Second {
+		response.BadRequest(c, "Tasks can be scheduled at most 30 days ahead")
+		return
+	}
+
+	runAt := time.Now()
+	if req.RunAt != nil {
+		runAt = *req.RunAt
+	} else if req.DelaySeconds > 0 {
+		runAt = runAt.Add(time.Duration(req.DelaySeconds) * time.Second)
+	}
+	if time.Until(runAt) > maxTaskDelay {
+		response.BadRequest(c, "Tasks can be scheduled at most 30 days ahead")
+		return
+	}
+
+	queueName, ok := h.taskQueueName(c, req.Type, req.Queue)
+	if !ok {
+		return
+	}
+
+	if err := h.taskQueue.EnqueueAt(c.Request.Context(), queueName, task, runAt); err != nil {
 		response.InternalServerError(c, "Failed to enqueue task")
 		return
 	}
 
 	response.CreatedWithMessage(c, "Task enqueued successfully", gin.H{
 		"task_id": task.ID,
+		"queue":   queueName,
+		"run_at":  runAt,
 	})
 }
 
+// taskQueueName returns the queue a new task of taskType is enqueued into: the queue the type is
+// routed to, or the requested one if the caller may use it. Responds with 400 or 403 otherwise.
+func (h *TaskHandler) taskQueueName(c *gin.Context, taskType, requested string) (string, bool) {
+	routed := h.taskQueue.QueueFor(taskType)
+	if requested == "" || requested == routed {
+		return routed, true
+	}
+
+	priority, ok := h.taskQueue.Priority(requested)
+	if !ok {
+		response.BadRequest(c, "Unknown queue")
+		return "", false
+	}
+
+	// Moving a task ahead of its routed queue needs permission; lower priority queues are always allowed
+	routedPriority, _ := h.taskQueue.Priority(routed)
+	if priority > routedPriority {
+		user, ok := currentUser(c)
+		if !ok {
+			return "", false
+		}
+		allowed, err := h.roleService.HasPermission(c.Request.Context(), user, model.PermissionTasksPrioritize)
+		if err != nil {
+			logger.Error("Failed to check permission",
+				logger.Uint("user_id", user.ID),
+				logger.String("permission", model.PermissionTasksPrioritize),
+				logger.Error2("error", err),
+			)
+			response.InternalServerError(c, "Failed to check permissions")
+			return "", false
+		}
+		if !allowed {
+			response.Forbidden(c, "Using a higher priority queue requires the tasks:prioritize permission")
+			return "", false
+		}
+	}
+
+	return requested, true
+}
+
 // @Summary Get queue status
-// @Description Get the current status of the task queue
+// @Description Get the current status of the task queues, totalled and per queue in priority order
 // @Tags tasks
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Success 200 {object} response.StandardResponse
 // @Failure 401 {object} response.UnauthorizedResponse
 // @Failure 500 {object} response.InternalServerErrorResponse
 // @Router /tasks/status [get]
 func (h *TaskHandler) GetQueueStatus(c *gin.Context) {
-	length, err := h.taskQueue.GetQueueLength(c.Request.Context(), "default")
-	if err != nil {
-		response.InternalServerError(c, "Failed to get queue length")
-		return
-	}
+	var length, processingLength, scheduledLength, deadLength int64
+	queues := make([]gin.H, 0, len(h.taskQueue.Queues()))
+	for _, queueName := range h.taskQueue.Queues() {
+		queueLength, err := h.taskQueue.GetQueueLength(c.Request.Context(), queueName)
+		if err != nil {
+			response.InternalServerError(c, "Failed to get queue length")
+			return
+		}
 
-	deadLength, err := h.taskQueue.GetQueueLength(c.Request.Context(), "default_dead")
-	if err != nil {
-		response.InternalServerError(c, "Failed to get dead queue length")
-		return
+		queueProcessingLength, err := h.taskQueue.GetProcessingLength(c.Request.Context(), queueName)
+		if err != nil {
+			response.InternalServerError(c, "Failed to get processing queue length")
+			return
+		}
+
+		queueScheduledLength, err := h.taskQueue.GetScheduledLength(c.Request.Context(), queueName)
+		if err != nil {
+			response.InternalServerError(c, "Failed to get scheduled queue length")
+			return
+		}
+
+		queueDeadLength, err := h.taskQueue.GetQueueLength(c.Request.Context(), queueName+"_dead")
+		if err != nil {
+			response.InternalServerError(c, "Failed to get dead queue length")
+			return
+		}
+
+		priority, _ := h.taskQueue.Priority(queueName)
+		queues = append(queues, gin.H{
+			"name":                    queueName,
+			"priority":                priority,
+			"queue_length":            queueLength,
+			"processing_queue_length": queueProcessingLength,
+			"scheduled_queue_length":  queueScheduledLength,
+			"dead_queue_length":       queueDeadLength,
+		})
+		length += queueLength
+		processingLength += queueProcessingLength
+		scheduledLength += queueScheduledLength
+		deadLength += queueDeadLength
 	}
 
 	response.Success(c, gin.H{
-		"queue_length":      length,
-		"dead_queue_length": deadLength,
+		"queue_length":            length,
+		"processing_queue_length": processingLength,
+		"scheduled_queue_length":  scheduledLength,
+		"dead_queue_length":       deadLength,
+		"queues":                  queues,
 	})
 }
\ No newline at end of file
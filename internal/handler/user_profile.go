@@ -12,11 +12,13 @@ import (
 
 type UserProfileHandler struct {
 	userService *service.UserService
+	authService *service.AuthService
 }
 
-func NewUserProfileHandler(userService *service.UserService) *UserProfileHandler {
+func NewUserProfileHandler(userService *service.UserService, authService *service.AuthService) *UserProfileHandler {
 	return &UserProfileHandler{
 		userService: userService,
+		authService: authService,
 	}
 }
 
@@ -174,20 +176,15 @@ func (h *UserProfileHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	// Validate password length
-	if len(req.NewPassword) < 6 {
-		response.BadRequest(c, "New password must be at least 6 characters")
+	if err := h.authService.ChangePassword(c.Request.Context(), currentUser.ID, req.OldPassword, req.NewPassword); err != nil {
+		logger.Error("Password change failed",
+			logger.Uint("user_id", currentUser.ID),
+			logger.Error2("error", err),
+		)
+		response.BadRequest(c, err.Error())
 		return
 	}
 
-	// Here you would implement password change logic
-	// For now, we'll just return success
-	// TODO: Implement actual password change with verification
-	
-	logger.Info("Password changed successfully",
-		logger.Uint("user_id", currentUser.ID),
-	)
-
 	response.SuccessWithMessage(c, "Password changed successfully", nil)
 }
 
@@ -202,5 +199,5 @@ type UserProfileUpdateRequest struct {
 // ChangePasswordRequest represents the structure for password change
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required"` // Checked against the password policy
 }
\ No newline at end of file
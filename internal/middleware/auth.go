@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"linke/internal/logger"
+	"linke/internal/model"
 	"linke/internal/response"
 	"linke/internal/service"
 
@@ -11,12 +12,22 @@ import (
 )
 
 const (
-	AuthContextKey = "auth_user"
+	AuthContextKey     = "auth_user"
+	ClaimsContextKey   = "auth_claims"
+	APITokenContextKey = "auth_api_token"
 )
 
-// AuthMiddleware creates a middleware for JWT authentication
-func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
+// AuthMiddleware creates a middleware for JWT and personal access token authentication.
+// Personal access tokens, sent as "Authorization: Bearer lk_..." or "X-API-Key: lk_...",
+// are only accepted when scopes are given and the token has been granted all of them.
+// Requests authenticated with a JWT are not limited by scopes.
+func AuthMiddleware(authService *service.AuthService, scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			authenticateAPIToken(c, authService, apiKey, scopes)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			logger.Warn("Missing authorization header",
@@ -38,7 +49,12 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 		}
 
 		token := tokenParts[1]
-		user, err := authService.ValidateToken(token)
+		if strings.HasPrefix(token, model.PersonalAccessTokenPrefix) {
+			authenticateAPIToken(c, authService, token, scopes)
+			return
+		}
+
+		user, claims, err := authService.ValidateToken(token)
 		if err != nil {
 			logger.Warn("Invalid token",
 				logger.String("path", c.Request.URL.Path),
@@ -49,12 +65,53 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		// Store user in context for use in handlers
+		// Store user and token claims in context for use in handlers
 		c.Set(AuthContextKey, user)
+		c.Set(ClaimsContextKey, claims)
+		setAuditContext(c, user, claims)
 		c.Next()
 	}
 }
 
+// authenticateAPIToken authenticates the request with a personal access token that must have
+// been granted every one of scopes
+func authenticateAPIToken(c *gin.Context, authService *service.AuthService, rawToken string, scopes []string) {
+	user, token, err := authService.ValidateAPIToken(c.Request.Context(), rawToken, c.ClientIP())
+	if err != nil {
+		logger.Warn("Invalid API token",
+			logger.String("path", c.Request.URL.Path),
+			logger.Error2("error", err),
+		)
+		response.Unauthorized(c, "Invalid or expired API token")
+		c.Abort()
+		return
+	}
+
+	if len(scopes) == 0 {
+		response.Forbidden(c, "API tokens cannot be used for this endpoint")
+		c.Abort()
+		return
+	}
+	for _, scope := range scopes {
+		if !token.HasScope(scope) {
+			logger.Warn("API token without required scope",
+				logger.String("path", c.Request.URL.Path),
+				logger.Uint("token_id", token.ID),
+				logger.String("scope", scope),
+			)
+			response.Forbidden(c, "API token is missing the required scope: "+scope)
+			c.Abort()
+			return
+		}
+	}
+
+	// Store user and token in context for use in handlers
+	c.Set(AuthContextKey, user)
+	c.Set(APITokenContextKey, token)
+	setAuditContext(c, user, nil)
+	c.Next()
+}
+
 // OptionalAuthMiddleware creates a middleware that sets user context if token is present but doesn't require it
 func OptionalAuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -71,15 +128,27 @@ func OptionalAuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 		}
 
 		token := tokenParts[1]
-		user, err := authService.ValidateToken(token)
+		user, claims, err := authService.ValidateToken(token)
 		if err != nil {
 			// Don't fail the request, just continue without user context
 			c.Next()
 			return
 		}
 
-		// Store user in context for use in handlers
+		// Store user and token claims in context for use in handlers
 		c.Set(AuthContextKey, user)
+		c.Set(ClaimsContextKey, claims)
+		setAuditContext(c, user, claims)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// setAuditContext records the authenticated user, and the admin impersonating them if any,
+// in the request context for the audit log
+func setAuditContext(c *gin.Context, user *model.User, claims *service.Claims) {
+	ctx := service.WithAuditActor(c.Request.Context(), user.ID)
+	if claims != nil && claims.IsImpersonation() {
+		ctx = service.WithAuditImpersonator(ctx, claims.Act.UserID)
+	}
+	c.Request = c.Request.WithContext(ctx)
+}
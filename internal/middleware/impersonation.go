@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlockImpersonation is a middleware that rejects requests made with an impersonation token,
+// for actions an admin must not take on a user's behalf.
+// This middleware should be used after the authentication middleware
+func BlockImpersonation() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if claimsValue, exists := c.Get(ClaimsContextKey); exists {
+			if claims, ok := claimsValue.(*service.Claims); ok && claims.IsImpersonation() {
+				response.Forbidden(c, "This action is not available while impersonating a user")
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	})
+}
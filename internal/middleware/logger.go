@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"net/url"
+	"strings"
 	"time"
 
 	"linke/internal/logger"
@@ -8,6 +10,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// redactedQueryParams are query parameters carrying one-time secrets, such as email
+// verification tokens and OAuth authorization codes, which must not end up in logs
+var redactedQueryParams = map[string]bool{
+	"token": true,
+	"code":  true,
+	"state": true,
+}
+
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -21,9 +31,10 @@ func Logger() gin.HandlerFunc {
 		method := c.Request.Method
 		statusCode := c.Writer.Status()
 		userAgent := c.Request.UserAgent()
+		requestID := c.GetString(RequestIDContextKey)
 
 		if raw != "" {
-			path = path + "?" + raw
+			path = path + "?" + redactQuery(raw)
 		}
 
 		if statusCode >= 500 {
@@ -34,6 +45,7 @@ func Logger() gin.HandlerFunc {
 				logger.Int("status_code", statusCode),
 				logger.Duration("latency", latency),
 				logger.String("user_agent", userAgent),
+				logger.String("request_id", requestID),
 			)
 		} else if statusCode >= 400 {
 			logger.Warn("HTTP request completed",
@@ -43,6 +55,7 @@ func Logger() gin.HandlerFunc {
 				logger.Int("status_code", statusCode),
 				logger.Duration("latency", latency),
 				logger.String("user_agent", userAgent),
+				logger.String("request_id", requestID),
 			)
 		} else {
 			logger.Info("HTTP request completed",
@@ -52,7 +65,20 @@ func Logger() gin.HandlerFunc {
 				logger.Int("status_code", statusCode),
 				logger.Duration("latency", latency),
 				logger.String("user_agent", userAgent),
+				logger.String("request_id", requestID),
 			)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// redactQuery replaces the values of redactedQueryParams in a raw query string, keeping its order
+func redactQuery(raw string) string {
+	params := strings.Split(raw, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		if name, err := url.QueryUnescape(key); err == nil && redactedQueryParams[name] {
+			params[i] = key + "=REDACTED"
+		}
+	}
+	return strings.Join(params, "&")
+}
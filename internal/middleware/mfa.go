@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireMFAEnrollment is a middleware that blocks users who are required to enable
+// two-factor authentication but have not done so yet.
+// This middleware should be used after the authentication middleware
+func RequireMFAEnrollment(mfaService *service.MFAService) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		userValue, exists := c.Get(AuthContextKey)
+		if !exists {
+			response.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		user, ok := userValue.(*model.User)
+		if !ok {
+			response.Unauthorized(c, "Invalid user context")
+			c.Abort()
+			return
+		}
+
+		if mfaService.IsEnrollmentRequired(user) {
+			response.Forbidden(c, "Two-factor authentication must be enabled to access this resource")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"errors"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const OrganizationContextKey = "auth_organization_member"
+
+// RequireOrganization is a middleware that loads the user's membership in the active organization
+// carried in the access token and checks that their role in it is at least role.
+// This middleware should be used after the authentication middleware
+func RequireOrganization(organizationService *service.OrganizationService, role string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		userValue, exists := c.Get(AuthContextKey)
+		if !exists {
+			response.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		user, ok := userValue.(*model.User)
+		if !ok {
+			response.Unauthorized(c, "Invalid user context")
+			c.Abort()
+			return
+		}
+
+		// Personal access tokens have no session and therefore no active organization
+		var organizationID uint
+		if claimsValue, exists := c.Get(ClaimsContextKey); exists {
+			if claims, ok := claimsValue.(*service.Claims); ok {
+				organizationID = claims.OrgID
+			}
+		}
+		if organizationID == 0 {
+			response.Forbidden(c, "No active organization, switch to an organization first")
+			c.Abort()
+			return
+		}
+
+		member, err := organizationService.GetMembership(c.Request.Context(), organizationID, user.ID)
+		if err != nil {
+			if errors.Is(err, service.ErrNotOrganizationMember) {
+				response.Forbidden(c, err.Error())
+				c.Abort()
+				return
+			}
+			logger.Error("Failed to load organization membership",
+				logger.Uint("user_id", user.ID),
+				logger.Uint("organization_id", organizationID),
+				logger.Error2("error", err),
+			)
+			response.InternalServerError(c, "Failed to load organization")
+			c.Abort()
+			return
+		}
+
+		if !member.HasRole(role) {
+			response.Forbidden(c, "Organization "+role+" role required")
+			c.Abort()
+			return
+		}
+
+		c.Set(OrganizationContextKey, member)
+		c.Next()
+	})
+}
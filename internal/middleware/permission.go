@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"linke/internal/logger"
+	"linke/internal/model"
+	"linke/internal/response"
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission is a middleware that checks if the authenticated user's role grants permission.
+// This middleware should be used after the authentication middleware
+func RequirePermission(roleService *service.RoleService, permission string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		userValue, exists := c.Get(AuthContextKey)
+		if !exists {
+			response.Unauthorized(c, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		user, ok := userValue.(*model.User)
+		if !ok {
+			response.Unauthorized(c, "Invalid user context")
+			c.Abort()
+			return
+		}
+
+		allowed, err := roleService.HasPermission(c.Request.Context(), user, permission)
+		if err != nil {
+			logger.Error("Failed to check permission",
+				logger.Uint("user_id", user.ID),
+				logger.String("permission", permission),
+				logger.Error2("error", err),
+			)
+			response.InternalServerError(c, "Failed to check permissions")
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			logger.Warn("Permission denied",
+				logger.Uint("user_id", user.ID),
+				logger.String("role", user.Role),
+				logger.String("permission", permission),
+				logger.String("path", c.Request.URL.Path),
+			)
+			response.Forbidden(c, "Missing required permission: "+permission)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"linke/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDHeader     = "X-Request-ID"
+	RequestIDContextKey = "request_id"
+)
+
+// requestIDPattern limits which incoming request IDs are trusted, since they end up in logs and the audit log
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID is a middleware that assigns every request an ID, reusing a well-formed X-Request-ID
+// header from the client or proxy. The ID is echoed in the response header and attached, together
+// with the client IP and user agent, to the request context for the audit log.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set(RequestIDContextKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(service.WithAuditMetadata(c.Request.Context(), requestID, c.ClientIP(), c.Request.UserAgent()))
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -8,6 +8,7 @@ import (
 	"linke/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func Migrate(db *gorm.DB) error {
@@ -20,12 +21,22 @@ func Migrate(db *gorm.DB) error {
 
 	logger.Info("Starting database migration")
 
+	// Accounts that exist before email verification was added are treated as verified
+	backfillEmailVerified := !db.Migrator().HasColumn(&model.User{}, "email_verified_at")
+
 	// Migrate User model
 	if err := db.AutoMigrate(&model.User{}); err != nil {
 		logger.Error("Failed to migrate User model", logger.Error2("error", err))
 		return err
 	}
 
+	if backfillEmailVerified {
+		if err := backfillEmailVerifiedAt(db); err != nil {
+			logger.Error("Failed to backfill email verification", logger.Error2("error", err))
+			return err
+		}
+	}
+
 	// Migrate InviteCode model
 	if err := db.AutoMigrate(&model.InviteCode{}); err != nil {
 		logger.Error("Failed to migrate InviteCode model", logger.Error2("error", err))
@@ -38,6 +49,192 @@ func Migrate(db *gorm.DB) error {
 		return err
 	}
 
+	// Migrate Session model
+	if err := db.AutoMigrate(&model.Session{}); err != nil {
+		logger.Error("Failed to migrate Session model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate RefreshToken model
+	if err := db.AutoMigrate(&model.RefreshToken{}); err != nil {
+		logger.Error("Failed to migrate RefreshToken model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate RecoveryCode model
+	if err := db.AutoMigrate(&model.RecoveryCode{}); err != nil {
+		logger.Error("Failed to migrate RecoveryCode model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate WebAuthnCredential model
+	if err := db.AutoMigrate(&model.WebAuthnCredential{}); err != nil {
+		logger.Error("Failed to migrate WebAuthnCredential model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate PasswordResetToken model
+	if err := db.AutoMigrate(&model.PasswordResetToken{}); err != nil {
+		logger.Error("Failed to migrate PasswordResetToken model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate PasswordHistory model
+	if err := db.AutoMigrate(&model.PasswordHistory{}); err != nil {
+		logger.Error("Failed to migrate PasswordHistory model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate UserIdentity model
+	if err := db.AutoMigrate(&model.UserIdentity{}); err != nil {
+		logger.Error("Failed to migrate UserIdentity model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate PersonalAccessToken model
+	if err := db.AutoMigrate(&model.PersonalAccessToken{}); err != nil {
+		logger.Error("Failed to migrate PersonalAccessToken model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate Role model
+	if err := db.AutoMigrate(&model.Role{}); err != nil {
+		logger.Error("Failed to migrate Role model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate Organization model
+	if err := db.AutoMigrate(&model.Organization{}); err != nil {
+		logger.Error("Failed to migrate Organization model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate OrganizationMember model
+	if err := db.AutoMigrate(&model.OrganizationMember{}); err != nil {
+		logger.Error("Failed to migrate OrganizationMember model", logger.Error2("error", err))
+		return err
+	}
+
+	// Migrate AuditEvent model
+	if err := db.AutoMigrate(&model.AuditEvent{}); err != nil {
+		logger.Error("Failed to migrate AuditEvent model", logger.Error2("error", err))
+		return err
+	}
+
+	// Create the built-in roles that the user role field has always referred to
+	if err := seedBuiltInRoles(db); err != nil {
+		logger.Error("Failed to seed built-in roles", logger.Error2("error", err))
+		return err
+	}
+
+	// Move provider IDs from the legacy per-provider user columns into user_identities
+	if err := backfillUserIdentities(db); err != nil {
+		logger.Error("Failed to backfill user identities", logger.Error2("error", err))
+		return err
+	}
+
 	logger.Info("Database migration completed successfully")
 	return nil
-}
\ No newline at end of file
+}
+
+// backfillEmailVerifiedAt marks the users that existed before the email_verified_at column was
+// added as verified at their creation time, so requiring verified emails does not lock them out.
+// It only runs in the migration that adds the column; later sign-ups have to verify their email.
+func backfillEmailVerifiedAt(db *gorm.DB) error {
+	result := db.Unscoped().Model(&model.User{}).
+		Where("email_verified_at IS NULL").
+		UpdateColumn("email_verified_at", gorm.Expr("created_at"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		logger.Info("Marked existing users as email verified", logger.Int64("count", result.RowsAffected))
+	}
+	return nil
+}
+
+// backfillUserIdentities copies the google_id, github_id and telegram_id columns of users,
+// which are no longer part of the User model, into user_identities. Rows that already exist
+// are skipped, so it is safe to run on every start. The old columns are left in place.
+func backfillUserIdentities(db *gorm.DB) error {
+	legacyColumns := []struct {
+		column   string
+		provider string
+	}{
+		{"google_id", model.ProviderGoogle},
+		{"github_id", model.ProviderGitHub},
+		{"telegram_id", model.ProviderTelegram},
+	}
+
+	for _, legacy := range legacyColumns {
+		if !db.Migrator().HasColumn(&model.User{}, legacy.column) {
+			continue
+		}
+
+		var rows []struct {
+			ID      uint
+			Email   string
+			Subject string
+		}
+		column := clause.Column{Name: legacy.column}
+		if err := db.Table("users").
+			Select("id, email, ? AS subject", column).
+			Where("? IS NOT NULL AND ? <> ''", column, column).
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		identities := make([]model.UserIdentity, 0, len(rows))
+		for _, row := range rows {
+			identities = append(identities, model.UserIdentity{
+				UserID:   row.ID,
+				Provider: legacy.provider,
+				Subject:  row.Subject,
+				Email:    row.Email,
+			})
+		}
+
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(identities, 500)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			logger.Info("Backfilled user identities",
+				logger.String("provider", legacy.provider),
+				logger.Int64("count", result.RowsAffected),
+			)
+		}
+	}
+	return nil
+}
+
+// seedBuiltInRoles makes sure the user and admin roles exist. The admin role is reset to every
+// known permission on each run, so admins keep full access as permissions are added. The user
+// role is only created when it is missing, so changes made to it through the API are kept.
+func seedBuiltInRoles(db *gorm.DB) error {
+	admin := model.Role{
+		Name:        model.UserRoleAdmin,
+		Description: "Full access to all administration features",
+		Permissions: strings.Join(model.AllPermissions, ","),
+		BuiltIn:     true,
+	}
+	if err := db.Where(model.Role{Name: admin.Name}).
+		Assign(model.Role{Permissions: admin.Permissions, BuiltIn: true}).
+		FirstOrCreate(&admin).Error; err != nil {
+		return err
+	}
+
+	user := model.Role{
+		Name:        model.UserRoleUser,
+		Description: "Default role of registered users",
+		Permissions: strings.Join(model.DefaultUserPermissions, ","),
+		BuiltIn:     true,
+	}
+	if err := db.Where(model.Role{Name: user.Name}).FirstOrCreate(&user).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEvent is an append-only record of a security-relevant or administrative action.
+// Rows are never updated or deleted by the application.
+type AuditEvent struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	ActorID        *uint  `json:"actor_id" gorm:"index"`        // User who performed the action, nil for system actions
+	ImpersonatorID *uint  `json:"impersonator_id" gorm:"index"` // Admin impersonating the actor when the action was performed
+	Action         string `json:"action" gorm:"size:64;not null;index"`
+	TargetType     string `json:"target_type" gorm:"size:50;not null;index:idx_audit_events_target"`
+	TargetID       uint   `json:"target_id" gorm:"not null;index:idx_audit_events_target"`
+	Before         string `json:"-" gorm:"type:text"` // JSON object holding the changed fields before the action
+	After          string `json:"-" gorm:"type:text"` // JSON object holding the changed fields after the action
+
+	// Request Fields
+	IPAddress string `json:"ip_address" gorm:"size:45"`
+	UserAgent string `json:"user_agent" gorm:"size:255"`
+	RequestID string `json:"request_id" gorm:"size:64;index"`
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null;index"`
+}
+
+// Audit actions
+const (
+	AuditActionUserUpdate               = "user.update"
+	AuditActionUserStatusUpdate         = "user.status_update"
+	AuditActionUserDelete               = "user.delete"
+	AuditActionUserRestore              = "user.restore"
+	AuditActionUserHardDelete           = "user.hard_delete"
+	AuditActionUserRoleAssign           = "user.role_assign"
+	AuditActionUserPasswordChange       = "user.password_change"
+	AuditActionUserPasswordReset        = "user.password_reset"
+	AuditActionUserMFAEnable            = "user.mfa_enable"
+	AuditActionUserMFAReset             = "user.mfa_reset"
+	AuditActionUserMFADisable           = "user.mfa_disable"
+	AuditActionUserImpersonate          = "user.impersonate"
+	AuditActionUserLockoutClear         = "user.lockout_clear"
+	AuditActionUserLogoutAll            = "user.logout_all"
+	AuditActionIdentityLink             = "identity.link"
+	AuditActionIdentityUnlink           = "identity.unlink"
+	AuditActionPasskeyRegister          = "passkey.register"
+	AuditActionPasskeyDelete            = "passkey.delete"
+	AuditActionRoleCreate               = "role.create"
+	AuditActionRoleUpdate               = "role.update"
+	AuditActionRoleDelete               = "role.delete"
+	AuditActionInviteCodeStatusUpdate   = "invite_code.status_update"
+	AuditActionInviteCodeDelete         = "invite_code.delete"
+	AuditActionSessionRevoke            = "session.revoke"
+	AuditActionAccessTokenCreate        = "access_token.create"
+	AuditActionAccessTokenUpdate        = "access_token.update"
+	AuditActionAccessTokenDelete        = "access_token.delete"
+	AuditActionOrganizationDelete       = "organization.delete"
+	AuditActionOrganizationMemberJoin   = "organization.member_join"
+	AuditActionOrganizationMemberLeave  = "organization.member_leave"
+	AuditActionOrganizationMemberRole   = "organization.member_role_update"
+	AuditActionOrganizationMemberRemove = "organization.member_remove"
+	AuditActionOrganizationTransfer     = "organization.ownership_transfer"
+)
+
+// Audit target types
+const (
+	AuditTargetUser                = "user"
+	AuditTargetRole                = "role"
+	AuditTargetInviteCode          = "invite_code"
+	AuditTargetSession             = "session"
+	AuditTargetPersonalAccessToken = "personal_access_token"
+	AuditTargetOrganization        = "organization"
+	AuditTargetIdentity            = "user_identity"
+	AuditTargetPasskey             = "passkey"
+)
+
+// TableName returns the table name for AuditEvent model
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditEventResponse represents the audit event data structure for API responses
+type AuditEventResponse struct {
+	ID             uint            `json:"id" example:"1"`                                            // Event ID
+	ActorID        *uint           `json:"actor_id" example:"1"`                                      // User who performed the action
+	ImpersonatorID *uint           `json:"impersonator_id,omitempty" example:"1"`                     // Admin impersonating the actor, if any
+	Action         string          `json:"action" example:"user.status_update"`                       // Action performed
+	TargetType     string          `json:"target_type" example:"user"`                                // Type of the affected resource
+	TargetID       uint            `json:"target_id" example:"42"`                                    // ID of the affected resource
+	Before         json.RawMessage `json:"before,omitempty" swaggertype:"object"`                     // Changed fields before the action
+	After          json.RawMessage `json:"after,omitempty" swaggertype:"object"`                      // Changed fields after the action
+	IPAddress      string          `json:"ip_address" example:"192.168.1.1"`                          // Client IP address
+	UserAgent      string          `json:"user_agent" example:"Mozilla/5.0"`                          // Client user agent
+	RequestID      string          `json:"request_id" example:"6f1c3a0e-6c1b-4a8e-9a55-2f1a4e7c9b11"` // Request ID, matches the X-Request-ID header
+	CreatedAt      time.Time       `json:"created_at" example:"2024-01-01T00:00:00Z"`                 // Time of the action
+}
+
+// ToResponse converts AuditEvent to AuditEventResponse
+func (e *AuditEvent) ToResponse() *AuditEventResponse {
+	resp := &AuditEventResponse{
+		ID:             e.ID,
+		ActorID:        e.ActorID,
+		ImpersonatorID: e.ImpersonatorID,
+		Action:         e.Action,
+		TargetType:     e.TargetType,
+		TargetID:       e.TargetID,
+		IPAddress:      e.IPAddress,
+		UserAgent:      e.UserAgent,
+		RequestID:      e.RequestID,
+		CreatedAt:      e.CreatedAt,
+	}
+	if e.Before != "" {
+		resp.Before = json.RawMessage(e.Before)
+	}
+	if e.After != "" {
+		resp.After = json.RawMessage(e.After)
+	}
+	return resp
+}
@@ -14,6 +14,7 @@ type InviteCode struct {
 	// Core Fields
 	Code        string `json:"code" gorm:"uniqueIndex;size:32;not null"`        // 邀请码
 	CreatedByID uint   `json:"created_by_id" gorm:"not null;index"`             // 创建者ID
+	OrganizationID *uint `json:"organization_id,omitempty" gorm:"index"`       // 组织ID (可选)
 	
 	// Status and Limits
 	Status      string `json:"status" gorm:"size:20;not null;default:'active';index"` // active, used, disabled
@@ -81,6 +82,7 @@ type InviteCodeResponse struct {
 	ID          uint      `json:"id" example:"1"`                                        // Invite code ID
 	Code        string    `json:"code" example:"a1b2c3d4e5f6789012345678901234567890abcd"` // Invite code string
 	CreatedByID uint      `json:"created_by_id" example:"1"`                             // Creator user ID
+	OrganizationID *uint  `json:"organization_id,omitempty" example:"1"`                 // Organization the code invites to
 	Status      string    `json:"status" example:"active" enums:"active,used,disabled"`   // Invite code status
 	MaxUses     int       `json:"max_uses" example:"10"`                                 // Maximum number of uses
 	UsedCount   int       `json:"used_count" example:"0"`                                // Current usage count
@@ -96,15 +98,16 @@ type InviteCodeResponse struct {
 // ToResponse converts InviteCode to InviteCodeResponse
 func (ic *InviteCode) ToResponse() *InviteCodeResponse {
 	resp := &InviteCodeResponse{
-		ID:          ic.ID,
-		Code:        ic.Code,
-		CreatedByID: ic.CreatedByID,
-		Status:      ic.Status,
-		MaxUses:     ic.MaxUses,
-		UsedCount:   ic.UsedCount,
-		Description: ic.Description,
-		CreatedAt:   ic.CreatedAt,
-		UpdatedAt:   ic.UpdatedAt,
+		ID:             ic.ID,
+		Code:           ic.Code,
+		CreatedByID:    ic.CreatedByID,
+		OrganizationID: ic.OrganizationID,
+		Status:         ic.Status,
+		MaxUses:        ic.MaxUses,
+		UsedCount:      ic.UsedCount,
+		Description:    ic.Description,
+		CreatedAt:      ic.CreatedAt,
+		UpdatedAt:      ic.UpdatedAt,
 	}
 	
 	// Include related data if loaded
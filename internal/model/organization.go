@@ -0,0 +1,128 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Organization is a team of users. Members have a role within the organization that is
+// independent of their global role.
+type Organization struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	Name        string `json:"name" gorm:"size:100;not null"`
+	Description string `json:"description" gorm:"size:255"`
+	OwnerID     uint   `json:"owner_id" gorm:"not null;index"`
+
+	// Timestamp Fields
+	CreatedAt time.Time      `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"not null"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// OrganizationMember is the membership of a user in an organization
+type OrganizationMember struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	OrganizationID uint   `json:"organization_id" gorm:"not null;uniqueIndex:idx_organization_members_org_user"`
+	UserID         uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_organization_members_org_user;index"`
+	Role           string `json:"role" gorm:"size:20;not null;default:'member'"` // owner, admin, member
+
+	// Relationships (no foreign key constraints for performance)
+	Organization *Organization `json:"organization,omitempty" gorm:"-"`
+	User         *User         `json:"user,omitempty" gorm:"-"`
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// Organization role constants
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// TableName returns the table name for Organization model
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// TableName returns the table name for OrganizationMember model
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// OrganizationRoleRank orders organization roles from least (1) to most (3) privileged.
+// Unknown roles rank 0.
+func OrganizationRoleRank(role string) int {
+	switch role {
+	case OrganizationRoleOwner:
+		return 3
+	case OrganizationRoleAdmin:
+		return 2
+	case OrganizationRoleMember:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// HasRole checks if the member's role is role or a more privileged one
+func (m *OrganizationMember) HasRole(role string) bool {
+	return OrganizationRoleRank(m.Role) >= OrganizationRoleRank(role)
+}
+
+// OrganizationResponse represents the organization data structure for API responses
+type OrganizationResponse struct {
+	ID          uint      `json:"id" example:"1"`                            // Organization ID
+	Name        string    `json:"name" example:"Acme"`                       // Name
+	Description string    `json:"description" example:"The Acme team"`       // Description
+	OwnerID     uint      `json:"owner_id" example:"1"`                      // User ID of the owner
+	Role        string    `json:"role,omitempty" example:"owner"`            // Role of the current user in the organization
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"` // Creation time
+}
+
+// ToResponse converts Organization to OrganizationResponse
+func (o *Organization) ToResponse() *OrganizationResponse {
+	return &OrganizationResponse{
+		ID:          o.ID,
+		Name:        o.Name,
+		Description: o.Description,
+		OwnerID:     o.OwnerID,
+		CreatedAt:   o.CreatedAt,
+	}
+}
+
+// OrganizationMemberResponse represents the organization member data structure for API responses
+type OrganizationMemberResponse struct {
+	UserID   uint      `json:"user_id" example:"2"`                      // Member user ID
+	Username string    `json:"username,omitempty" example:"jane"`        // Member username
+	Name     string    `json:"name,omitempty" example:"Jane"`            // Member display name
+	Email    string    `json:"email,omitempty" example:"jane@acme.com"`  // Member email
+	Avatar   string    `json:"avatar,omitempty"`                         // Member avatar URL
+	Role     string    `json:"role" example:"member"`                    // Role in the organization
+	JoinedAt time.Time `json:"joined_at" example:"2024-01-01T00:00:00Z"` // Time the user joined
+}
+
+// ToResponse converts OrganizationMember to OrganizationMemberResponse
+func (m *OrganizationMember) ToResponse() *OrganizationMemberResponse {
+	resp := &OrganizationMemberResponse{
+		UserID:   m.UserID,
+		Role:     m.Role,
+		JoinedAt: m.CreatedAt,
+	}
+	if m.User != nil {
+		resp.Username = m.User.Username
+		resp.Name = m.User.Name
+		resp.Email = m.User.Email
+		resp.Avatar = m.User.Avatar
+	}
+	return resp
+}
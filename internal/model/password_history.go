@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// PasswordHistory stores the bcrypt hash of a password a user had before,
+// so that recently used passwords cannot be set again
+type PasswordHistory struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	UserID       uint   `json:"user_id" gorm:"not null;index"`
+	PasswordHash string `json:"-" gorm:"size:255;not null"`
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null;index"`
+}
+
+// TableName returns the table name for PasswordHistory model
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
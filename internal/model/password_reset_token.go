@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+)
+
+// PasswordResetToken is a single-use token emailed to a user who forgot their password.
+// Only the SHA-256 hash of the token is stored; the raw value only appears in the email.
+type PasswordResetToken struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	UserID    uint   `json:"user_id" gorm:"not null;index"`
+	TokenHash string `json:"-" gorm:"uniqueIndex;size:64;not null"` // SHA-256 hex of the raw token
+
+	// Lifecycle
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+
+	// Request Information
+	IPAddress string `json:"ip_address" gorm:"size:45"`
+	UserAgent string `json:"user_agent" gorm:"size:500"`
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
+
+// TableName returns the table name for PasswordResetToken model
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// IsExpired checks if the reset token has passed its expiry time
+func (t *PasswordResetToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsUsed checks if the reset token has already been redeemed
+func (t *PasswordResetToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// PersonalAccessToken is a long-lived API key that lets machine clients act as its owner
+// within the token's scopes. Only the SHA-256 hash of the token is stored; the raw value
+// is shown once when the token is created.
+type PersonalAccessToken struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	UserID    uint   `json:"user_id" gorm:"not null;index"`
+	Name      string `json:"name" gorm:"size:100;not null"`
+	TokenHash string `json:"-" gorm:"uniqueIndex;size:64;not null"` // SHA-256 hex of the raw token
+	Prefix    string `json:"prefix" gorm:"size:16;not null"`        // First characters of the raw token, to recognize it
+	Scopes    string `json:"-" gorm:"size:500;not null"`            // Comma-separated list of scopes
+
+	// Lifecycle
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" gorm:"index"` // Nil for tokens that never expire
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty" gorm:"size:45"`
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// PersonalAccessTokenPrefix starts every raw personal access token, so they can be told apart
+// from JWTs and found by secret scanners
+const PersonalAccessTokenPrefix = "lk_"
+
+// Personal access token scopes
+const (
+	ScopeTasksRead        = "tasks:read"
+	ScopeTasksWrite       = "tasks:write"
+	ScopeInviteCodesRead  = "invite_codes:read"
+	ScopeInviteCodesWrite = "invite_codes:write"
+)
+
+// PersonalAccessTokenScopes lists every scope a personal access token can be granted
+var PersonalAccessTokenScopes = []string{
+	ScopeTasksRead,
+	ScopeTasksWrite,
+	ScopeInviteCodesRead,
+	ScopeInviteCodesWrite,
+}
+
+// TableName returns the table name for PersonalAccessToken model
+func (PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+// IsExpired checks if the token has passed its expiry time
+func (t *PersonalAccessToken) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+// ScopeList returns the scopes granted to the token
+func (t *PersonalAccessToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// HasScope checks if the token has been granted scope
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, granted := range t.ScopeList() {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// PersonalAccessTokenResponse represents the personal access token data structure for API responses
+type PersonalAccessTokenResponse struct {
+	ID         uint       `json:"id" example:"1"`                            // Token ID
+	Name       string     `json:"name" example:"CI deploy"`                  // Name given by the owner
+	Prefix     string     `json:"prefix" example:"lk_3q2-7wEr"`              // First characters of the token
+	Scopes     []string   `json:"scopes" example:"tasks:write"`              // Granted scopes
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`                      // Expiry time, absent if the token never expires
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`                    // Last time the token was used
+	LastUsedIP string     `json:"last_used_ip,omitempty" example:"10.0.0.1"` // IP address of the last use
+	CreatedAt  time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"` // Creation time
+	Token      string     `json:"token,omitempty"`                           // Raw token, only returned on creation
+}
+
+// ToResponse converts PersonalAccessToken to PersonalAccessTokenResponse
+func (t *PersonalAccessToken) ToResponse() *PersonalAccessTokenResponse {
+	return &PersonalAccessTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.ScopeList(),
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		LastUsedIP: t.LastUsedIP,
+		CreatedAt:  t.CreatedAt,
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// RecoveryCode is a single-use backup code that can stand in for a TOTP code
+// when the user has lost access to their authenticator.
+// Only the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	UserID   uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash string     `json:"-" gorm:"size:64;not null;index"` // SHA-256 hex of the normalized code
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
+
+// TableName returns the table name for RecoveryCode model
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// IsUsed checks if the recovery code has already been redeemed
+func (rc *RecoveryCode) IsUsed() bool {
+	return rc.UsedAt != nil
+}
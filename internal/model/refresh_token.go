@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken represents an opaque, long-lived refresh token.
+// Only the SHA-256 hash of the token is stored; the raw value is returned to the client once.
+// Tokens issued from the same login share a FamilyID so that reuse of a rotated token
+// can revoke every descendant at once.
+type RefreshToken struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	UserID    uint   `json:"user_id" gorm:"not null;index"`
+	SessionID uint   `json:"session_id" gorm:"not null;index"`
+	TokenHash string `json:"-" gorm:"uniqueIndex;size:64;not null"` // SHA-256 hex of the raw token
+	FamilyID  string `json:"family_id" gorm:"size:64;not null;index"`
+
+	// Lifecycle
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"` // Set once the token has been exchanged for a new one
+	RevokedAt *time.Time `json:"revoked_at,omitempty" gorm:"index"`
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null;index"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName returns the table name for RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsExpired checks if the refresh token has passed its expiry time
+func (rt *RefreshToken) IsExpired() bool {
+	return time.Now().After(rt.ExpiresAt)
+}
+
+// IsRevoked checks if the refresh token has been revoked
+func (rt *RefreshToken) IsRevoked() bool {
+	return rt.RevokedAt != nil
+}
+
+// IsRotated checks if the refresh token has already been exchanged
+func (rt *RefreshToken) IsRotated() bool {
+	return rt.RotatedAt != nil
+}
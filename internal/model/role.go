@@ -0,0 +1,114 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Role is a named set of permissions. Users are assigned a role through User.Role, which
+// holds the role name.
+type Role struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	Name        string `json:"name" gorm:"uniqueIndex;size:20;not null"`
+	Description string `json:"description" gorm:"size:255"`
+	Permissions string `json:"-" gorm:"type:text;not null"` // Comma-separated list of permissions
+	BuiltIn     bool   `json:"built_in" gorm:"not null;default:false"`
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// Permissions
+const (
+	PermissionUsersRead         = "users:read"
+	PermissionUsersWrite        = "users:write"
+	PermissionUsersDelete       = "users:delete"
+	PermissionUsersSecurity     = "users:security"
+	PermissionUsersImpersonate  = "users:impersonate"
+	PermissionRolesRead         = "roles:read"
+	PermissionRolesManage       = "roles:manage"
+	PermissionRolesAssign       = "roles:assign"
+	PermissionInviteCodesManage = "invite_codes:manage"
+	PermissionTasksEnqueue      = "tasks:enqueue"
+	PermissionTasksRead         = "tasks:read"
+	PermissionTasksPrioritize   = "tasks:prioritize"
+	PermissionAuditLogsRead     = "audit_logs:read"
+	PermissionSchedulesRead     = "schedules:read"
+	PermissionSchedulesManage   = "schedules:manage"
+)
+
+// AllPermissions lists every permission a role can be granted
+var AllPermissions = []string{
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionUsersDelete,
+	PermissionUsersSecurity,
+	PermissionUsersImpersonate,
+	PermissionRolesRead,
+	PermissionRolesManage,
+	PermissionRolesAssign,
+	PermissionInviteCodesManage,
+	PermissionTasksEnqueue,
+	PermissionTasksRead,
+	PermissionTasksPrioritize,
+	PermissionAuditLogsRead,
+	PermissionSchedulesRead,
+	PermissionSchedulesManage,
+}
+
+// DefaultUserPermissions are the permissions the built-in user role is seeded with
+var DefaultUserPermissions = []string{
+	PermissionTasksEnqueue,
+	PermissionTasksRead,
+}
+
+// TableName returns the table name for Role model
+func (Role) TableName() string {
+	return "roles"
+}
+
+// PermissionList returns the permissions granted to the role
+func (r *Role) PermissionList() []string {
+	if r.Permissions == "" {
+		return []string{}
+	}
+	return strings.Split(r.Permissions, ",")
+}
+
+// HasPermission checks if the role has been granted permission
+func (r *Role) HasPermission(permission string) bool {
+	for _, granted := range r.PermissionList() {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleResponse represents the role data structure for API responses
+type RoleResponse struct {
+	ID          uint      `json:"id" example:"1"`                            // Role ID
+	Name        string    `json:"name" example:"support"`                    // Role name, stored in the user's role field
+	Description string    `json:"description" example:"Customer support"`    // Description
+	Permissions []string  `json:"permissions" example:"users:read"`          // Granted permissions
+	BuiltIn     bool      `json:"built_in" example:"false"`                  // Built-in roles cannot be deleted
+	CreatedAt   time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"` // Creation time
+	UpdatedAt   time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"` // Last update time
+}
+
+// ToResponse converts Role to RoleResponse
+func (r *Role) ToResponse() *RoleResponse {
+	return &RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: r.PermissionList(),
+		BuiltIn:     r.BuiltIn,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+)
+
+// Session represents a single login of a user on a device.
+// Access tokens carry the session ID and refresh tokens are bound to it,
+// so revoking a session logs that device out.
+type Session struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	UserID   uint   `json:"user_id" gorm:"not null;index"`
+	Provider string `json:"provider" gorm:"size:50;not null"` // Login method used to create the session
+
+	// Active organization, carried in the session's access tokens
+	OrganizationID *uint `json:"organization_id,omitempty" gorm:"index"`
+
+	// Admin impersonating the user, set for sessions created through impersonation
+	ImpersonatorID *uint `json:"impersonator_id,omitempty" gorm:"index"`
+
+	// Client Info
+	Device    string `json:"device" gorm:"size:100"`     // Human readable device description, e.g. "Chrome on macOS"
+	UserAgent string `json:"user_agent" gorm:"size:255"` // User agent string
+	IPAddress string `json:"ip_address" gorm:"size:45"`  // IPv4/IPv6 address
+
+	// Lifecycle
+	LastSeenAt time.Time  `json:"last_seen_at" gorm:"not null;index"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" gorm:"index"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" gorm:"index"` // Set for sessions that cannot be refreshed and end on their own
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null;index"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// Login methods recorded on sessions in addition to the account providers
+const (
+	SessionProviderPasskey       = "passkey"
+	SessionProviderMagicLink     = "magic_link"
+	SessionProviderImpersonation = "impersonation"
+)
+
+// TableName returns the table name for Session model
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// IsRevoked checks if the session has been revoked
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
+
+// SessionResponse represents the session data structure for API responses
+type SessionResponse struct {
+	ID             uint       `json:"id" example:"1"`                              // Session ID
+	UserID         uint       `json:"user_id" example:"1"`                         // Owner user ID
+	Provider       string     `json:"provider" example:"local"`                    // Login method
+	Device         string     `json:"device" example:"Chrome on macOS"`            // Device description
+	UserAgent      string     `json:"user_agent" example:"Mozilla/5.0..."`         // User agent string
+	IPAddress      string     `json:"ip_address" example:"192.168.1.100"`          // Last known IP address
+	LastSeenAt     time.Time  `json:"last_seen_at" example:"2024-01-01T00:00:00Z"` // Last activity time
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`                        // Revocation time
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`                        // Time the session ends on its own, if it cannot be refreshed
+	ImpersonatorID *uint      `json:"impersonator_id,omitempty" example:"1"`       // Admin impersonating the user in this session
+	CreatedAt      time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`   // Login time
+	Current        bool       `json:"current" example:"true"`                      // Whether this is the session making the request
+}
+
+// ToResponse converts Session to SessionResponse
+func (s *Session) ToResponse() *SessionResponse {
+	return &SessionResponse{
+		ID:             s.ID,
+		UserID:         s.UserID,
+		Provider:       s.Provider,
+		Device:         s.Device,
+		UserAgent:      s.UserAgent,
+		IPAddress:      s.IPAddress,
+		LastSeenAt:     s.LastSeenAt,
+		RevokedAt:      s.RevokedAt,
+		ExpiresAt:      s.ExpiresAt,
+		ImpersonatorID: s.ImpersonatorID,
+		CreatedAt:      s.CreatedAt,
+	}
+}
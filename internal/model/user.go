@@ -22,14 +22,26 @@ type User struct {
 	Status   string `json:"status" gorm:"size:20;not null;default:'active';index"` // active, inactive, banned
 	Role     string `json:"role" gorm:"size:20;not null;default:'user';index"`     // user, admin
 
-	// OAuth Provider IDs (nullable for local accounts)
-	GoogleID   *string `json:"google_id,omitempty" gorm:"uniqueIndex;size:100"`
-	GitHubID   *string `json:"github_id,omitempty" gorm:"uniqueIndex;size:100;column:github_id"`
-	TelegramID *string `json:"telegram_id,omitempty" gorm:"uniqueIndex;size:100"`
+	// EmailVerifiedAt is set once the user has proven ownership of the email address
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	// TokenVersion is embedded in access tokens; bumping it invalidates every token issued before
+	TokenVersion int `json:"-" gorm:"not null;default:0"`
+
+	// Two-Factor Authentication (TOTP, RFC 6238)
+	TOTPSecret       string     `json:"-" gorm:"size:128"`           // Encrypted base32 secret, pending until enrollment is confirmed
+	TOTPEnabledAt    *time.Time `json:"totp_enabled_at,omitempty"`   // Set once enrollment has been confirmed with a valid code
+	TOTPLastUsedStep int64      `json:"-" gorm:"not null;default:0"` // Last accepted time step, used to reject replayed codes
 
 	// Provider Metadata
 	ProviderData string `json:"provider_data,omitempty" gorm:"type:text"`
 
+	// Legacy provider account IDs, filled from user_identities when the user is loaded so API
+	// responses keep them until a versioned removal. New clients should use /user/identities.
+	GoogleID   *string `json:"google_id,omitempty" gorm:"-"`
+	GitHubID   *string `json:"github_id,omitempty" gorm:"-"`
+	TelegramID *string `json:"telegram_id,omitempty" gorm:"-"`
+
 	// Invite Code Fields
 	InviteCodeID   *uint   `json:"invite_code_id,omitempty" gorm:"index"`           // 使用的邀请码ID
 	InviteCodeUsed *string `json:"invite_code_used,omitempty" gorm:"size:32;index"` // 使用的邀请码(冗余字段，便于查询)
@@ -66,6 +78,29 @@ const (
 	ProviderTelegram = "telegram"
 )
 
+// AfterFind fills the legacy provider ID fields from the user's identities
+func (u *User) AfterFind(tx *gorm.DB) error {
+	var identities []*UserIdentity
+	if err := tx.Session(&gorm.Session{NewDB: true}).
+		Where("user_id = ? AND provider IN ?", u.ID, []string{ProviderGoogle, ProviderGitHub, ProviderTelegram}).
+		Find(&identities).Error; err != nil {
+		return err
+	}
+
+	for _, identity := range identities {
+		subject := identity.Subject
+		switch identity.Provider {
+		case ProviderGoogle:
+			u.GoogleID = &subject
+		case ProviderGitHub:
+			u.GitHubID = &subject
+		case ProviderTelegram:
+			u.TelegramID = &subject
+		}
+	}
+	return nil
+}
+
 // IsDeleted checks if the user is soft deleted
 func (u *User) IsDeleted() bool {
 	return u.DeletedAt.Valid
@@ -81,6 +116,16 @@ func (u *User) IsAdmin() bool {
 	return u.Role == UserRoleAdmin && u.IsActive()
 }
 
+// IsEmailVerified checks if the user has verified their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsTOTPEnabled checks if the user has confirmed TOTP two-factor authentication
+func (u *User) IsTOTPEnabled() bool {
+	return u.TOTPEnabledAt != nil && u.TOTPSecret != ""
+}
+
 // IsLocalAccount checks if this is a local (email/password) account
 func (u *User) IsLocalAccount() bool {
 	return u.Provider == ProviderLocal
@@ -91,25 +136,6 @@ func (u *User) IsOAuthAccount() bool {
 	return u.Provider != ProviderLocal
 }
 
-// GetProviderID returns the provider-specific ID based on the provider
-func (u *User) GetProviderID() string {
-	switch u.Provider {
-	case ProviderGoogle:
-		if u.GoogleID != nil {
-			return *u.GoogleID
-		}
-	case ProviderGitHub:
-		if u.GitHubID != nil {
-			return *u.GitHubID
-		}
-	case ProviderTelegram:
-		if u.TelegramID != nil {
-			return *u.TelegramID
-		}
-	}
-	return ""
-}
-
 // SoftDelete performs soft delete on the user
 func (u *User) SoftDelete(db *gorm.DB) error {
 	return db.Delete(u).Error
@@ -137,14 +163,18 @@ type UserResponse struct {
 	Status   string `json:"status"`
 	Role     string `json:"role"`
 
-	// OAuth Provider IDs (only show if not empty)
-	GoogleID   *string `json:"google_id,omitempty"`
-	GitHubID   *string `json:"github_id,omitempty"`
-	TelegramID *string `json:"telegram_id,omitempty"`
+	// Verification and Two-Factor Authentication
+	EmailVerified bool `json:"email_verified"`
+	TOTPEnabled   bool `json:"totp_enabled"`
 
 	// Provider Metadata (only show if not empty)
 	ProviderData string `json:"provider_data,omitempty"`
 
+	// Legacy provider account IDs, kept for existing clients (use /user/identities instead)
+	GoogleID   *string `json:"google_id,omitempty"`
+	GitHubID   *string `json:"github_id,omitempty"`
+	TelegramID *string `json:"telegram_id,omitempty"`
+
 	// Invite Code Fields
 	InviteCodeID   *uint   `json:"invite_code_id,omitempty"`
 	InviteCodeUsed *string `json:"invite_code_used,omitempty"`
@@ -172,14 +202,18 @@ func (u *User) ToResponse() *UserResponse {
 		Status:   u.Status,
 		Role:     u.Role,
 
-		// OAuth Provider IDs
-		GoogleID:   u.GoogleID,
-		GitHubID:   u.GitHubID,
-		TelegramID: u.TelegramID,
+		// Verification and Two-Factor Authentication
+		EmailVerified: u.IsEmailVerified(),
+		TOTPEnabled:   u.IsTOTPEnabled(),
 
 		// Provider Metadata
 		ProviderData: u.ProviderData,
 
+		// Legacy provider account IDs
+		GoogleID:   u.GoogleID,
+		GitHubID:   u.GitHubID,
+		TelegramID: u.TelegramID,
+
 		// Invite Code Fields
 		InviteCodeID:   u.InviteCodeID,
 		InviteCodeUsed: u.InviteCodeUsed,
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+)
+
+// UserIdentity links a user to an account at an external login provider (Google, GitHub,
+// Telegram or an OIDC provider), identified by the provider key and the provider's stable
+// subject identifier for that account. A user can have at most one identity per provider.
+type UserIdentity struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	UserID   uint   `json:"user_id" gorm:"not null;index"`
+	Provider string `json:"provider" gorm:"size:50;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject  string `json:"subject" gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject"` // e.g. the OIDC "sub" claim
+	Email    string `json:"email" gorm:"size:255"`                                                             // Email reported by the provider when the account was linked
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName returns the table name for UserIdentity model
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+)
+
+// WebAuthnCredential is a passkey (WebAuthn public key credential) registered by a user.
+// The private key never leaves the authenticator; only the public key and the
+// metadata needed to verify assertions are stored.
+type WebAuthnCredential struct {
+	// Primary Key
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	// Core Fields
+	UserID       uint   `json:"user_id" gorm:"not null;index"`
+	Name         string `json:"name" gorm:"size:100"`                   // User supplied label, e.g. "MacBook Touch ID"
+	CredentialID string `json:"-" gorm:"uniqueIndex;size:255;not null"` // Base64url encoded credential ID
+	PublicKey    []byte `json:"-" gorm:"type:blob;not null"`            // COSE encoded public key
+	Algorithm    int64  `json:"-"`                                      // COSE algorithm identifier
+
+	// Authenticator Info
+	AttestationType string `json:"attestation_type" gorm:"size:50"`
+	Transports      string `json:"transports" gorm:"size:255"` // Comma separated, e.g. "internal,hybrid"
+	AAGUID          string `json:"aaguid" gorm:"size:36"`      // Identifies the authenticator model
+	SignCount       uint32 `json:"-" gorm:"not null;default:0"`
+	Attachment      string `json:"attachment" gorm:"size:20"` // platform or cross-platform
+
+	// Flags
+	UserVerified   bool `json:"user_verified"`
+	BackupEligible bool `json:"backup_eligible"` // Credential can be synced between devices
+	BackupState    bool `json:"backup_state"`    // Credential is currently synced
+
+	// Usage
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	// Timestamp Fields
+	CreatedAt time.Time `json:"created_at" gorm:"not null;index"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName returns the table name for WebAuthnCredential model
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// WebAuthnCredentialResponse represents the passkey data structure for API responses
+type WebAuthnCredentialResponse struct {
+	ID             uint       `json:"id" example:"1"`                                        // Passkey ID
+	Name           string     `json:"name" example:"MacBook Touch ID"`                       // User supplied label
+	AAGUID         string     `json:"aaguid" example:"fbfc3007-154e-4ecc-8c0b-6e020557d7bd"` // Authenticator model
+	Transports     string     `json:"transports" example:"internal,hybrid"`                  // Supported transports
+	Attachment     string     `json:"attachment" example:"platform"`                         // platform or cross-platform
+	BackupEligible bool       `json:"backup_eligible" example:"true"`                        // Can be synced between devices
+	BackupState    bool       `json:"backup_state" example:"true"`                           // Currently synced
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`                                // Last successful login
+	CreatedAt      time.Time  `json:"created_at" example:"2024-01-01T00:00:00Z"`             // Registration time
+}
+
+// ToResponse converts WebAuthnCredential to WebAuthnCredentialResponse
+func (c *WebAuthnCredential) ToResponse() *WebAuthnCredentialResponse {
+	return &WebAuthnCredentialResponse{
+		ID:             c.ID,
+		Name:           c.Name,
+		AAGUID:         c.AAGUID,
+		Transports:     c.Transports,
+		Attachment:     c.Attachment,
+		BackupEligible: c.BackupEligible,
+		BackupState:    c.BackupState,
+		LastUsedAt:     c.LastUsedAt,
+		CreatedAt:      c.CreatedAt,
+	}
+}
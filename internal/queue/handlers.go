@@ -27,7 +27,7 @@ func EmailTaskHandler(ctx context.Context, task *Task) error {
 	logger.Info("Sending email",
 		logger.String("to", to),
 		logger.String("subject", subject),
-		logger.String("body", body),
+		logger.Int("body_length", len(body)), // The body can carry verification and login links
 		logger.String("task_id", task.ID),
 	)
 	
@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// DefaultQueueName is the queue task types without a route are enqueued into
+const DefaultQueueName = "default"
+
+// SetQueuePriority makes workers poll queueName with the given priority weight; higher weights
+// are polled first or more often. Only queues with a priority are processed. Call before Start.
+func (tq *TaskQueue) SetQueuePriority(queueName string, priority int) {
+	if priority < 1 {
+		priority = 1
+	}
+	tq.priorities[queueName] = priority
+
+	tq.queues = tq.queues[:0]
+	for name := range tq.priorities {
+		tq.queues = append(tq.queues, name)
+	}
+	sort.Slice(tq.queues, func(i, j int) bool {
+		if tq.priorities[tq.queues[i]] != tq.priorities[tq.queues[j]] {
+			return tq.priorities[tq.queues[i]] > tq.priorities[tq.queues[j]]
+		}
+		return tq.queues[i] < tq.queues[j]
+	})
+}
+
+// RouteTaskType sends tasks of taskType to queueName instead of the default queue. Call before Start.
+func (tq *TaskQueue) RouteTaskType(taskType, queueName string) {
+	tq.routes[taskType] = queueName
+}
+
+// ValidateRoutes checks that the default queue and every route target are polled, so no task
+// can be enqueued into a queue that no worker processes. Call after configuring the queue.
+func (tq *TaskQueue) ValidateRoutes() error {
+	if _, ok := tq.priorities[DefaultQueueName]; !ok {
+		return fmt.Errorf("queue %s has no priority", DefaultQueueName)
+	}
+	for taskType, queueName := range tq.routes {
+		if _, ok := tq.priorities[queueName]; !ok {
+			return fmt.Errorf("task type %s is routed to queue %s, which has no priority", taskType, queueName)
+		}
+	}
+	return nil
+}
+
+// QueueFor returns the queue tasks of taskType are routed to
+func (tq *TaskQueue) QueueFor(taskType string) string {
+	if queueName, ok := tq.routes[taskType]; ok {
+		return queueName
+	}
+	return DefaultQueueName
+}
+
+// Priority returns the priority weight of queueName, and false if workers do not poll it
+func (tq *TaskQueue) Priority(queueName string) (int, bool) {
+	priority, ok := tq.priorities[queueName]
+	return priority, ok
+}
+
+// Queues returns the names of the polled queues, highest priority first
+func (tq *TaskQueue) Queues() []string {
+	return append([]string(nil), tq.queues...)
+}
+
+// pollOrder returns the order in which a worker polls the queues for its next task. With strict
+// priority it is always highest priority first; otherwise each position is drawn at random,
+// weighted by priority, from the queues not yet placed.
+func (tp *TaskProcessor) pollOrder() []string {
+	queues := tp.queue.Queues()
+	if tp.strict || len(queues) < 2 {
+		return queues
+	}
+
+	order := make([]string, 0, len(queues))
+	for len(queues) > 0 {
+		total := 0
+		for _, queueName := range queues {
+			total += tp.queue.priorities[queueName]
+		}
+
+		pick := rand.Intn(total)
+		for i, queueName := range queues {
+			pick -= tp.queue.priorities[queueName]
+			if pick < 0 {
+				order = append(order, queueName)
+				queues = append(queues[:i], queues[i+1:]...)
+				break
+			}
+		}
+	}
+	return order
+}
@@ -0,0 +1,23 @@
+package queue
+
+import "testing"
+
+func TestValidateRoutes(t *testing.T) {
+	q, _ := newTestQueue(t)
+	q.SetQueuePriority("critical", 6)
+	q.RouteTaskType("email", "critical")
+
+	if err := q.ValidateRoutes(); err == nil {
+		t.Fatal("ValidateRoutes accepted a configuration without the default queue")
+	}
+
+	q.SetQueuePriority(DefaultQueueName, 3)
+	if err := q.ValidateRoutes(); err != nil {
+		t.Fatalf("ValidateRoutes: %v", err)
+	}
+
+	q.RouteTaskType("data_processing", "low")
+	if err := q.ValidateRoutes(); err == nil {
+		t.Fatal("ValidateRoutes accepted a route to a queue without priority")
+	}
+}
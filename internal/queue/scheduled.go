@@ -0,0 +1,119 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"linke/internal/logger"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// promoteInterval is how often a started TaskProcessor moves due scheduled tasks onto the queue
+	promoteInterval = time.Second
+
+	// promoteBatchSize caps how many due tasks are moved per promotion, keeping the script short
+	promoteBatchSize = 100
+
+	// retryBaseDelay is the delay before the first retry of a failed task, doubled for every further retry
+	retryBaseDelay = 5 * time.Second
+
+	// retryMaxDelay caps the delay between retries
+	retryMaxDelay = 10 * time.Minute
+)
+
+// promoteDueScript moves up to ARGV[2] tasks due at ARGV[1] from the scheduled set onto the queue
+var promoteDueScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+for _, item in ipairs(due) do
+	redis.call('ZREM', KEYS[1], item)
+	redis.call('LPUSH', KEYS[2], item)
+end
+return #due
+`)
+
+// EnqueueAt schedules task to be added to the queue at runAt. Tasks due now or in the past are enqueued immediately.
+func (tq *TaskQueue) EnqueueAt(ctx context.Context, queueName string, task *Task, runAt time.Time) error {
+	if !runAt.After(time.Now()) {
+		return tq.Enqueue(ctx, queueName, task)
+	}
+
+	task.CreatedAt = time.Now()
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+
+	return tq.client.ZAdd(ctx, scheduledKey(queueName), &redis.Z{
+		Score:  float64(runAt.UnixMilli()),
+		Member: data,
+	}).Err()
+}
+
+// EnqueueIn schedules task to be added to the queue after delay
+func (tq *TaskQueue) EnqueueIn(ctx context.Context, queueName string, task *Task, delay time.Duration) error {
+	return tq.EnqueueAt(ctx, queueName, task, time.Now().Add(delay))
+}
+
+// PromoteDue moves scheduled tasks that are due onto the queue and returns how many were moved
+func (tq *TaskQueue) PromoteDue(ctx context.Context, queueName string) (int64, error) {
+	keys := []string{scheduledKey(queueName), queueName}
+	promoted, err := promoteDueScript.Run(ctx, tq.client, keys, time.Now().UnixMilli(), promoteBatchSize).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to promote scheduled tasks: %w", err)
+	}
+	return promoted, nil
+}
+
+// GetScheduledLength returns the number of tasks scheduled to run on queueName later
+func (tq *TaskQueue) GetScheduledLength(ctx context.Context, queueName string) (int64, error) {
+	return tq.client.ZCard(ctx, scheduledKey(queueName)).Result()
+}
+
+// promoteScheduledTasks periodically moves due scheduled tasks onto the queue
+func (tp *TaskProcessor) promoteScheduledTasks(ctx context.Context, queueName string) {
+	ticker := time.NewTicker(promoteInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep promoting while full batches come back so a backlog drains quickly
+			for {
+				promoted, err := tp.queue.PromoteDue(ctx, queueName)
+				if err != nil {
+					logger.Error("Error promoting scheduled tasks",
+						logger.String("queue", queueName),
+						logger.Error2("error", err),
+					)
+					break
+				}
+				if promoted < promoteBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// retryBackoff returns the delay before the given retry: exponential in the number of retries,
+// capped at retryMaxDelay, with the upper half jittered so failing tasks do not retry in lockstep
+func retryBackoff(retry int) time.Duration {
+	delay := retryMaxDelay
+	if retry < 1 {
+		retry = 1
+	}
+	if shift := retry - 1; shift < 32 {
+		if d := retryBaseDelay << shift; d > 0 && d < retryMaxDelay {
+			delay = d
+		}
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
@@ -0,0 +1,380 @@
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"linke/internal/logger"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// schedulerLeaderKey holds the ID of the instance currently firing schedules
+	schedulerLeaderKey = "scheduler:leader"
+
+	// schedulerPausedKey is the set of paused schedule names, shared by all instances
+	schedulerPausedKey = "scheduler:paused"
+
+	// schedulerLastRunKey maps schedule names to the Unix millisecond time they last fired
+	schedulerLastRunKey = "scheduler:last_run"
+
+	// schedulerFiredKeyPrefix marks a run as fired so it is enqueued once even if leadership changes mid-tick
+	schedulerFiredKeyPrefix = "scheduler:fired:"
+
+	// schedulerTickInterval is how often the scheduler checks for due schedules and renews leadership
+	schedulerTickInterval = time.Second
+
+	// schedulerLeaderTTL is how long leadership lasts without renewal, after which another instance takes over
+	schedulerLeaderTTL = 15 * time.Second
+
+	// schedulerFiredTTL is how long fired run markers are kept
+	schedulerFiredTTL = 24 * time.Hour
+
+	// schedulerRetryWindow is how long runs that failed to fire are retried on later ticks before they are skipped
+	schedulerRetryWindow = 5 * time.Minute
+)
+
+// ErrScheduleNotFound is returned when no schedule is registered under the given name
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// renewLeaderScript extends the leader lock if it is still held by ARGV[1]
+var renewLeaderScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseLeaderScript deletes the leader lock if it is still held by ARGV[1]
+var releaseLeaderScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// fireScheduleScript marks the run KEYS[1] as fired by ARGV[1] for ARGV[2] milliseconds and
+// pushes the task ARGV[3] onto the queue KEYS[2], unless the run was already fired. Both happen
+// or neither does, so a run is never marked without its task being enqueued.
+var fireScheduleScript = redis.NewScript(`
+if not redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return 0
+end
+redis.call('LPUSH', KEYS[2], ARGV[3])
+return 1
+`)
+
+// Schedule is a recurring task enqueued whenever its cron expression fires
+type Schedule struct {
+	Name     string
+	Spec     string
+	TaskType string
+	Payload  map[string]interface{}
+
+	cron cron.Schedule
+}
+
+// ScheduleStatus describes a registered schedule for the admin API
+type ScheduleStatus struct {
+	Name      string                 `json:"name" example:"nightly-cleanup"`                    // Unique schedule name
+	Spec      string                 `json:"spec" example:"0 3 * * *"`                          // Cron expression, evaluated in server time
+	QueueName string                 `json:"queue_name" example:"low"`                          // Queue the task type is routed to
+	TaskType  string                 `json:"task_type" example:"data_processing"`               // Type of the enqueued task
+	Payload   map[string]interface{} `json:"payload"`                                           // Payload of the enqueued task
+	Paused    bool                   `json:"paused" example:"false"`                            // Paused schedules do not fire
+	NextRun   time.Time              `json:"next_run" example:"2024-01-02T03:00:00Z"`           // Next time the cron expression fires
+	LastRun   *time.Time             `json:"last_run,omitempty" example:"2024-01-01T03:00:00Z"` // Last time a task was enqueued
+}
+
+// Scheduler enqueues recurring tasks on cron schedules. Every instance runs a Scheduler, but only
+// the instance holding the Redis leader lock fires schedules, so each run is enqueued once.
+type Scheduler struct {
+	client     *redis.Client
+	queue      *TaskQueue
+	instanceID string
+
+	mu        sync.RWMutex
+	schedules map[string]*Schedule
+}
+
+func NewScheduler(client *redis.Client, queue *TaskQueue) *Scheduler {
+	return &Scheduler{
+		client:     client,
+		queue:      queue,
+		instanceID: newInstanceID(),
+		schedules:  make(map[string]*Schedule),
+	}
+}
+
+// Register adds a schedule that enqueues a taskType task with payload, into the queue the task type
+// is routed to, whenever the standard five-field cron expression spec fires
+func (s *Scheduler) Register(name, spec, taskType string, payload map[string]interface{}) error {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("invalid cron expression %q for schedule %s: %w", spec, name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.schedules[name]; exists {
+		return fmt.Errorf("schedule %s is already registered", name)
+	}
+	s.schedules[name] = &Schedule{
+		Name:     name,
+		Spec:     spec,
+		TaskType: taskType,
+		Payload:  payload,
+		cron:     schedule,
+	}
+	return nil
+}
+
+// Run fires due schedules while this instance is the leader, until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	logger.Info("Starting task scheduler", logger.String("instance_id", s.instanceID))
+
+	ticker := time.NewTicker(schedulerTickInterval)
+	defer ticker.Stop()
+
+	leader := false
+	var lastCheck time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			if leader {
+				s.releaseLeadership()
+			}
+			logger.Info("Task scheduler stopped", logger.String("instance_id", s.instanceID))
+			return
+		case now := <-ticker.C:
+			isLeader, err := s.holdLeadership(ctx, leader)
+			if err != nil {
+				logger.Error("Error acquiring scheduler leadership",
+					logger.String("instance_id", s.instanceID),
+					logger.Error2("error", err),
+				)
+				isLeader = false
+			}
+			if isLeader != leader {
+				logger.Info("Scheduler leadership changed",
+					logger.String("instance_id", s.instanceID),
+					logger.Any("leader", isLeader),
+				)
+				// Runs missed while no instance was leader are skipped rather than fired late
+				lastCheck = now
+			}
+			leader = isLeader
+
+			if leader {
+				// Runs that failed to fire are retried on the next tick; the fired markers keep
+				// runs that did fire from being enqueued twice
+				if s.fireDue(ctx, lastCheck, now) || now.Sub(lastCheck) > schedulerRetryWindow {
+					lastCheck = now
+				}
+			}
+		}
+	}
+}
+
+// List returns every registered schedule ordered by name
+func (s *Scheduler) List(ctx context.Context) ([]*ScheduleStatus, error) {
+	paused, err := s.pausedSchedules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lastRuns, err := s.client.HGetAll(ctx, schedulerLastRunKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule runs: %w", err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	statuses := make([]*ScheduleStatus, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		status := &ScheduleStatus{
+			Name:      schedule.Name,
+			Spec:      schedule.Spec,
+			QueueName: s.queue.QueueFor(schedule.TaskType),
+			TaskType:  schedule.TaskType,
+			Payload:   schedule.Payload,
+			Paused:    paused[schedule.Name],
+			NextRun:   schedule.cron.Next(now),
+		}
+		if value, ok := lastRuns[schedule.Name]; ok {
+			if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+				lastRun := time.UnixMilli(millis)
+				status.LastRun = &lastRun
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses, nil
+}
+
+// Pause stops the named schedule from firing on every instance until it is resumed
+func (s *Scheduler) Pause(ctx context.Context, name string) error {
+	if !s.registered(name) {
+		return ErrScheduleNotFound
+	}
+	if err := s.client.SAdd(ctx, schedulerPausedKey, name).Err(); err != nil {
+		return fmt.Errorf("failed to pause schedule: %w", err)
+	}
+	return nil
+}
+
+// Resume lets a paused schedule fire again from its next run
+func (s *Scheduler) Resume(ctx context.Context, name string) error {
+	if !s.registered(name) {
+		return ErrScheduleNotFound
+	}
+	if err := s.client.SRem(ctx, schedulerPausedKey, name).Err(); err != nil {
+		return fmt.Errorf("failed to resume schedule: %w", err)
+	}
+	return nil
+}
+
+// pausedSchedules returns the names of the paused schedules
+func (s *Scheduler) pausedSchedules(ctx context.Context) (map[string]bool, error) {
+	names, err := s.client.SMembers(ctx, schedulerPausedKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load paused schedules: %w", err)
+	}
+	paused := make(map[string]bool, len(names))
+	for _, name := range names {
+		paused[name] = true
+	}
+	return paused, nil
+}
+
+func (s *Scheduler) registered(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, exists := s.schedules[name]
+	return exists
+}
+
+// holdLeadership renews the leader lock if this instance holds it, or tries to acquire it otherwise
+func (s *Scheduler) holdLeadership(ctx context.Context, leader bool) (bool, error) {
+	if leader {
+		renewed, err := renewLeaderScript.Run(ctx, s.client, []string{schedulerLeaderKey}, s.instanceID, schedulerLeaderTTL.Milliseconds()).Int64()
+		if err != nil {
+			return false, err
+		}
+		if renewed == 1 {
+			return true, nil
+		}
+	}
+	return s.client.SetNX(ctx, schedulerLeaderKey, s.instanceID, schedulerLeaderTTL).Result()
+}
+
+// releaseLeadership gives up the leader lock on shutdown so another instance takes over right away
+func (s *Scheduler) releaseLeadership() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := releaseLeaderScript.Run(ctx, s.client, []string{schedulerLeaderKey}, s.instanceID).Err(); err != nil {
+		logger.Warn("Failed to release scheduler leadership",
+			logger.String("instance_id", s.instanceID),
+			logger.Error2("error", err),
+		)
+	}
+}
+
+// fireDue enqueues a task for every unpaused schedule with a run in (from, to]. A schedule that
+// fired several times in the window is enqueued once. It reports whether every due run was fired.
+func (s *Scheduler) fireDue(ctx context.Context, from, to time.Time) bool {
+	paused, err := s.pausedSchedules(ctx)
+	if err != nil {
+		logger.Error("Error firing schedules", logger.Error2("error", err))
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ok := true
+	for _, schedule := range s.schedules {
+		runAt := schedule.cron.Next(from)
+		if runAt.After(to) || paused[schedule.Name] {
+			continue
+		}
+		if err := s.fire(ctx, schedule, runAt); err != nil {
+			logger.Error("Error firing schedule",
+				logger.String("schedule", schedule.Name),
+				logger.Error2("error", err),
+			)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// fire enqueues the task of schedule for the run at runAt, unless another instance already did
+func (s *Scheduler) fire(ctx context.Context, schedule *Schedule, runAt time.Time) error {
+	payload := make(map[string]interface{}, len(schedule.Payload))
+	for key, value := range schedule.Payload {
+		payload[key] = value
+	}
+	task := &Task{
+		ID:       fmt.Sprintf("task-%d", time.Now().UnixNano()),
+		Type:     schedule.TaskType,
+		Payload:  payload,
+		Retry:    0,
+		MaxRetry: 3,
+	}
+	data, err := newTaskData(task)
+	if err != nil {
+		return err
+	}
+
+	firedKey := schedulerFiredKeyPrefix + schedule.Name + ":" + strconv.FormatInt(runAt.Unix(), 10)
+	keys := []string{firedKey, s.queue.QueueFor(task.Type)}
+	fired, err := fireScheduleScript.Run(ctx, s.client, keys, s.instanceID, schedulerFiredTTL.Milliseconds(), data).Int()
+	if err != nil {
+		return fmt.Errorf("failed to enqueue scheduled task: %w", err)
+	}
+	if fired == 0 {
+		// This run was already fired, by another instance or on an earlier tick
+		return nil
+	}
+
+	if err := s.client.HSet(ctx, schedulerLastRunKey, schedule.Name, time.Now().UnixMilli()).Err(); err != nil {
+		logger.Warn("Failed to record schedule run",
+			logger.String("schedule", schedule.Name),
+			logger.Error2("error", err),
+		)
+	}
+
+	logger.Info("Schedule fired",
+		logger.String("schedule", schedule.Name),
+		logger.String("task_id", task.ID),
+		logger.String("task_type", task.Type),
+	)
+	return nil
+}
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
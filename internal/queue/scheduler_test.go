@@ -0,0 +1,63 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSchedulerFiresEachRunOnce(t *testing.T) {
+	ctx := context.Background()
+	q, mr := newTestQueue(t)
+	q.SetQueuePriority(DefaultQueueName, 1)
+
+	s := NewScheduler(q.client, q)
+	if err := s.Register("every-minute", "* * * * *", "data_processing", map[string]interface{}{"data_type": "cleanup"}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	to := time.Now().Truncate(time.Minute).Add(time.Second)
+	from := to.Add(-2 * time.Second)
+	if !s.fireDue(ctx, from, to) {
+		t.Fatal("fireDue reported a failed run")
+	}
+
+	// Another instance, or a retried tick, covering the same run
+	other := NewScheduler(q.client, q)
+	if err := other.Register("every-minute", "* * * * *", "data_processing", nil); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if !other.fireDue(ctx, from, to) {
+		t.Fatal("fireDue reported a failed run")
+	}
+
+	length, err := q.GetQueueLength(ctx, DefaultQueueName)
+	assertLength(t, "queue length", length, err, 1)
+
+	task, err := q.TryDequeue(ctx, DefaultQueueName)
+	if err != nil || task == nil || task.Type != "data_processing" || task.Payload["data_type"] != "cleanup" {
+		t.Fatalf("TryDequeue = %+v, %v", task, err)
+	}
+	if mr.HGet(schedulerLastRunKey, "every-minute") == "" {
+		t.Fatal("last run was not recorded")
+	}
+}
+
+func TestSchedulerSkipsPausedSchedules(t *testing.T) {
+	ctx := context.Background()
+	q, _ := newTestQueue(t)
+
+	s := NewScheduler(q.client, q)
+	if err := s.Register("every-minute", "* * * * *", "data_processing", nil); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := s.Pause(ctx, "every-minute"); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+
+	to := time.Now().Truncate(time.Minute).Add(time.Second)
+	s.fireDue(ctx, to.Add(-2*time.Second), to)
+
+	length, err := q.GetQueueLength(ctx, DefaultQueueName)
+	assertLength(t, "queue length", length, err, 0)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"linke/internal/logger"
@@ -11,8 +12,55 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
+const (
+	// defaultVisibilityTimeout is how long a dequeued task stays leased to its worker before the
+	// reaper assumes the worker died and puts the task back on the queue
+	defaultVisibilityTimeout = 5 * time.Minute
+
+	// reapInterval is how often a started TaskProcessor checks for tasks whose lease has expired
+	reapInterval = 30 * time.Second
+
+	// idlePollInterval is how long a worker waits before polling again when every queue is empty
+	idlePollInterval = 500 * time.Millisecond
+
+	// typeConcurrencyRetryDelay is how long a task waits before it is retried when its type is at its concurrency limit
+	typeConcurrencyRetryDelay = time.Second
+)
+
+// requeueExpiredScript moves tasks whose lease expired from the processing list back to the head
+// of the queue. A task without a lease (its worker died between LMOVE and ZADD) is given one, so
+// it is requeued after a full visibility timeout too. Leases left without a task are dropped.
+var requeueExpiredScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local requeued = 0
+for _, item in ipairs(redis.call('LRANGE', KEYS[1], 0, -1)) do
+	local deadline = redis.call('ZSCORE', KEYS[2], item)
+	if not deadline then
+		redis.call('ZADD', KEYS[2], ARGV[2], item)
+	elseif tonumber(deadline) <= now then
+		redis.call('LREM', KEYS[1], 1, item)
+		redis.call('ZREM', KEYS[2], item)
+		redis.call('RPUSH', KEYS[3], item)
+		requeued = requeued + 1
+	end
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', now)
+return requeued
+`)
+
+// TaskQueue is a Redis list based queue with at-least-once delivery. TryDequeue atomically moves a
+// task onto a per-queue processing list and leases it to the worker; the task only leaves the
+// processing list when it is acknowledged, and RequeueExpired redelivers it if the lease runs out.
 type TaskQueue struct {
-	client *redis.Client
+	client            *redis.Client
+	visibilityTimeout time.Duration
+
+	// priorities holds the weight of every queue workers poll, and queues their names by descending weight
+	priorities map[string]int
+	queues     []string
+
+	// routes maps task types to the queue they are enqueued into
+	routes map[string]string
 }
 
 type Task struct {
@@ -22,6 +70,9 @@ type Task struct {
 	Retry   int                    `json:"retry"`
 	MaxRetry int                   `json:"max_retry"`
 	CreatedAt time.Time            `json:"created_at"`
+
+	// raw is the task as it was dequeued, used to find it on the processing list
+	raw string
 }
 
 type TaskHandler func(ctx context.Context, task *Task) error
@@ -29,34 +80,68 @@ type TaskHandler func(ctx context.Context, task *Task) error
 type TaskProcessor struct {
 	queue    *TaskQueue
 	handlers map[string]TaskHandler
+
+	// typeSlots and queueSlots cap how many tasks of a type or from a queue are handled at once across all workers
+	typeSlots  map[string]chan struct{}
+	queueSlots map[string]chan struct{}
+	strict     bool
+
+	// handlerCtx is passed to handlers; it is only cancelled when Shutdown gives up waiting
+	handlerCtx     context.Context
+	cancelHandlers context.CancelFunc
+
+	stopping chan struct{}
+	stopOnce sync.Once
+	workers  sync.WaitGroup
 }
 
 func NewTaskQueue(client *redis.Client) *TaskQueue {
 	return &TaskQueue{
-		client: client,
+		client:            client,
+		visibilityTimeout: defaultVisibilityTimeout,
+		priorities:        make(map[string]int),
+		routes:            make(map[string]string),
 	}
 }
 
 func NewTaskProcessor(queue *TaskQueue) *TaskProcessor {
+	handlerCtx, cancelHandlers := context.WithCancel(context.Background())
 	return &TaskProcessor{
-		queue:    queue,
-		handlers: make(map[string]TaskHandler),
+		queue:          queue,
+		handlers:       make(map[string]TaskHandler),
+		typeSlots:      make(map[string]chan struct{}),
+		queueSlots:     make(map[string]chan struct{}),
+		handlerCtx:     handlerCtx,
+		cancelHandlers: cancelHandlers,
+		stopping:       make(chan struct{}),
 	}
 }
 
 func (tq *TaskQueue) Enqueue(ctx context.Context, queueName string, task *Task) error {
-	task.CreatedAt = time.Now()
-	
-	data, err := json.Marshal(task)
+	data, err := newTaskData(task)
 	if err != nil {
-		return fmt.Errorf("failed to marshal task: %w", err)
+		return err
 	}
 
 	return tq.client.LPush(ctx, queueName, data).Err()
 }
 
-func (tq *TaskQueue) Dequeue(ctx context.Context, queueName string, timeout time.Duration) (*Task, error) {
-	result, err := tq.client.BRPop(ctx, timeout, queueName).Result()
+// newTaskData stamps a task about to be enqueued with its creation time and encodes it
+func newTaskData(task *Task) ([]byte, error) {
+	task.CreatedAt = time.Now()
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal task: %w", err)
+	}
+	return data, nil
+}
+
+// TryDequeue takes the next task and leases it to the caller for the visibility timeout, or
+// returns nil right away when the queue is empty. The task must be acknowledged with Ack once
+// handled, otherwise it is delivered again.
+func (tq *TaskQueue) TryDequeue(ctx context.Context, queueName string) (*Task, error) {
+	raw, err := tq.client.LMove(ctx, queueName, processingKey(queueName), "RIGHT", "LEFT").Result()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, nil
@@ -64,50 +149,319 @@ func (tq *TaskQueue) Dequeue(ctx context.Context, queueName string, timeout time
 		return nil, fmt.Errorf("failed to dequeue task: %w", err)
 	}
 
-	if len(result) < 2 {
-		return nil, fmt.Errorf("invalid redis response")
+	return tq.claim(ctx, queueName, raw)
+}
+
+// claim leases a task just moved onto the processing list and decodes it
+func (tq *TaskQueue) claim(ctx context.Context, queueName, raw string) (*Task, error) {
+	if err := tq.client.ZAdd(ctx, leasesKey(queueName), &redis.Z{
+		Score:  leaseDeadline(tq.visibilityTimeout),
+		Member: raw,
+	}).Err(); err != nil {
+		// The reaper leases the task itself, so it is still redelivered
+		return nil, fmt.Errorf("failed to lease task: %w", err)
 	}
 
 	var task Task
-	if err := json.Unmarshal([]byte(result[1]), &task); err != nil {
+	if err := json.Unmarshal([]byte(raw), &task); err != nil {
+		// A task that cannot be decoded would be redelivered forever, so it is dead lettered as is
+		task.raw = raw
+		if moveErr := tq.move(ctx, queueName, &task, func(pipe redis.Pipeliner) {
+			pipe.LPush(ctx, deadKey(queueName), raw)
+		}); moveErr != nil {
+			return nil, fmt.Errorf("failed to dead letter malformed task: %w", moveErr)
+		}
 		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
 	}
+	task.raw = raw
 
 	return &task, nil
 }
 
+// Ack removes a handled task from the processing list so it is not delivered again
+func (tq *TaskQueue) Ack(ctx context.Context, queueName string, task *Task) error {
+	_, err := tq.client.TxPipelined(context.WithoutCancel(ctx), func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, processingKey(queueName), 1, task.raw)
+		pipe.ZRem(ctx, leasesKey(queueName), task.raw)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to ack task: %w", err)
+	}
+	return nil
+}
+
+// ExtendLease pushes back the lease deadline of a task that is still being handled
+func (tq *TaskQueue) ExtendLease(ctx context.Context, queueName string, task *Task) error {
+	return tq.client.ZAddXX(ctx, leasesKey(queueName), &redis.Z{
+		Score:  leaseDeadline(tq.visibilityTimeout),
+		Member: task.raw,
+	}).Err()
+}
+
+// RequeueExpired puts tasks whose lease expired back at the head of the queue and returns how many were requeued
+func (tq *TaskQueue) RequeueExpired(ctx context.Context, queueName string) (int64, error) {
+	keys := []string{processingKey(queueName), leasesKey(queueName), queueName}
+	requeued, err := requeueExpiredScript.Run(ctx, tq.client, keys, leaseDeadline(0), leaseDeadline(tq.visibilityTimeout)).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue expired tasks: %w", err)
+	}
+	return requeued, nil
+}
+
+// move acknowledges task and runs push, which stores it elsewhere, in a single transaction,
+// so a retried or dead lettered task is neither lost nor duplicated
+func (tq *TaskQueue) move(ctx context.Context, queueName string, task *Task, push func(pipe redis.Pipeliner)) error {
+	_, err := tq.client.TxPipelined(context.WithoutCancel(ctx), func(pipe redis.Pipeliner) error {
+		push(pipe)
+		pipe.LRem(ctx, processingKey(queueName), 1, task.raw)
+		pipe.ZRem(ctx, leasesKey(queueName), task.raw)
+		return nil
+	})
+	return err
+}
+
+// requeue acknowledges task and enqueues its updated state onto the destination queue
+func (tq *TaskQueue) requeue(ctx context.Context, queueName, destination string, task *Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	if err := tq.move(ctx, queueName, task, func(pipe redis.Pipeliner) {
+		pipe.LPush(ctx, destination, data)
+	}); err != nil {
+		return fmt.Errorf("failed to requeue task: %w", err)
+	}
+	return nil
+}
+
+// reschedule acknowledges task and schedules its updated state to run on queueName at runAt
+func (tq *TaskQueue) reschedule(ctx context.Context, queueName string, task *Task, runAt time.Time) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	if err := tq.move(ctx, queueName, task, func(pipe redis.Pipeliner) {
+		pipe.ZAdd(ctx, scheduledKey(queueName), &redis.Z{Score: float64(runAt.UnixMilli()), Member: data})
+	}); err != nil {
+		return fmt.Errorf("failed to reschedule task: %w", err)
+	}
+	return nil
+}
+
 func (tq *TaskQueue) GetQueueLength(ctx context.Context, queueName string) (int64, error) {
 	return tq.client.LLen(ctx, queueName).Result()
 }
 
+// GetProcessingLength returns the number of tasks dequeued from queueName but not yet acknowledged
+func (tq *TaskQueue) GetProcessingLength(ctx context.Context, queueName string) (int64, error) {
+	return tq.client.LLen(ctx, processingKey(queueName)).Result()
+}
+
+func processingKey(queueName string) string {
+	return queueName + "_processing"
+}
+
+func leasesKey(queueName string) string {
+	return queueName + "_leases"
+}
+
+func scheduledKey(queueName string) string {
+	return queueName + "_scheduled"
+}
+
+func deadKey(queueName string) string {
+	return queueName + "_dead"
+}
+
+// leaseDeadline returns the lease score, in Unix milliseconds, of a lease taken now for d
+func leaseDeadline(d time.Duration) float64 {
+	return float64(time.Now().Add(d).UnixMilli())
+}
+
 func (tp *TaskProcessor) RegisterHandler(taskType string, handler TaskHandler) {
 	tp.handlers[taskType] = handler
 }
 
-func (tp *TaskProcessor) ProcessTasks(ctx context.Context, queueName string) {
-	logger.Info("Starting task processor", logger.String("queue", queueName))
+// SetTypeConcurrency limits how many taskType tasks are handled at once, so slow task types cannot
+// occupy every worker. Tasks over the limit are put back and retried shortly. Call before Start.
+func (tp *TaskProcessor) SetTypeConcurrency(taskType string, limit int) {
+	if limit < 1 {
+		delete(tp.typeSlots, taskType)
+		return
+	}
+	tp.typeSlots[taskType] = make(chan struct{}, limit)
+}
+
+// SetQueueConcurrency limits how many tasks from queueName are handled at once, so a busy queue
+// cannot occupy every worker. Call before Start.
+func (tp *TaskProcessor) SetQueueConcurrency(queueName string, limit int) {
+	if limit < 1 {
+		delete(tp.queueSlots, queueName)
+		return
+	}
+	tp.queueSlots[queueName] = make(chan struct{}, limit)
+}
+
+// Start runs concurrency workers that poll every queue with a priority, plus a reaper and a
+// scheduled task promoter per queue. With strict priority workers always take tasks from the
+// highest priority queue that has any; otherwise queues are polled in a random order weighted by
+// priority, so lower priority queues are not starved. Workers stop taking tasks when ctx is
+// cancelled or Shutdown is called.
+func (tp *TaskProcessor) Start(ctx context.Context, concurrency int, strict bool) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	tp.strict = strict
+
+	logger.Info("Starting task processor",
+		logger.Any("queues", tp.queue.Queues()),
+		logger.Int("concurrency", concurrency),
+		logger.Any("strict_priority", strict),
+	)
+	for _, queueName := range tp.queue.Queues() {
+		go tp.reapExpiredTasks(ctx, queueName)
+		go tp.promoteScheduledTasks(ctx, queueName)
+	}
+
+	for i := 0; i < concurrency; i++ {
+		tp.workers.Add(1)
+		go tp.processTasks(ctx)
+	}
+}
+
+// Shutdown stops workers from taking new tasks and waits for in-flight handlers to return. If ctx
+// ends first, handler contexts are cancelled and ctx's error is returned; unfinished tasks are not
+// acknowledged, so they are delivered again once their lease expires.
+func (tp *TaskProcessor) Shutdown(ctx context.Context) error {
+	tp.stopOnce.Do(func() {
+		close(tp.stopping)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		tp.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("Task processor drained")
+		return nil
+	case <-ctx.Done():
+		tp.cancelHandlers()
+		return ctx.Err()
+	}
+}
+
+func (tp *TaskProcessor) processTasks(ctx context.Context) {
+	defer tp.workers.Done()
 	
 	for {
 		select {
 		case <-ctx.Done():
-			logger.Info("Task processor stopped", logger.String("queue", queueName))
+			logger.Info("Task processor stopped")
+			return
+		case <-tp.stopping:
 			return
 		default:
-			task, err := tp.queue.Dequeue(ctx, queueName, 5*time.Second)
+			if tp.processNext(ctx) {
+				continue
+			}
+
+			// Every queue is empty or at its limit
+			select {
+			case <-ctx.Done():
+			case <-tp.stopping:
+			case <-time.After(idlePollInterval):
+			}
+		}
+	}
+}
+
+// processNext handles one task from the first queue in poll order that has one, and reports whether it found any
+func (tp *TaskProcessor) processNext(ctx context.Context) bool {
+	for _, queueName := range tp.pollOrder() {
+		slots, limited := tp.queueSlots[queueName]
+		if limited {
+			select {
+			case slots <- struct{}{}:
+			default:
+				continue
+			}
+		}
+
+		task, err := tp.queue.TryDequeue(ctx, queueName)
+		if err != nil {
+			logger.Error("Error dequeuing task",
+				logger.String("queue", queueName),
+				logger.Error2("error", err),
+			)
+		}
+		if task == nil {
+			if limited {
+				<-slots
+			}
+			continue
+		}
+
+		if err := tp.processTask(tp.handlerCtx, queueName, task); err != nil {
+			logger.Error("Error processing task",
+				logger.String("task_id", task.ID),
+				logger.String("queue", queueName),
+				logger.Error2("error", err),
+			)
+		}
+		if limited {
+			<-slots
+		}
+		return true
+	}
+	return false
+}
+
+// reapExpiredTasks periodically requeues tasks whose worker stopped renewing the lease, e.g. because the process crashed
+func (tp *TaskProcessor) reapExpiredTasks(ctx context.Context, queueName string) {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			requeued, err := tp.queue.RequeueExpired(ctx, queueName)
 			if err != nil {
-				logger.Error("Error dequeuing task", 
+				logger.Error("Error requeuing expired tasks",
 					logger.String("queue", queueName),
 					logger.Error2("error", err),
 				)
 				continue
 			}
-
-			if task == nil {
-				continue
+			if requeued > 0 {
+				logger.Warn("Requeued tasks with expired leases",
+					logger.String("queue", queueName),
+					logger.Int64("count", requeued),
+				)
 			}
+		}
+	}
+}
+
+// keepLeased extends the lease of task until stop is closed
+func (tp *TaskProcessor) keepLeased(ctx context.Context, queueName string, task *Task, stop <-chan struct{}) {
+	ticker := time.NewTicker(tp.queue.visibilityTimeout / 3)
+	defer ticker.Stop()
 
-			if err := tp.processTask(ctx, queueName, task); err != nil {
-				logger.Error("Error processing task",
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := tp.queue.ExtendLease(ctx, queueName, task); err != nil {
+				logger.Warn("Failed to extend task lease",
 					logger.String("task_id", task.ID),
 					logger.String("queue", queueName),
 					logger.Error2("error", err),
@@ -120,34 +474,54 @@ func (tp *TaskProcessor) ProcessTasks(ctx context.Context, queueName string) {
 func (tp *TaskProcessor) processTask(ctx context.Context, queueName string, task *Task) error {
 	handler, exists := tp.handlers[task.Type]
 	if !exists {
+		if err := tp.queue.requeue(ctx, queueName, deadKey(queueName), task); err != nil {
+			return err
+		}
 		return fmt.Errorf("no handler registered for task type: %s", task.Type)
 	}
 
+	if slots, limited := tp.typeSlots[task.Type]; limited {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		default:
+			// Every slot of this type is busy; hand the task back instead of blocking a worker on it
+			return tp.queue.reschedule(ctx, queueName, task, time.Now().Add(typeConcurrencyRetryDelay))
+		}
+	}
+
 	logger.Info("Processing task",
 		logger.String("task_id", task.ID),
 		logger.String("task_type", task.Type),
 	)
 
-	if err := handler(ctx, task); err != nil {
+	stop := make(chan struct{})
+	go tp.keepLeased(ctx, queueName, task, stop)
+	err := handler(ctx, task)
+	close(stop)
+
+	if err != nil {
 		task.Retry++
 		if task.Retry < task.MaxRetry {
+			delay := retryBackoff(task.Retry)
 			logger.Warn("Task failed, retrying",
-			logger.String("task_id", task.ID),
-			logger.Int("retry", task.Retry),
-			logger.Int("max_retry", task.MaxRetry),
-		)
-			return tp.queue.Enqueue(ctx, queueName, task)
+				logger.String("task_id", task.ID),
+				logger.Int("retry", task.Retry),
+				logger.Int("max_retry", task.MaxRetry),
+				logger.Duration("delay", delay),
+			)
+			return tp.queue.reschedule(ctx, queueName, task, time.Now().Add(delay))
 		}
 		
 		logger.Error("Task failed after max retries, moving to dead letter queue",
 			logger.String("task_id", task.ID),
 			logger.Int("max_retry", task.MaxRetry),
 		)
-		return tp.queue.Enqueue(ctx, queueName+"_dead", task)
+		return tp.queue.requeue(ctx, queueName, deadKey(queueName), task)
 	}
 
 	logger.Info("Task completed successfully",
 		logger.String("task_id", task.ID),
 	)
-	return nil
+	return tp.queue.Ack(ctx, queueName, task)
 }
\ No newline at end of file
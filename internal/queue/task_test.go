@@ -0,0 +1,263 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestQueue(t *testing.T) (*TaskQueue, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewTaskQueue(client), mr
+}
+
+func newTestTask(taskType string) *Task {
+	return &Task{
+		ID:       "task-1",
+		Type:     taskType,
+		Payload:  map[string]interface{}{"key": "value"},
+		MaxRetry: 3,
+	}
+}
+
+func assertLength(t *testing.T, name string, got int64, err error, want int64) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if got != want {
+		t.Fatalf("%s = %d, want %d", name, got, want)
+	}
+}
+
+func TestDequeueAck(t *testing.T) {
+	ctx := context.Background()
+	q, _ := newTestQueue(t)
+
+	if err := q.Enqueue(ctx, "default", newTestTask("email")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	task, err := q.TryDequeue(ctx, "default")
+	if err != nil {
+		t.Fatalf("TryDequeue: %v", err)
+	}
+	if task == nil || task.ID != "task-1" || task.Payload["key"] != "value" {
+		t.Fatalf("TryDequeue returned %+v", task)
+	}
+
+	length, err := q.GetQueueLength(ctx, "default")
+	assertLength(t, "queue length", length, err, 0)
+	length, err = q.GetProcessingLength(ctx, "default")
+	assertLength(t, "processing length after dequeue", length, err, 1)
+
+	if err := q.Ack(ctx, "default", task); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	length, err = q.GetProcessingLength(ctx, "default")
+	assertLength(t, "processing length after ack", length, err, 0)
+
+	// An acknowledged task is not redelivered
+	requeued, err := q.RequeueExpired(ctx, "default")
+	assertLength(t, "requeued", requeued, err, 0)
+	task, err = q.TryDequeue(ctx, "default")
+	if err != nil || task != nil {
+		t.Fatalf("TryDequeue on empty queue = %+v, %v", task, err)
+	}
+}
+
+func TestRequeueExpired(t *testing.T) {
+	ctx := context.Background()
+	q, _ := newTestQueue(t)
+	q.visibilityTimeout = 50 * time.Millisecond
+
+	if err := q.Enqueue(ctx, "default", newTestTask("email")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := q.TryDequeue(ctx, "default"); err != nil {
+		t.Fatalf("TryDequeue: %v", err)
+	}
+
+	// The lease is still held, so nothing is requeued yet
+	requeued, err := q.RequeueExpired(ctx, "default")
+	assertLength(t, "requeued before lease expiry", requeued, err, 0)
+
+	time.Sleep(100 * time.Millisecond)
+
+	requeued, err = q.RequeueExpired(ctx, "default")
+	assertLength(t, "requeued after lease expiry", requeued, err, 1)
+	length, err := q.GetProcessingLength(ctx, "default")
+	assertLength(t, "processing length", length, err, 0)
+
+	task, err := q.TryDequeue(ctx, "default")
+	if err != nil {
+		t.Fatalf("TryDequeue: %v", err)
+	}
+	if task == nil || task.ID != "task-1" {
+		t.Fatalf("redelivered task = %+v", task)
+	}
+}
+
+func TestMalformedTaskDeadLettered(t *testing.T) {
+	ctx := context.Background()
+	q, mr := newTestQueue(t)
+
+	if _, err := mr.Lpush("default", "not json"); err != nil {
+		t.Fatalf("Lpush: %v", err)
+	}
+
+	task, err := q.TryDequeue(ctx, "default")
+	if err == nil {
+		t.Fatalf("TryDequeue returned %+v, want unmarshal error", task)
+	}
+
+	dead, err := mr.List(deadKey("default"))
+	if err != nil {
+		t.Fatalf("dead letter list: %v", err)
+	}
+	if len(dead) != 1 || dead[0] != "not json" {
+		t.Fatalf("dead letter list = %q", dead)
+	}
+	length, err := q.GetProcessingLength(ctx, "default")
+	assertLength(t, "processing length", length, err, 0)
+	if mr.Exists(leasesKey("default")) {
+		t.Fatal("lease of malformed task was not removed")
+	}
+}
+
+func TestFailedTaskRescheduled(t *testing.T) {
+	ctx := context.Background()
+	q, _ := newTestQueue(t)
+	tp := NewTaskProcessor(q)
+	tp.RegisterHandler("email", func(ctx context.Context, task *Task) error {
+		return errors.New("smtp unavailable")
+	})
+
+	if err := q.Enqueue(ctx, "default", newTestTask("email")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	task, err := q.TryDequeue(ctx, "default")
+	if err != nil {
+		t.Fatalf("TryDequeue: %v", err)
+	}
+
+	if err := tp.processTask(ctx, "default", task); err != nil {
+		t.Fatalf("processTask: %v", err)
+	}
+
+	length, err := q.GetProcessingLength(ctx, "default")
+	assertLength(t, "processing length", length, err, 0)
+	length, err = q.GetQueueLength(ctx, "default")
+	assertLength(t, "queue length", length, err, 0)
+	length, err = q.GetScheduledLength(ctx, "default")
+	assertLength(t, "scheduled length", length, err, 1)
+
+	// The retry is not due until its backoff has passed
+	promoted, err := q.PromoteDue(ctx, "default")
+	assertLength(t, "promoted", promoted, err, 0)
+
+	scheduled, err := q.client.ZRangeWithScores(ctx, scheduledKey("default"), 0, -1).Result()
+	if err != nil {
+		t.Fatalf("scheduled tasks: %v", err)
+	}
+	runAt := time.UnixMilli(int64(scheduled[0].Score))
+	if delay := time.Until(runAt); delay < retryBaseDelay/2-time.Second || delay > retryBaseDelay {
+		t.Fatalf("retry scheduled in %v, want between %v and %v", delay, retryBaseDelay/2, retryBaseDelay)
+	}
+}
+
+func TestFailedTaskDeadLetteredAfterMaxRetries(t *testing.T) {
+	ctx := context.Background()
+	q, mr := newTestQueue(t)
+	tp := NewTaskProcessor(q)
+	tp.RegisterHandler("email", func(ctx context.Context, task *Task) error {
+		return errors.New("smtp unavailable")
+	})
+
+	task := newTestTask("email")
+	task.Retry = task.MaxRetry - 1
+	if err := q.Enqueue(ctx, "default", task); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	task, err := q.TryDequeue(ctx, "default")
+	if err != nil {
+		t.Fatalf("TryDequeue: %v", err)
+	}
+
+	if err := tp.processTask(ctx, "default", task); err != nil {
+		t.Fatalf("processTask: %v", err)
+	}
+
+	length, err := q.GetScheduledLength(ctx, "default")
+	assertLength(t, "scheduled length", length, err, 0)
+	dead, err := mr.List(deadKey("default"))
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("dead letter list = %q, %v", dead, err)
+	}
+}
+
+func TestCriticalTaskHandledWhileLowQueueSaturated(t *testing.T) {
+	ctx := context.Background()
+	q, _ := newTestQueue(t)
+	q.SetQueuePriority("critical", 6)
+	q.SetQueuePriority(DefaultQueueName, 3)
+	q.SetQueuePriority("low", 1)
+
+	release := make(chan struct{})
+	emailHandled := make(chan struct{})
+	tp := NewTaskProcessor(q)
+	tp.RegisterHandler("data_processing", func(ctx context.Context, task *Task) error {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	tp.RegisterHandler("email", func(ctx context.Context, task *Task) error {
+		close(emailHandled)
+		return nil
+	})
+	tp.SetQueueConcurrency("low", 1)
+
+	for i := 0; i < 4; i++ {
+		task := newTestTask("data_processing")
+		task.ID = fmt.Sprintf("slow-%d", i)
+		if err := q.Enqueue(ctx, "low", task); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tp.Start(workerCtx, 4, false)
+	defer func() {
+		close(release)
+		shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := tp.Shutdown(shutdownCtx); err != nil {
+			t.Errorf("Shutdown: %v", err)
+		}
+	}()
+
+	// Let the workers pick up what they can from the low queue
+	time.Sleep(2 * idlePollInterval)
+	length, err := q.GetProcessingLength(ctx, "low")
+	assertLength(t, "tasks handled from low", length, err, 1)
+
+	if err := q.Enqueue(ctx, "critical", newTestTask("email")); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	select {
+	case <-emailHandled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("email task was not handled while the low queue was busy")
+	}
+}
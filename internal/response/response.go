@@ -95,6 +95,11 @@ func Conflict(c *gin.Context, message string) {
 	Error(c, http.StatusConflict, 4009, message)
 }
 
+// TooManyRequests sends a 429 too many requests response
+func TooManyRequests(c *gin.Context, message string) {
+	Error(c, http.StatusTooManyRequests, 4029, message)
+}
+
 // InternalServerError sends a 500 internal server error response
 func InternalServerError(c *gin.Context, message string) {
 	Error(c, http.StatusInternalServerError, 5000, message)
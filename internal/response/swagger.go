@@ -50,6 +50,13 @@ type ConflictResponse struct {
 	Message string `json:"message" example:"Resource already exists"`
 }
 
+// TooManyRequestsResponse represents a 429 Too Many Requests response
+// @Description Too Many Requests response format
+type TooManyRequestsResponse struct {
+	Code    int    `json:"code" example:"4029"`
+	Message string `json:"message" example:"Too many failed login attempts, please try again later"`
+}
+
 // InternalServerErrorResponse represents a 500 Internal Server Error response
 // @Description Internal Server Error response format
 type InternalServerErrorResponse struct {
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// auditExportBatchSize is the number of events loaded per query while exporting
+const auditExportBatchSize = 500
+
+// auditIgnoredFields are left out of before/after diffs because they change on every write
+var auditIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+type auditContextKey struct{}
+
+// auditMetadata describes the request an audited action happens in
+type auditMetadata struct {
+	actorID        *uint
+	impersonatorID *uint
+	requestID      string
+	ipAddress      string
+	userAgent      string
+}
+
+// WithAuditMetadata returns a copy of ctx carrying the request details recorded with audit events
+func WithAuditMetadata(ctx context.Context, requestID, ipAddress, userAgent string) context.Context {
+	meta := auditMetadataFrom(ctx)
+	meta.requestID = requestID
+	meta.ipAddress = ipAddress
+	meta.userAgent = userAgent
+	return context.WithValue(ctx, auditContextKey{}, &meta)
+}
+
+// WithAuditActor returns a copy of ctx recording userID as the actor of audit events
+func WithAuditActor(ctx context.Context, userID uint) context.Context {
+	meta := auditMetadataFrom(ctx)
+	meta.actorID = &userID
+	return context.WithValue(ctx, auditContextKey{}, &meta)
+}
+
+// WithAuditImpersonator returns a copy of ctx recording that the actor is being impersonated by impersonatorID
+func WithAuditImpersonator(ctx context.Context, impersonatorID uint) context.Context {
+	meta := auditMetadataFrom(ctx)
+	meta.impersonatorID = &impersonatorID
+	return context.WithValue(ctx, auditContextKey{}, &meta)
+}
+
+// auditMetadataFrom returns a copy of the audit metadata stored in ctx
+func auditMetadataFrom(ctx context.Context) auditMetadata {
+	if meta, ok := ctx.Value(auditContextKey{}).(*auditMetadata); ok {
+		return *meta
+	}
+	return auditMetadata{}
+}
+
+// AuditEntry describes an action to record. Before and After are the affected resource before
+// and after the action (either may be nil); only the fields that differ are stored.
+type AuditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   uint
+	Before     interface{}
+	After      interface{}
+}
+
+// AuditEventFilter narrows down the audit events returned by ListEvents and ExportEvents.
+// Zero values do not filter.
+type AuditEventFilter struct {
+	ActorID    uint
+	TargetType string
+	TargetID   uint
+	Action     string
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditService writes and queries the append-only audit log
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record writes an audit event for entry, taking the actor and request details from ctx.
+// Failures are logged rather than returned so that auditing never undoes a completed action.
+func (s *AuditService) Record(ctx context.Context, entry AuditEntry) {
+	meta := auditMetadataFrom(ctx)
+
+	before, after, err := auditDiff(entry.Before, entry.After)
+	if err != nil {
+		logger.Error("Failed to encode audit event",
+			logger.String("action", entry.Action),
+			logger.Uint("target_id", entry.TargetID),
+			logger.Error2("error", err),
+		)
+	}
+
+	event := &model.AuditEvent{
+		ActorID:        meta.actorID,
+		ImpersonatorID: meta.impersonatorID,
+		Action:         entry.Action,
+		TargetType:     entry.TargetType,
+		TargetID:       entry.TargetID,
+		Before:         before,
+		After:          after,
+		IPAddress:      meta.ipAddress,
+		UserAgent:      truncate(meta.userAgent, 255),
+		RequestID:      meta.requestID,
+	}
+
+	// The action has already happened, so the event is written even if the request was cancelled
+	if err := s.db.WithContext(context.WithoutCancel(ctx)).Create(event).Error; err != nil {
+		logger.Error("Failed to record audit event",
+			logger.String("action", entry.Action),
+			logger.String("target_type", entry.TargetType),
+			logger.Uint("target_id", entry.TargetID),
+			logger.String("request_id", meta.requestID),
+			logger.Error2("error", err),
+		)
+	}
+}
+
+// ListEvents lists audit events matching filter, newest first
+func (s *AuditService) ListEvents(ctx context.Context, filter *AuditEventFilter, limit, offset int) ([]*model.AuditEvent, int64, error) {
+	var events []*model.AuditEvent
+	var total int64
+
+	if err := s.query(ctx, filter).Model(&model.AuditEvent{}).Count(&total).Error; err != nil {
+		logger.Error("Failed to count audit events", logger.Error2("error", err))
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	if err := s.query(ctx, filter).Order("id DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		logger.Error("Failed to list audit events", logger.Error2("error", err))
+		return nil, 0, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, total, nil
+}
+
+// ExportEvents calls fn for every audit event matching filter, oldest first, loading them in batches
+func (s *AuditService) ExportEvents(ctx context.Context, filter *AuditEventFilter, fn func(*model.AuditEvent) error) error {
+	var batch []*model.AuditEvent
+	result := s.query(ctx, filter).FindInBatches(&batch, auditExportBatchSize, func(tx *gorm.DB, _ int) error {
+		for _, event := range batch {
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to export audit events: %w", result.Error)
+	}
+	return nil
+}
+
+// query builds the base query for filter
+func (s *AuditService) query(ctx context.Context, filter *AuditEventFilter) *gorm.DB {
+	query := s.db.WithContext(ctx)
+	if filter == nil {
+		return query
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
+
+// auditDiff encodes before and after as JSON objects. When both are given only the fields
+// whose values differ are kept.
+func auditDiff(before, after interface{}) (string, string, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return "", "", err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return "", "", err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for key, value := range beforeFields {
+			if other, ok := afterFields[key]; ok && reflect.DeepEqual(value, other) {
+				delete(beforeFields, key)
+				delete(afterFields, key)
+			}
+		}
+	}
+
+	beforeJSON, err := encodeAuditFields(beforeFields)
+	if err != nil {
+		return "", "", err
+	}
+	afterJSON, err := encodeAuditFields(afterFields)
+	if err != nil {
+		return "", "", err
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+// auditFields converts v into a map of its JSON fields. Fields hidden from JSON, such as
+// password hashes and secrets, are therefore never stored.
+func auditFields(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key := range auditIgnoredFields {
+		delete(fields, key)
+	}
+	return fields, nil
+}
+
+func encodeAuditFields(fields map[string]interface{}) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -11,21 +11,32 @@ import (
 	"linke/internal/logger"
 	"linke/internal/model"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type AuthService struct {
-	db               *gorm.DB
-	userService      *UserService
-	jwtService       *JWTService
-	inviteCodeService *InviteCodeService
+	db                  *gorm.DB
+	userService         *UserService
+	jwtService          *JWTService
+	inviteCodeService   *InviteCodeService
+	refreshTokenService *RefreshTokenService
+	revocationService   *TokenRevocationService
+	sessionService      *SessionService
+	mfaService          *MFAService
+	passkeyService      *PasskeyService
+	emailVerification   *EmailVerificationService
+	magicLinkService    *MagicLinkService
+	loginGuard          *LoginGuardService
+	passwordPolicy      *PasswordPolicyService
+	passwordHasher      PasswordHasher
+	accessTokens        *PersonalAccessTokenService
+	auditService        *AuditService
 }
 
 type RegisterRequest struct {
 	Email      string `json:"email" binding:"required,email"`
-	Password   string `json:"password" binding:"required,min=6"`
-	InviteCode string `json:"invite_code"` // Optional invite code
+	Password   string `json:"password" binding:"required"` // Checked against the password policy
+	InviteCode string `json:"invite_code"`                 // Optional invite code
 }
 
 type LoginRequest struct {
@@ -33,35 +44,53 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"` // Optional, revokes the refresh token family as well
+}
+
+// AuthResponse is returned by the login endpoints. When the account has two-factor
+// authentication enabled, only MFARequired and MFAToken are set and the MFA token
+// must be exchanged at /auth/mfa/verify. When email verification is required,
+// registration returns the user without a token and EmailVerificationRequired set.
 type AuthResponse struct {
-	User  *model.UserResponse `json:"user"`
-	Token *TokenResponse      `json:"token"`
+	User                      *model.UserResponse `json:"user,omitempty"`
+	Token                     *TokenResponse      `json:"token,omitempty"`
+	MFARequired               bool                `json:"mfa_required,omitempty"`
+	MFAToken                  string              `json:"mfa_token,omitempty"`
+	EmailVerificationRequired bool                `json:"email_verification_required,omitempty"`
 }
 
-func NewAuthService(db *gorm.DB, userService *UserService, jwtService *JWTService, inviteCodeService *InviteCodeService) *AuthService {
+func NewAuthService(db *gorm.DB, userService *UserService, jwtService *JWTService, inviteCodeService *InviteCodeService, refreshTokenService *RefreshTokenService, revocationService *TokenRevocationService, sessionService *SessionService, mfaService *MFAService, passkeyService *PasskeyService, emailVerification *EmailVerificationService, magicLinkService *MagicLinkService, loginGuard *LoginGuardService, passwordPolicy *PasswordPolicyService, passwordHasher PasswordHasher, accessTokens *PersonalAccessTokenService, auditService *AuditService) *AuthService {
 	return &AuthService{
-		db:               db,
-		userService:      userService,
-		jwtService:       jwtService,
-		inviteCodeService: inviteCodeService,
+		db:                  db,
+		userService:         userService,
+		jwtService:          jwtService,
+		inviteCodeService:   inviteCodeService,
+		refreshTokenService: refreshTokenService,
+		revocationService:   revocationService,
+		sessionService:      sessionService,
+		mfaService:          mfaService,
+		passkeyService:      passkeyService,
+		emailVerification:   emailVerification,
+		magicLinkService:    magicLinkService,
+		loginGuard:          loginGuard,
+		passwordPolicy:      passwordPolicy,
+		passwordHasher:      passwordHasher,
+		accessTokens:        accessTokens,
+		auditService:        auditService,
 	}
 }
 
 // Register creates a new user account with email and password
-func (a *AuthService) Register(ctx context.Context, req *RegisterRequest) (*AuthResponse, error) {
+func (a *AuthService) Register(ctx context.Context, req *RegisterRequest, client ClientInfo) (*AuthResponse, error) {
 	// Validate invite code if provided
-	var inviteCode *model.InviteCode
-	if req.InviteCode != "" {
-		validatedCode, err := a.inviteCodeService.ValidateInviteCode(ctx, req.InviteCode)
-		if err != nil {
-			logger.Warn("Invalid invite code used during registration",
-				logger.String("email", req.Email),
-				logger.String("invite_code", req.InviteCode),
-				logger.Error2("error", err),
-			)
-			return nil, fmt.Errorf("invalid invite code: %s", err.Error())
-		}
-		inviteCode = validatedCode
+	inviteCode, err := a.validateInviteCode(ctx, req.Email, req.InviteCode)
+	if err != nil {
+		return nil, err
 	}
 
 	// Check if user already exists
@@ -70,71 +99,49 @@ func (a *AuthService) Register(ctx context.Context, req *RegisterRequest) (*Auth
 		return nil, fmt.Errorf("user with email %s already exists", req.Email)
 	}
 
+	// Enforce the password policy
+	if err := a.passwordPolicy.Validate(req.Password); err != nil {
+		return nil, err
+	}
+
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := a.passwordHasher.Hash(req.Password)
 	if err != nil {
 		logger.Error("Failed to hash password", logger.Error2("error", err))
 		return nil, fmt.Errorf("failed to process password")
 	}
 
-	// Generate username and name from email
-	emailParts := strings.Split(req.Email, "@")
-	baseUsername := emailParts[0]
-	
-	// Generate a unique username by adding random numbers if needed
-	username := a.generateUniqueUsername(ctx, baseUsername)
-	
-	// Generate name from email (capitalize first letter of username)
-	name := baseUsername
-	if len(baseUsername) > 0 {
-		name = strings.ToUpper(string(baseUsername[0])) + baseUsername[1:]
-	}
-
-	// Create user
 	user := &model.User{
 		Email:    req.Email,
-		Name:     name,
-		Username: username,
-		Password: string(hashedPassword),
-		Provider: model.ProviderLocal,
-		Status:   model.UserStatusActive,
+		Password: hashedPassword,
 	}
-
-	// Set invite code information if provided
-	if inviteCode != nil {
-		user.InviteCodeID = &inviteCode.ID
-		user.InviteCodeUsed = &inviteCode.Code
+	if err := a.createLocalUser(ctx, user, inviteCode, client); err != nil {
+		return nil, err
 	}
 
-	if err := a.userService.CreateUser(ctx, user); err != nil {
-		logger.Error("Failed to create user during registration",
-			logger.String("email", req.Email),
+	// Send the verification email; a failure here can be recovered with the resend endpoint
+	if err := a.emailVerification.SendVerification(ctx, user); err != nil {
+		logger.Error("Failed to send verification email during registration",
+			logger.Uint("user_id", user.ID),
 			logger.Error2("error", err),
 		)
-		return nil, fmt.Errorf("failed to create user account")
 	}
 
-	// Use the invite code if provided
-	if inviteCode != nil {
-		// Get IP address and user agent from context (can be enhanced later)
-		ipAddress := "unknown"
-		userAgent := "unknown"
-		
-		_, err := a.inviteCodeService.UseInviteCode(ctx, inviteCode.Code, user.ID, ipAddress, userAgent)
-		if err != nil {
-			logger.Error("Failed to use invite code during registration",
-				logger.String("email", req.Email),
-				logger.String("invite_code", inviteCode.Code),
-				logger.Uint("user_id", user.ID),
-				logger.Error2("error", err),
-			)
-			// Note: We don't return error here to avoid failing registration
-			// if invite code usage fails after user creation
-		}
+	// Hold back tokens until the email address has been verified
+	if a.emailVerification.IsRequired() {
+		logger.Info("User registered, waiting for email verification",
+			logger.Uint("user_id", user.ID),
+			logger.String("email", user.Email),
+		)
+
+		return &AuthResponse{
+			User:                      user.ToResponse(),
+			EmailVerificationRequired: true,
+		}, nil
 	}
 
-	// Generate JWT token
-	token, err := a.jwtService.GenerateToken(user)
+	// Generate access and refresh tokens
+	token, err := a.IssueTokens(ctx, user, model.ProviderLocal, client)
 	if err != nil {
 		logger.Error("Failed to generate token for new user",
 			logger.Uint("user_id", user.ID),
@@ -155,14 +162,23 @@ func (a *AuthService) Register(ctx context.Context, req *RegisterRequest) (*Auth
 }
 
 // Login authenticates a user with email and password
-func (a *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthResponse, error) {
+func (a *AuthService) Login(ctx context.Context, req *LoginRequest, client ClientInfo) (*AuthResponse, error) {
+	// Refuse locked out emails and client IPs before touching the password
+	if err := a.loginGuard.Check(ctx, req.Email, client.IPAddress); err != nil {
+		logger.Warn("Login attempt while locked out",
+			logger.String("email", req.Email),
+			logger.String("ip_address", client.IPAddress),
+		)
+		return nil, err
+	}
+
 	// Get user by email (first check without status filter for better error messages)
 	user, err := a.userService.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		logger.Warn("Login attempt with non-existent email",
 			logger.String("email", req.Email),
 		)
-		return nil, fmt.Errorf("invalid email or password")
+		return nil, a.loginFailed(ctx, req.Email, client)
 	}
 
 	// Check if user is using local authentication
@@ -184,16 +200,28 @@ func (a *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthRespon
 	}
 
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	if ok, err := a.passwordHasher.Verify(user.Password, req.Password); err != nil || !ok {
 		logger.Warn("Failed login attempt with incorrect password",
 			logger.String("email", req.Email),
 			logger.Uint("user_id", user.ID),
 		)
-		return nil, fmt.Errorf("invalid email or password")
+		return nil, a.loginFailed(ctx, req.Email, client)
+	}
+	a.upgradePasswordHash(ctx, user, req.Password)
+
+	if err := a.checkEmailVerified(user); err != nil {
+		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := a.jwtService.GenerateToken(user)
+	// Hold back tokens until the second factor has been verified. Failures are only reset once
+	// VerifyMFA succeeds, so repeated logins cannot be used to get unlimited code guesses.
+	if user.IsTOTPEnabled() {
+		return a.mfaChallenge(user)
+	}
+	a.loginGuard.Reset(ctx, req.Email)
+
+	// Generate access and refresh tokens
+	token, err := a.IssueTokens(ctx, user, model.ProviderLocal, client)
 	if err != nil {
 		logger.Error("Failed to generate token during login",
 			logger.Uint("user_id", user.ID),
@@ -213,6 +241,356 @@ func (a *AuthService) Login(ctx context.Context, req *LoginRequest) (*AuthRespon
 	}, nil
 }
 
+// VerifyMFA completes a two-step login by exchanging a pending MFA token and a valid
+// TOTP or recovery code for access and refresh tokens. The MFA token can only be used once.
+func (a *AuthService) VerifyMFA(ctx context.Context, req *MFAVerifyRequest, client ClientInfo) (*AuthResponse, error) {
+	claims, err := a.jwtService.ValidateActionToken(req.MFAToken, TokenPurposeMFA)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired MFA token")
+	}
+
+	revoked, err := a.revocationService.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		logger.Error("Failed to check MFA token revocation",
+			logger.Uint("user_id", claims.UserID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to verify MFA token")
+	}
+	if revoked {
+		return nil, fmt.Errorf("invalid or expired MFA token")
+	}
+
+	user, err := a.userService.GetActiveUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found or inactive")
+	}
+
+	// Wrong codes count against the same lockout as wrong passwords
+	if err := a.loginGuard.Check(ctx, user.Email, client.IPAddress); err != nil {
+		logger.Warn("MFA verification while locked out",
+			logger.Uint("user_id", user.ID),
+			logger.String("ip_address", client.IPAddress),
+		)
+		return nil, err
+	}
+
+	if err := a.mfaService.VerifyCode(ctx, user, req.Code); err != nil {
+		logger.Warn("Failed MFA verification",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+
+		if lockoutErr := a.loginGuard.RegisterFailure(ctx, user.Email, client.IPAddress); lockoutErr != nil {
+			if revokeErr := a.revocationService.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); revokeErr != nil {
+				logger.Error("Failed to revoke MFA token",
+					logger.Uint("user_id", user.ID),
+					logger.Error2("error", revokeErr),
+				)
+			}
+			return nil, lockoutErr
+		}
+
+		exhausted, attemptErr := a.mfaService.RegisterFailedAttempt(ctx, claims.ID, claims.ExpiresAt.Time)
+		if attemptErr != nil || exhausted {
+			if revokeErr := a.revocationService.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); revokeErr != nil {
+				logger.Error("Failed to revoke MFA token",
+					logger.Uint("user_id", user.ID),
+					logger.Error2("error", revokeErr),
+				)
+			}
+			return nil, fmt.Errorf("too many failed attempts, please log in again")
+		}
+		return nil, err
+	}
+
+	// The MFA token is single-use; claiming it atomically stops concurrent requests from both succeeding
+	claimed, err := a.revocationService.Claim(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		logger.Error("Failed to claim MFA token",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to verify MFA token")
+	}
+	if !claimed {
+		return nil, fmt.Errorf("invalid or expired MFA token")
+	}
+	a.loginGuard.Reset(ctx, user.Email)
+
+	token, err := a.IssueTokens(ctx, user, model.ProviderLocal, client)
+	if err != nil {
+		logger.Error("Failed to generate token after MFA verification",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to generate authentication token")
+	}
+
+	logger.Info("User logged in successfully with second factor",
+		logger.Uint("user_id", user.ID),
+		logger.String("email", user.Email),
+	)
+
+	return &AuthResponse{
+		User:  user.ToResponse(),
+		Token: token,
+	}, nil
+}
+
+// LoginWithPasskey completes a passwordless login ceremony. Passkey logins require user
+// verification on the authenticator, so they satisfy two-factor authentication on their own.
+func (a *AuthService) LoginWithPasskey(ctx context.Context, req *PasskeyLoginFinishRequest, client ClientInfo) (*AuthResponse, error) {
+	user, err := a.passkeyService.FinishLogin(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check user status
+	if !user.IsActive() {
+		logger.Warn("Passkey login attempt for inactive user",
+			logger.Uint("user_id", user.ID),
+			logger.String("status", user.Status),
+		)
+		return nil, fmt.Errorf("account is %s. Please contact support", user.Status)
+	}
+
+	if err := a.checkEmailVerified(user); err != nil {
+		return nil, err
+	}
+
+	token, err := a.IssueTokens(ctx, user, model.SessionProviderPasskey, client)
+	if err != nil {
+		logger.Error("Failed to generate token during passkey login",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to generate authentication token")
+	}
+
+	logger.Info("User logged in successfully with passkey",
+		logger.Uint("user_id", user.ID),
+		logger.String("email", user.Email),
+	)
+
+	return &AuthResponse{
+		User:  user.ToResponse(),
+		Token: token,
+	}, nil
+}
+
+// LoginWithMagicLink redeems a one-time login link. If no account exists for the link's
+// address yet, one is created with the same invite code handling as Register.
+// Accounts with two-factor authentication still have to complete /auth/mfa/verify.
+func (a *AuthService) LoginWithMagicLink(ctx context.Context, req *MagicLinkVerifyRequest, client ClientInfo) (*AuthResponse, error) {
+	claims, err := a.magicLinkService.Redeem(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.userService.GetUserByEmail(ctx, claims.Email)
+	if err != nil {
+		inviteCode, err := a.validateInviteCode(ctx, claims.Email, req.InviteCode)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		user = &model.User{
+			Email:           claims.Email,
+			EmailVerifiedAt: &now,
+		}
+		if err := a.createLocalUser(ctx, user, inviteCode, client); err != nil {
+			return nil, err
+		}
+	} else {
+		// A link issued to an existing account must not log into a different one
+		if claims.UserID != 0 && claims.UserID != user.ID {
+			return nil, fmt.Errorf("invalid or expired login link")
+		}
+
+		if !user.IsLocalAccount() {
+			return nil, fmt.Errorf("this account uses %s authentication. Please use the appropriate login method", user.Provider)
+		}
+
+		if !user.IsActive() {
+			logger.Warn("Magic link login attempt for inactive user",
+				logger.Uint("user_id", user.ID),
+				logger.String("status", user.Status),
+			)
+			return nil, fmt.Errorf("account is %s. Please contact support", user.Status)
+		}
+
+		// Opening the link proves ownership of the address
+		if !user.IsEmailVerified() {
+			now := time.Now()
+			if err := a.db.WithContext(ctx).Model(user).Update("email_verified_at", now).Error; err != nil {
+				logger.Error("Failed to mark email as verified during magic link login",
+					logger.Uint("user_id", user.ID),
+					logger.Error2("error", err),
+				)
+			} else {
+				user.EmailVerifiedAt = &now
+			}
+		}
+
+		if user.IsTOTPEnabled() {
+			return a.mfaChallenge(user)
+		}
+	}
+
+	token, err := a.IssueTokens(ctx, user, model.SessionProviderMagicLink, client)
+	if err != nil {
+		logger.Error("Failed to generate token during magic link login",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to generate authentication token")
+	}
+
+	logger.Info("User logged in successfully with magic link",
+		logger.Uint("user_id", user.ID),
+		logger.String("email", user.Email),
+	)
+
+	return &AuthResponse{
+		User:  user.ToResponse(),
+		Token: token,
+	}, nil
+}
+
+// LoginWithIdentity completes a login with an external provider account that has already been
+// verified and resolved to user. provider records the login method on the session. Accounts
+// with two-factor authentication get an MFA challenge, as with a password login.
+func (a *AuthService) LoginWithIdentity(ctx context.Context, user *model.User, provider string, client ClientInfo) (*AuthResponse, error) {
+	if !user.IsActive() {
+		logger.Warn("Provider login attempt for inactive user",
+			logger.Uint("user_id", user.ID),
+			logger.String("provider", provider),
+			logger.String("status", user.Status),
+		)
+		return nil, fmt.Errorf("account is %s. Please contact support", user.Status)
+	}
+
+	if user.IsTOTPEnabled() {
+		return a.mfaChallenge(user)
+	}
+
+	token, err := a.IssueTokens(ctx, user, provider, client)
+	if err != nil {
+		logger.Error("Failed to generate token during provider login",
+			logger.Uint("user_id", user.ID),
+			logger.String("provider", provider),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to generate authentication token")
+	}
+
+	return &AuthResponse{
+		User:  user.ToResponse(),
+		Token: token,
+	}, nil
+}
+
+// IssueTokens starts a new session for the user and returns its access and refresh tokens.
+// provider records the login method used to create the session.
+func (a *AuthService) IssueTokens(ctx context.Context, user *model.User, provider string, client ClientInfo) (*TokenResponse, error) {
+	session, err := a.sessionService.CreateSession(ctx, user.ID, provider, client)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.jwtService.GenerateToken(user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, _, err := a.refreshTokenService.Issue(ctx, user.ID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	token.RefreshToken = refreshToken
+
+	return token, nil
+}
+
+// RefreshToken rotates a refresh token and issues a new access token for its session
+func (a *AuthService) RefreshToken(ctx context.Context, rawRefreshToken string, client ClientInfo) (*TokenResponse, error) {
+	newRefreshToken, record, err := a.refreshTokenService.Rotate(ctx, rawRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := a.sessionService.GetActiveSession(ctx, record.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("session has been revoked")
+	}
+
+	user, err := a.userService.GetActiveUserByID(ctx, record.UserID)
+	if err != nil {
+		if revokeErr := a.refreshTokenService.RevokeFamily(ctx, record.FamilyID); revokeErr != nil {
+			logger.Error("Failed to revoke refresh token family for inactive user",
+				logger.Uint("user_id", record.UserID),
+				logger.Error2("error", revokeErr),
+			)
+		}
+		return nil, fmt.Errorf("user not found or inactive")
+	}
+
+	token, err := a.jwtService.GenerateToken(user, session)
+	if err != nil {
+		logger.Error("Failed to generate token during refresh",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to generate authentication token")
+	}
+	token.RefreshToken = newRefreshToken
+
+	a.sessionService.Touch(ctx, session, client.IPAddress)
+
+	logger.Info("Token refreshed successfully",
+		logger.Uint("user_id", user.ID),
+		logger.Uint("session_id", session.ID),
+	)
+
+	return token, nil
+}
+
+// ReissueAccessToken replaces the presented access token with a new one for the same session,
+// picking up changes to the session such as its active organization. The refresh token stays valid.
+func (a *AuthService) ReissueAccessToken(ctx context.Context, user *model.User, claims *Claims) (*TokenResponse, error) {
+	session, err := a.sessionService.GetActiveSession(ctx, claims.SessionID)
+	if err != nil || session.UserID != user.ID {
+		return nil, fmt.Errorf("session has been revoked")
+	}
+
+	// Impersonation tokens keep their actor and expiry so they cannot be turned into regular tokens
+	var token *TokenResponse
+	if claims.IsImpersonation() {
+		token, err = a.jwtService.GenerateImpersonationToken(user, session, claims.Act, claims.ExpiresAt.Time)
+	} else {
+		token, err = a.jwtService.GenerateToken(user, session)
+	}
+	if err != nil {
+		logger.Error("Failed to reissue access token",
+			logger.Uint("user_id", user.ID),
+			logger.Uint("session_id", session.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to generate authentication token")
+	}
+
+	if err := a.revocationService.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		logger.Warn("Failed to revoke replaced access token",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+	}
+
+	return token, nil
+}
+
 // ChangePassword changes a user's password
 func (a *AuthService) ChangePassword(ctx context.Context, userID uint, oldPassword, newPassword string) error {
 	user, err := a.userService.GetUserByID(ctx, userID)
@@ -226,15 +604,23 @@ func (a *AuthService) ChangePassword(ctx context.Context, userID uint, oldPasswo
 	}
 
 	// Verify old password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+	if ok, err := a.passwordHasher.Verify(user.Password, oldPassword); err != nil || !ok {
 		logger.Warn("Password change attempt with incorrect old password",
 			logger.Uint("user_id", userID),
 		)
 		return fmt.Errorf("current password is incorrect")
 	}
 
+	// Enforce the password policy
+	if err := a.passwordPolicy.Validate(newPassword); err != nil {
+		return err
+	}
+	if err := a.passwordPolicy.CheckReuse(ctx, user, newPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashedPassword, err := a.passwordHasher.Hash(newPassword)
 	if err != nil {
 		logger.Error("Failed to hash new password",
 			logger.Uint("user_id", userID),
@@ -243,9 +629,14 @@ func (a *AuthService) ChangePassword(ctx context.Context, userID uint, oldPasswo
 		return fmt.Errorf("failed to process new password")
 	}
 
-	// Update password
-	user.Password = string(hashedPassword)
-	if err := a.userService.UpdateUser(ctx, user); err != nil {
+	// Update password and keep the old one in the history
+	err = a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := a.passwordPolicy.Remember(tx, user.ID, user.Password); err != nil {
+			return err
+		}
+		return tx.Model(user).Update("password", hashedPassword).Error
+	})
+	if err != nil {
 		logger.Error("Failed to update password",
 			logger.Uint("user_id", userID),
 			logger.Error2("error", err),
@@ -256,24 +647,280 @@ func (a *AuthService) ChangePassword(ctx context.Context, userID uint, oldPasswo
 	logger.Info("Password changed successfully",
 		logger.Uint("user_id", userID),
 	)
+	a.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserPasswordChange,
+		TargetType: model.AuditTargetUser,
+		TargetID:   userID,
+	})
 
 	return nil
 }
 
-// ValidateToken validates a JWT token and returns user info
-func (a *AuthService) ValidateToken(tokenString string) (*model.User, error) {
+// ValidateToken validates a JWT token and returns user info along with the token claims
+func (a *AuthService) ValidateToken(tokenString string) (*model.User, *Claims, error) {
+	ctx := context.Background()
+
 	claims, err := a.jwtService.ValidateToken(tokenString)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	revoked, err := a.revocationService.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		logger.Error("Failed to check token revocation",
+			logger.Uint("user_id", claims.UserID),
+			logger.Error2("error", err),
+		)
+		return nil, nil, fmt.Errorf("failed to validate token")
+	}
+	if revoked {
+		return nil, nil, fmt.Errorf("token has been revoked")
 	}
 
 	// Get fresh user data from database (only active users)
-	user, err := a.userService.GetActiveUserByID(context.Background(), claims.UserID)
+	user, err := a.userService.GetActiveUserByID(ctx, claims.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found or inactive")
+		return nil, nil, fmt.Errorf("user not found or inactive")
+	}
+
+	if claims.TokenVersion != user.TokenVersion {
+		return nil, nil, fmt.Errorf("token has been revoked")
+	}
+
+	// Impersonation ends as soon as the admin can no longer sign in themselves
+	if claims.IsImpersonation() {
+		if _, err := a.userService.GetActiveUserByID(ctx, claims.Act.UserID); err != nil {
+			return nil, nil, fmt.Errorf("impersonating user not found or inactive")
+		}
+	}
+
+	session, err := a.sessionService.GetActiveSession(ctx, claims.SessionID)
+	if err != nil || session.UserID != user.ID {
+		return nil, nil, fmt.Errorf("session has been revoked")
 	}
+	a.sessionService.Touch(ctx, session, "")
+
+	return user, claims, nil
+}
+
+// ValidateAPIToken validates a personal access token and returns its owner along with the token,
+// whose scopes limit what the request may do
+func (a *AuthService) ValidateAPIToken(ctx context.Context, rawToken, ipAddress string) (*model.User, *model.PersonalAccessToken, error) {
+	token, err := a.accessTokens.Authenticate(ctx, rawToken, ipAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := a.userService.GetActiveUserByID(ctx, token.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("user not found or inactive")
+	}
+
+	return user, token, nil
+}
 
-	return user, nil
+// Logout revokes the presented access token and its session, and, if given, the refresh token family it came with
+func (a *AuthService) Logout(ctx context.Context, claims *Claims, rawRefreshToken string) error {
+	if err := a.revocationService.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		logger.Error("Failed to revoke access token on logout",
+			logger.Uint("user_id", claims.UserID),
+			logger.Error2("error", err),
+		)
+		return fmt.Errorf("failed to revoke access token")
+	}
+
+	if err := a.sessionService.RevokeSession(ctx, claims.UserID, claims.SessionID); err != nil {
+		logger.Warn("Failed to revoke session on logout",
+			logger.Uint("user_id", claims.UserID),
+			logger.Uint("session_id", claims.SessionID),
+			logger.Error2("error", err),
+		)
+	}
+
+	if rawRefreshToken != "" {
+		if err := a.refreshTokenService.Revoke(ctx, rawRefreshToken); err != nil {
+			logger.Warn("Failed to revoke refresh token on logout",
+				logger.Uint("user_id", claims.UserID),
+				logger.Error2("error", err),
+			)
+		}
+	}
+
+	logger.Info("User logged out",
+		logger.Uint("user_id", claims.UserID),
+	)
+	return nil
+}
+
+// LogoutAll invalidates every access and refresh token issued to the user
+func (a *AuthService) LogoutAll(ctx context.Context, userID uint) error {
+	if err := a.userService.BumpTokenVersion(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens")
+	}
+
+	if err := a.sessionService.RevokeAllSessions(ctx, userID); err != nil {
+		logger.Error("Failed to revoke sessions on logout-all",
+			logger.Uint("user_id", userID),
+			logger.Error2("error", err),
+		)
+		return fmt.Errorf("failed to revoke sessions")
+	}
+
+	logger.Info("User logged out of all sessions",
+		logger.Uint("user_id", userID),
+	)
+	a.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserLogoutAll,
+		TargetType: model.AuditTargetUser,
+		TargetID:   userID,
+	})
+	return nil
+}
+
+// upgradePasswordHash rehashes a just-verified password when its stored hash uses an
+// outdated algorithm or weaker parameters. Failures are logged and retried on the next login.
+func (a *AuthService) upgradePasswordHash(ctx context.Context, user *model.User, password string) {
+	if !a.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := a.passwordHasher.Hash(password)
+	if err != nil {
+		logger.Error("Failed to rehash password",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return
+	}
+
+	// Only replace the hash that was verified, in case the password changed concurrently
+	result := a.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Update("password", hashedPassword)
+	if result.Error != nil {
+		logger.Error("Failed to store upgraded password hash",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", result.Error),
+		)
+		return
+	}
+
+	user.Password = hashedPassword
+	logger.Info("Password hash upgraded",
+		logger.Uint("user_id", user.ID),
+	)
+}
+
+// loginFailed records a failed password login and returns the error to report to the client,
+// which is a *LockoutError once the failure triggered a lockout
+func (a *AuthService) loginFailed(ctx context.Context, email string, client ClientInfo) error {
+	if err := a.loginGuard.RegisterFailure(ctx, email, client.IPAddress); err != nil {
+		return err
+	}
+	return fmt.Errorf("invalid email or password")
+}
+
+// mfaChallenge holds back tokens for an account with two-factor authentication and
+// returns a short-lived MFA token to be exchanged at /auth/mfa/verify instead
+func (a *AuthService) mfaChallenge(user *model.User) (*AuthResponse, error) {
+	mfaToken, _, err := a.jwtService.GenerateActionToken(user, TokenPurposeMFA, mfaTokenTTL)
+	if err != nil {
+		logger.Error("Failed to generate MFA token during login",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to generate authentication token")
+	}
+
+	logger.Info("First factor verified, waiting for second factor",
+		logger.Uint("user_id", user.ID),
+	)
+
+	return &AuthResponse{
+		MFARequired: true,
+		MFAToken:    mfaToken,
+	}, nil
+}
+
+// validateInviteCode validates the invite code given for a new account, returning nil when no code was given
+func (a *AuthService) validateInviteCode(ctx context.Context, email, code string) (*model.InviteCode, error) {
+	if code == "" {
+		return nil, nil
+	}
+
+	inviteCode, err := a.inviteCodeService.ValidateInviteCode(ctx, code)
+	if err != nil {
+		logger.Warn("Invalid invite code used during registration",
+			logger.String("email", email),
+			logger.String("invite_code", code),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("invalid invite code: %s", err.Error())
+	}
+	return inviteCode, nil
+}
+
+// createLocalUser fills in the generated profile fields of a new local account, saves it
+// and records the use of its invite code, if any
+func (a *AuthService) createLocalUser(ctx context.Context, user *model.User, inviteCode *model.InviteCode, client ClientInfo) error {
+	// Generate username and name from email
+	emailParts := strings.Split(user.Email, "@")
+	baseUsername := emailParts[0]
+
+	// Generate a unique username by adding random numbers if needed
+	user.Username = a.generateUniqueUsername(ctx, baseUsername)
+
+	// Generate name from email (capitalize first letter of username)
+	user.Name = baseUsername
+	if len(baseUsername) > 0 {
+		user.Name = strings.ToUpper(string(baseUsername[0])) + baseUsername[1:]
+	}
+
+	user.Provider = model.ProviderLocal
+	user.Status = model.UserStatusActive
+
+	// Set invite code information if provided
+	if inviteCode != nil {
+		user.InviteCodeID = &inviteCode.ID
+		user.InviteCodeUsed = &inviteCode.Code
+	}
+
+	if err := a.userService.CreateUser(ctx, user); err != nil {
+		logger.Error("Failed to create user during registration",
+			logger.String("email", user.Email),
+			logger.Error2("error", err),
+		)
+		return fmt.Errorf("failed to create user account")
+	}
+
+	// Use the invite code if provided
+	if inviteCode != nil {
+		_, err := a.inviteCodeService.UseInviteCode(ctx, inviteCode.Code, user.ID, client.IPAddress, client.UserAgent)
+		if err != nil {
+			logger.Error("Failed to use invite code during registration",
+				logger.String("email", user.Email),
+				logger.String("invite_code", inviteCode.Code),
+				logger.Uint("user_id", user.ID),
+				logger.Error2("error", err),
+			)
+			// Note: We don't return error here to avoid failing registration
+			// if invite code usage fails after user creation
+		}
+	}
+
+	return nil
+}
+
+// checkEmailVerified rejects local accounts with an unverified email when verification is required
+func (a *AuthService) checkEmailVerified(user *model.User) error {
+	if !a.emailVerification.IsRequired() || !user.IsLocalAccount() || user.IsEmailVerified() {
+		return nil
+	}
+
+	logger.Warn("Login attempt with unverified email",
+		logger.Uint("user_id", user.ID),
+	)
+	return fmt.Errorf("email address has not been verified")
 }
 
 // generateUniqueUsername generates a unique username by checking database for conflicts
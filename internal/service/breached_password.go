@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// breachedFalsePositiveRate is the probability that a password that is not in the
+// list is still reported as breached
+const breachedFalsePositiveRate = 0.001
+
+// breachedPasswordList is a Bloom filter over the SHA-1 hashes of breached passwords.
+// It accepts files in the format of the Have I Been Pwned password downloads, so the
+// plain-text passwords never have to exist on the server. Membership can produce rare
+// false positives but never false negatives.
+type breachedPasswordList struct {
+	bits   []uint64
+	m      uint64 // Number of bits
+	k      uint64 // Number of hash functions
+	hashes int
+}
+
+// loadBreachedPasswordList reads a file of hex SHA-1 hashes, one per line and optionally
+// followed by ":count"
+func loadBreachedPasswordList(path string) (*breachedPasswordList, error) {
+	n, err := countBreachedHashes(path)
+	if err != nil {
+		return nil, err
+	}
+	list := newBreachedPasswordList(n)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		sum, ok := parseBreachedHash(scanner.Text())
+		if !ok {
+			continue
+		}
+		list.add(sum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return list, nil
+}
+
+func countBreachedHashes(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if _, ok := parseBreachedHash(scanner.Text()); ok {
+			n++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return n, nil
+}
+
+func parseBreachedHash(line string) ([]byte, bool) {
+	line = strings.TrimSpace(line)
+	if i := strings.IndexByte(line, ':'); i >= 0 {
+		line = line[:i]
+	}
+	if len(line) != sha1.Size*2 {
+		return nil, false
+	}
+	sum, err := hex.DecodeString(line)
+	if err != nil {
+		return nil, false
+	}
+	return sum, true
+}
+
+func newBreachedPasswordList(n int) *breachedPasswordList {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(breachedFalsePositiveRate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &breachedPasswordList{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// locations derives the k bit positions of a SHA-1 digest by double hashing;
+// the digest is already uniformly distributed, so its halves serve as the two hashes
+func (l *breachedPasswordList) locations(sum []byte, fn func(bit uint64)) {
+	h1 := binary.BigEndian.Uint64(sum[0:8])
+	h2 := binary.BigEndian.Uint64(sum[8:16]) | 1
+	for i := uint64(0); i < l.k; i++ {
+		fn((h1 + i*h2) % l.m)
+	}
+}
+
+func (l *breachedPasswordList) add(sum []byte) {
+	l.locations(sum, func(bit uint64) {
+		l.bits[bit/64] |= 1 << (bit % 64)
+	})
+	l.hashes++
+}
+
+// Contains reports whether the password is (probably) in the breached list
+func (l *breachedPasswordList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	found := true
+	l.locations(sum[:], func(bit uint64) {
+		if l.bits[bit/64]&(1<<(bit%64)) == 0 {
+			found = false
+		}
+	})
+	return found
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"linke/internal/logger"
+	"linke/internal/queue"
+)
+
+// EmailService sends transactional emails through the task queue
+type EmailService struct {
+	taskQueue *queue.TaskQueue
+}
+
+func NewEmailService(taskQueue *queue.TaskQueue) *EmailService {
+	return &EmailService{
+		taskQueue: taskQueue,
+	}
+}
+
+// Send enqueues an "email" task for the given recipient
+func (s *EmailService) Send(ctx context.Context, to, subject, body string) error {
+	task := &queue.Task{
+		ID:   fmt.Sprintf("task-%d", time.Now().UnixNano()),
+		Type: "email",
+		Payload: map[string]interface{}{
+			"to":      to,
+			"subject": subject,
+			"body":    body,
+		},
+		Retry:    0,
+		MaxRetry: 3,
+	}
+
+	if err := s.taskQueue.Enqueue(ctx, s.taskQueue.QueueFor(task.Type), task); err != nil {
+		logger.Error("Failed to enqueue email",
+			logger.String("subject", subject),
+			logger.Error2("error", err),
+		)
+		return fmt.Errorf("failed to enqueue email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"linke/config"
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const (
+	// emailVerificationCooldown limits how often a verification email can be sent to the same user
+	emailVerificationCooldown = time.Minute
+
+	emailVerificationCooldownKeyPrefix = "email_verification_cooldown:"
+)
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// EmailVerificationService issues and redeems email verification tokens.
+// Tokens are signed action tokens bound to the user's current email address,
+// and are revoked after first use so a leaked link cannot be replayed.
+type EmailVerificationService struct {
+	db                *gorm.DB
+	cfg               *config.Config
+	client            *redis.Client
+	userService       *UserService
+	jwtService        *JWTService
+	revocationService *TokenRevocationService
+	emailService      *EmailService
+}
+
+func NewEmailVerificationService(db *gorm.DB, cfg *config.Config, client *redis.Client, userService *UserService, jwtService *JWTService, revocationService *TokenRevocationService, emailService *EmailService) *EmailVerificationService {
+	return &EmailVerificationService{
+		db:                db,
+		cfg:               cfg,
+		client:            client,
+		userService:       userService,
+		jwtService:        jwtService,
+		revocationService: revocationService,
+		emailService:      emailService,
+	}
+}
+
+// IsRequired reports whether local accounts must verify their email before they can log in
+func (s *EmailVerificationService) IsRequired() bool {
+	return s.cfg.Auth.RequireVerifiedEmail
+}
+
+// SendVerification emails the user a link to verify their address
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *model.User) error {
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	ttl := time.Duration(s.cfg.Auth.EmailVerificationExpireHours) * time.Hour
+	token, _, err := s.jwtService.GenerateActionToken(user, TokenPurposeVerifyEmail, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	link := s.cfg.Server.BaseURL + "/api/v1/auth/verify-email?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\nPlease verify your email address by opening the link below:\n\n%s\n\nThe link expires in %d hours. If you did not create an account, you can ignore this email.",
+		user.Name, link, s.cfg.Auth.EmailVerificationExpireHours)
+
+	if err := s.emailService.Send(ctx, user.Email, "Verify your email address", body); err != nil {
+		return err
+	}
+
+	logger.Info("Verification email enqueued",
+		logger.Uint("user_id", user.ID),
+	)
+	return nil
+}
+
+// Resend sends a new verification email to the account with the given address.
+// It does not reveal whether the address is registered, already verified or
+// still cooling down from a previous request.
+func (s *EmailVerificationService) Resend(ctx context.Context, email string) error {
+	user, err := s.userService.GetActiveUserByEmail(ctx, email)
+	if err != nil || user.IsEmailVerified() {
+		return nil
+	}
+
+	allowed, err := s.client.SetNX(ctx, fmt.Sprintf("%s%d", emailVerificationCooldownKeyPrefix, user.ID), 1, emailVerificationCooldown).Result()
+	if err != nil {
+		return fmt.Errorf("failed to send verification email")
+	}
+	if !allowed {
+		return nil
+	}
+
+	if err := s.SendVerification(ctx, user); err != nil {
+		logger.Error("Failed to resend verification email",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return fmt.Errorf("failed to send verification email")
+	}
+	return nil
+}
+
+// Verify redeems a verification token and marks the user's email as verified
+func (s *EmailVerificationService) Verify(ctx context.Context, token string) (*model.User, error) {
+	claims, err := s.jwtService.ValidateActionToken(token, TokenPurposeVerifyEmail)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired verification link")
+	}
+
+	revoked, err := s.revocationService.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify email")
+	}
+	if revoked {
+		return nil, fmt.Errorf("verification link has already been used")
+	}
+
+	user, err := s.userService.GetActiveUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found or inactive")
+	}
+
+	if user.Email != claims.Email {
+		return nil, fmt.Errorf("invalid or expired verification link")
+	}
+
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		if err := s.db.WithContext(ctx).Model(user).Update("email_verified_at", now).Error; err != nil {
+			logger.Error("Failed to mark email as verified",
+				logger.Uint("user_id", user.ID),
+				logger.Error2("error", err),
+			)
+			return nil, fmt.Errorf("failed to verify email")
+		}
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.revocationService.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		logger.Warn("Failed to revoke verification token",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+	}
+
+	logger.Info("Email verified",
+		logger.Uint("user_id", user.ID),
+	)
+	return user, nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrIdentityInUse is returned when a provider account is already linked to another user
+	ErrIdentityInUse = errors.New("this provider account is already linked to another user")
+
+	// ErrProviderAlreadyLinked is returned when the user already has an account of the provider linked
+	ErrProviderAlreadyLinked = errors.New("an account of this provider is already linked")
+
+	// ErrLastLoginMethod is returned when removing a login method would lock the user out
+	ErrLastLoginMethod = errors.New("cannot remove the last login method of the account")
+
+	// ErrEmailAlreadyRegistered is returned when an unlinked provider account reports the email
+	// of an existing user. Accounts are never merged by email because the provider may not have
+	// verified it; the user has to sign in and link the provider explicitly.
+	ErrEmailAlreadyRegistered = errors.New("an account with this email already exists, sign in and link this provider from your account settings")
+)
+
+// IdentityService manages the external provider accounts (Google, GitHub, Telegram and OIDC
+// providers) that users can log in with
+type IdentityService struct {
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+func NewIdentityService(db *gorm.DB, auditService *AuditService) *IdentityService {
+	return &IdentityService{
+		db:           db,
+		auditService: auditService,
+	}
+}
+
+// ListIdentities returns the provider accounts linked to the user
+func (s *IdentityService) ListIdentities(ctx context.Context, userID uint) ([]*model.UserIdentity, error) {
+	var identities []*model.UserIdentity
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&identities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	return identities, nil
+}
+
+// FindUser returns the user the provider account is linked to, or nil if it is not linked
+func (s *IdentityService) FindUser(ctx context.Context, provider, subject string) (*model.User, error) {
+	var identity model.UserIdentity
+	err := s.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find identity: %w", err)
+	}
+
+	var user model.User
+	if err := s.db.WithContext(ctx).First(&user, identity.UserID).Error; err != nil {
+		return nil, fmt.Errorf("failed to find user of identity: %w", err)
+	}
+	return &user, nil
+}
+
+// CreateUser creates a user signing up with a provider account, linked to that account
+func (s *IdentityService) CreateUser(ctx context.Context, user *model.User, info *UserInfo) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if user.Email != "" {
+			var count int64
+			if err := tx.Unscoped().Model(&model.User{}).Where("email = ?", user.Email).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to check email: %w", err)
+			}
+			if count > 0 {
+				return ErrEmailAlreadyRegistered
+			}
+		}
+
+		if err := tx.Create(user).Error; err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+
+		identity := &model.UserIdentity{
+			UserID:   user.ID,
+			Provider: info.Provider,
+			Subject:  info.ID,
+			Email:    info.Email,
+		}
+		if err := tx.Create(identity).Error; err != nil {
+			return fmt.Errorf("failed to create identity: %w", err)
+		}
+		return nil
+	})
+}
+
+// Link attaches the provider account described by info to the user. Linking an account that
+// is already linked to the same user is a no-op.
+func (s *IdentityService) Link(ctx context.Context, userID uint, info *UserInfo) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	linked := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serialize identity changes per user
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return fmt.Errorf("user not found")
+		}
+
+		err := tx.Where("provider = ? AND subject = ?", info.Provider, info.ID).First(&identity).Error
+		if err == nil {
+			if identity.UserID != userID {
+				return ErrIdentityInUse
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to find identity: %w", err)
+		}
+
+		var count int64
+		if err := tx.Model(&model.UserIdentity{}).Where("user_id = ? AND provider = ?", userID, info.Provider).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check identities: %w", err)
+		}
+		if count > 0 {
+			return ErrProviderAlreadyLinked
+		}
+
+		identity = model.UserIdentity{
+			UserID:   userID,
+			Provider: info.Provider,
+			Subject:  info.ID,
+			Email:    info.Email,
+		}
+		if err := tx.Create(&identity).Error; err != nil {
+			return fmt.Errorf("failed to link identity: %w", err)
+		}
+		linked = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !linked {
+		return &identity, nil
+	}
+
+	logger.Info("Identity linked",
+		logger.Uint("user_id", userID),
+		logger.String("provider", info.Provider),
+		logger.String("provider_id", info.ID),
+	)
+	// Links completed in the OAuth callback carry no authenticated user, so the actor is set here
+	s.auditService.Record(WithAuditActor(ctx, userID), AuditEntry{
+		Action:     model.AuditActionIdentityLink,
+		TargetType: model.AuditTargetIdentity,
+		TargetID:   identity.ID,
+		After:      &identity,
+	})
+	return &identity, nil
+}
+
+// Unlink removes the user's account of the provider, unless it is the last way the user can log in
+func (s *IdentityService) Unlink(ctx context.Context, userID uint, provider string) error {
+	var identity model.UserIdentity
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return fmt.Errorf("user not found")
+		}
+
+		err := tx.Where("user_id = ? AND provider = ?", userID, provider).First(&identity).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("identity not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find identity: %w", err)
+		}
+
+		methods, err := countLoginMethods(tx, &user)
+		if err != nil {
+			return err
+		}
+		if methods <= 1 {
+			return ErrLastLoginMethod
+		}
+
+		if err := tx.Delete(&identity).Error; err != nil {
+			return fmt.Errorf("failed to unlink identity: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Identity unlinked",
+		logger.Uint("user_id", userID),
+		logger.String("provider", provider),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionIdentityUnlink,
+		TargetType: model.AuditTargetIdentity,
+		TargetID:   identity.ID,
+		Before:     &identity,
+	})
+	return nil
+}
+
+// countLoginMethods counts the independent ways the user can log in: a password, each linked
+// provider account and each passkey
+func countLoginMethods(tx *gorm.DB, user *model.User) (int64, error) {
+	var identities, passkeys int64
+	if err := tx.Model(&model.UserIdentity{}).Where("user_id = ?", user.ID).Count(&identities).Error; err != nil {
+		return 0, fmt.Errorf("failed to count identities: %w", err)
+	}
+	if err := tx.Model(&model.WebAuthnCredential{}).Where("user_id = ?", user.ID).Count(&passkeys).Error; err != nil {
+		return 0, fmt.Errorf("failed to count passkeys: %w", err)
+	}
+
+	methods := identities + passkeys
+	if user.IsLocalAccount() && user.Password != "" {
+		methods++
+	}
+	return methods, nil
+}
@@ -11,15 +11,18 @@ import (
 	"linke/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type InviteCodeService struct {
-	db *gorm.DB
+	db           *gorm.DB
+	auditService *AuditService
 }
 
-func NewInviteCodeService(db *gorm.DB) *InviteCodeService {
+func NewInviteCodeService(db *gorm.DB, auditService *AuditService) *InviteCodeService {
 	return &InviteCodeService{
-		db: db,
+		db:           db,
+		auditService: auditService,
 	}
 }
 
@@ -52,6 +55,15 @@ func (s *InviteCodeService) GenerateInviteCode() (string, error) {
 
 // CreateInviteCode creates a new invite code
 func (s *InviteCodeService) CreateInviteCode(ctx context.Context, createdByID uint, req *CreateInviteCodeRequest) (*model.InviteCode, error) {
+	return s.createInviteCode(ctx, createdByID, nil, req)
+}
+
+// CreateOrganizationInviteCode creates an invite code that makes its users members of the organization
+func (s *InviteCodeService) CreateOrganizationInviteCode(ctx context.Context, createdByID, organizationID uint, req *CreateInviteCodeRequest) (*model.InviteCode, error) {
+	return s.createInviteCode(ctx, createdByID, &organizationID, req)
+}
+
+func (s *InviteCodeService) createInviteCode(ctx context.Context, createdByID uint, organizationID *uint, req *CreateInviteCodeRequest) (*model.InviteCode, error) {
 	// Generate unique code
 	code, err := s.GenerateInviteCode()
 	if err != nil {
@@ -60,12 +72,13 @@ func (s *InviteCodeService) CreateInviteCode(ctx context.Context, createdByID ui
 
 	// Create invite code
 	inviteCode := &model.InviteCode{
-		Code:        code,
-		CreatedByID: createdByID,
-		Status:      model.InviteCodeStatusActive,
-		MaxUses:     req.MaxUses,
-		UsedCount:   0,
-		Description: req.Description,
+		Code:           code,
+		CreatedByID:    createdByID,
+		OrganizationID: organizationID,
+		Status:         model.InviteCodeStatusActive,
+		MaxUses:        req.MaxUses,
+		UsedCount:      0,
+		Description:    req.Description,
 	}
 
 	if err := s.db.WithContext(ctx).Create(inviteCode).Error; err != nil {
@@ -229,6 +242,31 @@ func (s *InviteCodeService) UseInviteCode(ctx context.Context, code string, user
 		return nil, fmt.Errorf("failed to create usage record: %w", err)
 	}
 
+	// Organization invite codes make the user a member of the organization
+	if inviteCode.OrganizationID != nil {
+		member := &model.OrganizationMember{
+			OrganizationID: *inviteCode.OrganizationID,
+			UserID:         userID,
+			Role:           model.OrganizationRoleMember,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(member)
+		if result.Error != nil {
+			tx.Rollback()
+			logger.Error("Failed to add organization member from invite code",
+				logger.Uint("invite_code_id", inviteCode.ID),
+				logger.Uint("organization_id", *inviteCode.OrganizationID),
+				logger.Uint("user_id", userID),
+				logger.Error2("error", result.Error),
+			)
+			return nil, fmt.Errorf("failed to join organization: %w", result.Error)
+		}
+		// The user is already a member, e.g. from a concurrent join; do not consume a use of the code
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			return nil, ErrAlreadyOrganizationMember
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		logger.Error("Failed to commit invite code usage transaction",
@@ -246,6 +284,19 @@ func (s *InviteCodeService) UseInviteCode(ctx context.Context, code string, user
 		logger.Int("used_count", inviteCode.UsedCount),
 	)
 
+	if inviteCode.OrganizationID != nil {
+		s.auditService.Record(WithAuditActor(ctx, userID), AuditEntry{
+			Action:     model.AuditActionOrganizationMemberJoin,
+			TargetType: model.AuditTargetOrganization,
+			TargetID:   *inviteCode.OrganizationID,
+			After: map[string]interface{}{
+				"user_id":        userID,
+				"role":           model.OrganizationRoleMember,
+				"invite_code_id": inviteCode.ID,
+			},
+		})
+	}
+
 	return inviteCode, nil
 }
 
@@ -295,6 +346,29 @@ func (s *InviteCodeService) ListInviteCodesByCreator(ctx context.Context, creato
 	return codes, total, nil
 }
 
+// ListInviteCodesByOrganization lists the invite codes of an organization
+func (s *InviteCodeService) ListInviteCodesByOrganization(ctx context.Context, organizationID uint, limit, offset int) ([]*model.InviteCode, int64, error) {
+	var codes []*model.InviteCode
+	var total int64
+
+	// Count total codes
+	if err := s.db.WithContext(ctx).Model(&model.InviteCode{}).Where("organization_id = ?", organizationID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count invite codes: %w", err)
+	}
+
+	// Get codes with pagination
+	if err := s.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&codes).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list invite codes: %w", err)
+	}
+
+	return codes, total, nil
+}
+
 // UpdateInviteCodeStatus updates the status of an invite code
 func (s *InviteCodeService) UpdateInviteCodeStatus(ctx context.Context, id uint, status string) (*model.InviteCode, error) {
 	var inviteCode model.InviteCode
@@ -306,6 +380,7 @@ func (s *InviteCodeService) UpdateInviteCodeStatus(ctx context.Context, id uint,
 	}
 
 	// Update status
+	before := inviteCode
 	inviteCode.Status = status
 	if err := s.db.WithContext(ctx).Save(&inviteCode).Error; err != nil {
 		logger.Error("Failed to update invite code status",
@@ -321,11 +396,27 @@ func (s *InviteCodeService) UpdateInviteCodeStatus(ctx context.Context, id uint,
 		logger.String("status", status),
 	)
 
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionInviteCodeStatusUpdate,
+		TargetType: model.AuditTargetInviteCode,
+		TargetID:   id,
+		Before:     &before,
+		After:      &inviteCode,
+	})
+
 	return &inviteCode, nil
 }
 
 // DeleteInviteCode soft deletes an invite code
 func (s *InviteCodeService) DeleteInviteCode(ctx context.Context, id uint) error {
+	var before model.InviteCode
+	if err := s.db.WithContext(ctx).First(&before, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("invite code not found")
+		}
+		return fmt.Errorf("failed to get invite code: %w", err)
+	}
+
 	result := s.db.WithContext(ctx).Delete(&model.InviteCode{}, id)
 	if result.Error != nil {
 		logger.Error("Failed to delete invite code",
@@ -343,6 +434,13 @@ func (s *InviteCodeService) DeleteInviteCode(ctx context.Context, id uint) error
 		logger.Uint("invite_code_id", id),
 	)
 
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionInviteCodeDelete,
+		TargetType: model.AuditTargetInviteCode,
+		TargetID:   id,
+		Before:     &before,
+	})
+
 	return nil
 }
 
@@ -11,14 +11,49 @@ import (
 )
 
 type JWTService struct {
-	cfg *config.Config
+	cfg  *config.Config
+	keys *keySet
 }
 
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Email    string `json:"email"`
-	Username string `json:"username"`
-	Provider string `json:"provider"`
+	UserID       uint        `json:"user_id"`
+	Email        string      `json:"email"`
+	Username     string      `json:"username"`
+	Provider     string      `json:"provider"`
+	TokenVersion int         `json:"ver"`              // Must match User.TokenVersion, bumped on "log out everywhere"
+	SessionID    uint        `json:"sid"`              // Session the token belongs to
+	OrgID        uint        `json:"org_id,omitempty"` // Active organization of the session, 0 if none
+	Act          *ActorClaim `json:"act,omitempty"`    // Set when an admin is impersonating the user
+	jwt.RegisteredClaims
+}
+
+// ActorClaim identifies the admin acting on behalf of the token's user, following the "act" claim of RFC 8693
+type ActorClaim struct {
+	Subject string `json:"sub"`
+	UserID  uint   `json:"user_id"`
+	Email   string `json:"email"`
+}
+
+// IsImpersonation reports whether the token was issued to an admin impersonating the user
+func (c *Claims) IsImpersonation() bool {
+	return c.Act != nil
+}
+
+// Purposes of short-lived action tokens
+const (
+	TokenPurposeMFA         = "mfa"          // Password verified, waiting for the second factor
+	TokenPurposeVerifyEmail = "verify_email" // Proves ownership of the email address
+	TokenPurposeMagicLink   = "magic_link"   // One-time passwordless login link
+)
+
+// actionTokenAudience keeps action tokens from being accepted as access tokens and vice versa
+const actionTokenAudience = "linke-action"
+
+// ActionClaims are carried by short-lived, single-purpose tokens that are not access tokens
+type ActionClaims struct {
+	UserID  uint   `json:"user_id"`
+	Email   string `json:"email"` // Email of the user when the token was issued, so a changed address invalidates it
+	Purpose string `json:"purpose"`
 	jwt.RegisteredClaims
 }
 
@@ -30,22 +65,45 @@ type TokenResponse struct {
 	RefreshToken string    `json:"refresh_token,omitempty"`
 }
 
-func NewJWTService(cfg *config.Config) *JWTService {
-	return &JWTService{
-		cfg: cfg,
+func NewJWTService(cfg *config.Config) (*JWTService, error) {
+	keys, err := loadKeySet(cfg.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
 	}
+
+	return &JWTService{
+		cfg:  cfg,
+		keys: keys,
+	}, nil
+}
+
+// GenerateToken generates a JWT token for the given user and session
+func (j *JWTService) GenerateToken(user *model.User, session *model.Session) (*TokenResponse, error) {
+	return j.generateToken(user, session, nil, time.Now().Add(time.Duration(j.cfg.JWT.ExpireHours)*time.Hour))
+}
+
+// GenerateImpersonationToken generates a JWT token for the given user and session that carries
+// the impersonating admin in its act claim and expires at expiresAt
+func (j *JWTService) GenerateImpersonationToken(user *model.User, session *model.Session, actor *ActorClaim, expiresAt time.Time) (*TokenResponse, error) {
+	return j.generateToken(user, session, actor, expiresAt)
 }
 
-// GenerateToken generates a JWT token for the given user
-func (j *JWTService) GenerateToken(user *model.User) (*TokenResponse, error) {
-	expirationTime := time.Now().Add(time.Duration(j.cfg.JWT.ExpireHours) * time.Hour)
+func (j *JWTService) generateToken(user *model.User, session *model.Session, actor *ActorClaim, expirationTime time.Time) (*TokenResponse, error) {
+	jti, err := generateOpaqueToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token id: %w", err)
+	}
 
 	claims := &Claims{
-		UserID:   user.ID,
-		Email:    user.Email,
-		Username: user.Username,
-		Provider: user.Provider,
+		UserID:       user.ID,
+		Email:        user.Email,
+		Username:     user.Username,
+		Provider:     user.Provider,
+		TokenVersion: user.TokenVersion,
+		SessionID:    session.ID,
+		Act:          actor,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -54,8 +112,11 @@ func (j *JWTService) GenerateToken(user *model.User) (*TokenResponse, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.cfg.JWT.Secret))
+	if session.OrganizationID != nil {
+		claims.OrgID = *session.OrganizationID
+	}
+
+	tokenString, err := j.sign(claims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -63,58 +124,86 @@ func (j *JWTService) GenerateToken(user *model.User) (*TokenResponse, error) {
 	return &TokenResponse{
 		AccessToken: tokenString,
 		TokenType:   "Bearer",
-		ExpiresIn:   j.cfg.JWT.ExpireHours * 3600, // Convert hours to seconds
+		ExpiresIn:   int(time.Until(expirationTime).Round(time.Second).Seconds()),
 		ExpiresAt:   expirationTime,
 	}, nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
 func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.cfg.JWT.Secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.keys.keyFunc, jwt.WithValidMethods(j.keys.validMethods()))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && len(claims.Audience) == 0 {
 		return claims, nil
 	}
 
 	return nil, fmt.Errorf("invalid token")
 }
 
-// RefreshToken generates a new token based on an existing valid token
-func (j *JWTService) RefreshToken(tokenString string) (*TokenResponse, error) {
-	claims, err := j.ValidateToken(tokenString)
+// GenerateActionToken generates a short-lived token that can only be used for the given purpose
+func (j *JWTService) GenerateActionToken(user *model.User, purpose string, ttl time.Duration) (string, time.Time, error) {
+	expirationTime := time.Now().Add(ttl)
+
+	jti, err := generateOpaqueToken(16)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token for refresh: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to generate token id: %w", err)
+	}
+
+	claims := &ActionClaims{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Audience:  jwt.ClaimStrings{actionTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "linke-api",
+			Subject:   fmt.Sprintf("user:%d", user.ID),
+		},
 	}
 
-	// Check if token is close to expiry (within 1 hour)
-	if time.Until(claims.ExpiresAt.Time) > time.Hour {
-		return nil, fmt.Errorf("token is not close to expiry, no need to refresh")
+	tokenString, err := j.sign(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	// Create new token with updated expiration
-	newExpirationTime := time.Now().Add(time.Duration(j.cfg.JWT.ExpireHours) * time.Hour)
-	claims.ExpiresAt = jwt.NewNumericDate(newExpirationTime)
-	claims.IssuedAt = jwt.NewNumericDate(time.Now())
+	return tokenString, expirationTime, nil
+}
+
+// ValidateActionToken validates an action token and checks that it was issued for the given purpose
+func (j *JWTService) ValidateActionToken(tokenString, purpose string) (*ActionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ActionClaims{}, j.keys.keyFunc,
+		jwt.WithValidMethods(j.keys.validMethods()),
+		jwt.WithAudience(actionTokenAudience),
+	)
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	newTokenString, err := token.SignedString([]byte(j.cfg.JWT.Secret))
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+		return nil, fmt.Errorf("failed to parse token: %w", err)
 	}
 
-	return &TokenResponse{
-		AccessToken: newTokenString,
-		TokenType:   "Bearer",
-		ExpiresIn:   j.cfg.JWT.ExpireHours * 3600,
-		ExpiresAt:   newExpirationTime,
-	}, nil
-}
\ No newline at end of file
+	if claims, ok := token.Claims.(*ActionClaims); ok && token.Valid && claims.Purpose == purpose {
+		return claims, nil
+	}
+
+	return nil, fmt.Errorf("invalid token")
+}
+
+// JWKS returns the public keys that can be used to verify tokens issued by this service
+func (j *JWTService) JWKS() *JWKS {
+	return j.keys.jwks()
+}
+
+// sign signs the claims with the active signing key, setting the kid header when applicable
+func (j *JWTService) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(j.keys.signingMethod, claims)
+	if j.keys.signingKID != "" {
+		token.Header["kid"] = j.keys.signingKID
+	}
+	return token.SignedString(j.keys.signingKey)
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"linke/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported JWT signing algorithms
+const (
+	SigningAlgHS256 = "HS256"
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+)
+
+// JWK represents a single public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// RSA public key parameters
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// OKP (Ed25519) and EC public key parameters
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS represents a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// verificationKey is a public key (or HMAC secret) accepted when validating tokens
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// keySet holds the active signing key and every key accepted for verification.
+// Keeping retired public keys in the verification set lets tokens signed before
+// a key rotation stay valid until they expire.
+type keySet struct {
+	signingKID    string
+	signingMethod jwt.SigningMethod
+	signingKey    interface{}
+	verification  map[string]*verificationKey
+	hmacSecret    []byte
+}
+
+// loadKeySet builds the key set described by the JWT configuration
+func loadKeySet(cfg config.JWTConfig) (*keySet, error) {
+	ks := &keySet{
+		verification: make(map[string]*verificationKey),
+	}
+
+	switch cfg.SigningAlgorithm {
+	case "", SigningAlgHS256:
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("JWT_SECRET is required for HS256 signing")
+		}
+		ks.signingMethod = jwt.SigningMethodHS256
+		ks.signingKey = []byte(cfg.Secret)
+		ks.hmacSecret = []byte(cfg.Secret)
+		return ks, nil
+	case SigningAlgRS256, SigningAlgEdDSA:
+		// handled below
+	default:
+		return nil, fmt.Errorf("unsupported JWT signing algorithm: %s", cfg.SigningAlgorithm)
+	}
+
+	if cfg.PrivateKeyFile == "" {
+		return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE is required for %s signing", cfg.SigningAlgorithm)
+	}
+
+	privateKey, err := readPrivateKey(cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKey crypto.PublicKey
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if cfg.SigningAlgorithm != SigningAlgRS256 {
+			return nil, fmt.Errorf("private key in %s is RSA but JWT_SIGNING_ALGORITHM is %s", cfg.PrivateKeyFile, cfg.SigningAlgorithm)
+		}
+		publicKey = &key.PublicKey
+	case ed25519.PrivateKey:
+		if cfg.SigningAlgorithm != SigningAlgEdDSA {
+			return nil, fmt.Errorf("private key in %s is Ed25519 but JWT_SIGNING_ALGORITHM is %s", cfg.PrivateKeyFile, cfg.SigningAlgorithm)
+		}
+		publicKey = key.Public()
+	default:
+		return nil, fmt.Errorf("unsupported private key type in %s", cfg.PrivateKeyFile)
+	}
+
+	signingVerifier, err := newVerificationKey(cfg.KeyID, publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ks.signingKID = signingVerifier.kid
+	ks.signingMethod = signingVerifier.method
+	ks.signingKey = privateKey
+	ks.verification[signingVerifier.kid] = signingVerifier
+
+	// Additional public keys that remain valid for verification during rotation
+	for _, entry := range cfg.VerificationKeyFiles {
+		kid, path := "", entry
+		if parts := strings.SplitN(entry, "=", 2); len(parts) == 2 {
+			kid, path = parts[0], parts[1]
+		}
+
+		publicKey, err := readPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+
+		verifier, err := newVerificationKey(kid, publicKey)
+		if err != nil {
+			return nil, err
+		}
+		ks.verification[verifier.kid] = verifier
+	}
+
+	return ks, nil
+}
+
+// keyFunc resolves the verification key for a token based on its kid header
+func (ks *keySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if kid == "" {
+		// Tokens without kid are only accepted when signing with a shared secret
+		if ks.hmacSecret == nil {
+			return nil, fmt.Errorf("token has no kid header")
+		}
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return ks.hmacSecret, nil
+	}
+
+	verifier, exists := ks.verification[kid]
+	if !exists {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+	if token.Method.Alg() != verifier.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return verifier.key, nil
+}
+
+// validMethods returns the algorithm names accepted by the key set
+func (ks *keySet) validMethods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+	if ks.hmacSecret != nil {
+		seen[SigningAlgHS256] = true
+		methods = append(methods, SigningAlgHS256)
+	}
+	for _, verifier := range ks.verification {
+		if alg := verifier.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// jwks returns the public verification keys. Shared HMAC secrets are never published.
+func (ks *keySet) jwks() *JWKS {
+	kids := make([]string, 0, len(ks.verification))
+	for kid := range ks.verification {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := &JWKS{Keys: []JWK{}}
+	for _, kid := range kids {
+		verifier := ks.verification[kid]
+		jwk, err := publicKeyToJWK(verifier.key)
+		if err != nil {
+			continue
+		}
+		jwk.Kid = verifier.kid
+		jwk.Alg = verifier.method.Alg()
+		jwk.Use = "sig"
+		set.Keys = append(set.Keys, *jwk)
+	}
+	return set
+}
+
+func newVerificationKey(kid string, publicKey crypto.PublicKey) (*verificationKey, error) {
+	var method jwt.SigningMethod
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	if kid == "" {
+		thumbprint, err := jwkThumbprint(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		kid = thumbprint
+	}
+
+	return &verificationKey{
+		kid:    kid,
+		method: method,
+		key:    publicKey,
+	}, nil
+}
+
+func publicKeyToJWK(publicKey interface{}) (*JWK, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return &JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// jwkToPublicKey parses an RSA, EC or Ed25519 public key from its JWK representation
+func jwkToPublicKey(jwk *JWK) (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := decode(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		key := &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", jwk.Crv)
+		}
+		return key, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+// jwkThumbprint computes the RFC 7638 thumbprint of a public key, used as the default kid
+func jwkThumbprint(publicKey crypto.PublicKey) (string, error) {
+	jwk, err := publicKeyToJWK(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	// Members must be in lexicographic order with no whitespace
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func readPrivateKey(path string) (crypto.PrivateKey, error) {
+	block, err := readPEMBlock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("failed to parse private key in %s", path)
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEMBlock(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("failed to parse public key in %s", path)
+}
+
+func readPEMBlock(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	return block, nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"linke/config"
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	loginFailuresKeyPrefix = "login_failures:"
+	loginLockoutKeyPrefix  = "login_lockout:"
+
+	// Failed logins are slowed down by loginDelayBase, doubled for every further
+	// failure in the window, up to loginDelayMax
+	loginDelayBase = 250 * time.Millisecond
+	loginDelayMax  = 4 * time.Second
+)
+
+// LockoutError is returned while an email address or client IP is locked out
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	minutes := int(math.Ceil(e.RetryAfter.Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+	return fmt.Sprintf("too many failed login attempts, please try again in %d minute(s)", minutes)
+}
+
+// LoginLockoutStatus describes the lockout state of an email address
+type LoginLockoutStatus struct {
+	Email          string     `json:"email"`
+	Locked         bool       `json:"locked"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	RecentFailures int64      `json:"recent_failures"` // Failed logins within the current window
+}
+
+// LoginGuardService counts failed password logins per email address and per client IP
+// in Redis sliding windows, and locks either out once its threshold is reached
+type LoginGuardService struct {
+	cfg          *config.Config
+	client       *redis.Client
+	auditService *AuditService
+}
+
+func NewLoginGuardService(cfg *config.Config, client *redis.Client, auditService *AuditService) *LoginGuardService {
+	return &LoginGuardService{
+		cfg:          cfg,
+		client:       client,
+		auditService: auditService,
+	}
+}
+
+// Check returns a *LockoutError if the email address or the client IP is locked out
+func (s *LoginGuardService) Check(ctx context.Context, email, ipAddress string) error {
+	for _, subject := range s.subjects(email, ipAddress) {
+		ttl, err := s.client.PTTL(ctx, loginLockoutKeyPrefix+subject).Result()
+		if err != nil {
+			// Fail open so a Redis outage does not block every login
+			logger.Error("Failed to check login lockout", logger.Error2("error", err))
+			return nil
+		}
+		if ttl > 0 {
+			return &LockoutError{RetryAfter: ttl}
+		}
+	}
+	return nil
+}
+
+// RegisterFailure records a failed login, slows the caller down progressively and
+// returns a *LockoutError if this failure pushed the email or IP over its threshold
+func (s *LoginGuardService) RegisterFailure(ctx context.Context, email, ipAddress string) error {
+	window := time.Duration(s.cfg.Auth.LoginFailureWindowMinutes) * time.Minute
+	lockout := time.Duration(s.cfg.Auth.LoginLockoutMinutes) * time.Minute
+
+	var lockoutErr error
+	var maxFailures int64
+	for _, subject := range s.subjects(email, ipAddress) {
+		failures, err := s.recordFailure(ctx, subject, window)
+		if err != nil {
+			logger.Error("Failed to record login failure", logger.Error2("error", err))
+			continue
+		}
+		if failures > maxFailures {
+			maxFailures = failures
+		}
+
+		if failures >= s.threshold(subject) {
+			if err := s.client.Set(ctx, loginLockoutKeyPrefix+subject, failures, lockout).Err(); err != nil {
+				logger.Error("Failed to set login lockout", logger.Error2("error", err))
+				continue
+			}
+			logger.Warn("Login locked out after repeated failures",
+				logger.String("subject", strings.SplitN(subject, ":", 2)[0]),
+				logger.String("ip_address", ipAddress),
+				logger.Int64("failures", failures),
+			)
+			lockoutErr = &LockoutError{RetryAfter: lockout}
+		}
+	}
+
+	if lockoutErr != nil {
+		return lockoutErr
+	}
+
+	s.delay(ctx, maxFailures)
+	return nil
+}
+
+// Reset forgets the failed logins of an email address after a successful login.
+// Failures of the client IP are kept, so one valid account cannot be used to reset the IP counter.
+func (s *LoginGuardService) Reset(ctx context.Context, email string) {
+	if err := s.client.Del(ctx, loginFailuresKeyPrefix+emailSubject(email)).Err(); err != nil {
+		logger.Error("Failed to reset login failures", logger.Error2("error", err))
+	}
+}
+
+// GetStatus returns the lockout state of an email address
+func (s *LoginGuardService) GetStatus(ctx context.Context, email string) (*LoginLockoutStatus, error) {
+	subject := emailSubject(email)
+	window := time.Duration(s.cfg.Auth.LoginFailureWindowMinutes) * time.Minute
+
+	ttl, err := s.client.PTTL(ctx, loginLockoutKeyPrefix+subject).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lockout: %w", err)
+	}
+
+	cutoff := time.Now().Add(-window).UnixNano()
+	failures, err := s.client.ZCount(ctx, loginFailuresKeyPrefix+subject, strconv.FormatInt(cutoff, 10), "+inf").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count login failures: %w", err)
+	}
+
+	status := &LoginLockoutStatus{
+		Email:          email,
+		RecentFailures: failures,
+	}
+	if ttl > 0 {
+		until := time.Now().Add(ttl)
+		status.Locked = true
+		status.LockedUntil = &until
+	}
+	return status, nil
+}
+
+// Clear lifts the lockout of a user's email address and forgets its failed logins
+func (s *LoginGuardService) Clear(ctx context.Context, user *model.User) error {
+	before, err := s.GetStatus(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+
+	subject := emailSubject(user.Email)
+	if err := s.client.Del(ctx, loginLockoutKeyPrefix+subject, loginFailuresKeyPrefix+subject).Err(); err != nil {
+		return fmt.Errorf("failed to clear lockout: %w", err)
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserLockoutClear,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID,
+		Before:     before,
+		After:      &LoginLockoutStatus{Email: before.Email},
+	})
+	return nil
+}
+
+// recordFailure adds a failure to the subject's sliding window and returns the number of
+// failures still inside the window
+func (s *LoginGuardService) recordFailure(ctx context.Context, subject string, window time.Duration) (int64, error) {
+	key := loginFailuresKeyPrefix + subject
+	now := time.Now()
+	cutoff := now.Add(-window).UnixNano()
+
+	var card *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(cutoff, 10))
+		pipe.ZAdd(ctx, key, &redis.Z{Score: float64(now.UnixNano()), Member: now.UnixNano()})
+		card = pipe.ZCard(ctx, key)
+		pipe.Expire(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return card.Val(), nil
+}
+
+func (s *LoginGuardService) threshold(subject string) int64 {
+	if strings.HasPrefix(subject, "ip:") {
+		return int64(s.cfg.Auth.MaxLoginFailuresPerIP)
+	}
+	return int64(s.cfg.Auth.MaxLoginFailuresPerEmail)
+}
+
+// delay sleeps for the progressive delay of the given number of failures
+func (s *LoginGuardService) delay(ctx context.Context, failures int64) {
+	if failures < 1 {
+		return
+	}
+
+	d := loginDelayMax
+	if failures < 16 {
+		if scaled := loginDelayBase << (failures - 1); scaled < loginDelayMax {
+			d = scaled
+		}
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+func (s *LoginGuardService) subjects(email, ipAddress string) []string {
+	subjects := []string{emailSubject(email)}
+	if ipAddress != "" {
+		subjects = append(subjects, "ip:"+ipAddress)
+	}
+	return subjects
+}
+
+// emailSubject keys counters by a hash of the normalized address so emails are not stored in Redis
+func emailSubject(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return "email:" + hex.EncodeToString(sum[:])
+}
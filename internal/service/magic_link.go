@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"linke/config"
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// magicLinkCooldown limits how often a login link can be sent to the same address
+	magicLinkCooldown = time.Minute
+
+	magicLinkCooldownKeyPrefix = "magic_link_cooldown:"
+)
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type MagicLinkVerifyRequest struct {
+	Token      string `json:"token" binding:"required"`
+	InviteCode string `json:"invite_code"` // Only used when the link creates a new account
+}
+
+// MagicLinkService emails one-time login links. Links are signed action tokens bound
+// to the email address, and are revoked on first use.
+type MagicLinkService struct {
+	cfg               *config.Config
+	client            *redis.Client
+	userService       *UserService
+	jwtService        *JWTService
+	revocationService *TokenRevocationService
+	emailService      *EmailService
+}
+
+func NewMagicLinkService(cfg *config.Config, client *redis.Client, userService *UserService, jwtService *JWTService, revocationService *TokenRevocationService, emailService *EmailService) *MagicLinkService {
+	return &MagicLinkService{
+		cfg:               cfg,
+		client:            client,
+		userService:       userService,
+		jwtService:        jwtService,
+		revocationService: revocationService,
+		emailService:      emailService,
+	}
+}
+
+// Send emails a login link to the given address. A link sent to an unknown address signs
+// the user up on first use. It does not reveal whether the address is registered.
+func (s *MagicLinkService) Send(ctx context.Context, email string) error {
+	user, err := s.userService.GetUserByEmail(ctx, email)
+	if err != nil {
+		user = &model.User{Email: email}
+	} else if !user.IsActive() || !user.IsLocalAccount() {
+		logger.Info("Magic link requested for inactive or non-local account",
+			logger.Uint("user_id", user.ID),
+		)
+		return nil
+	}
+
+	emailHash := sha256.Sum256([]byte(strings.ToLower(email)))
+	allowed, err := s.client.SetNX(ctx, magicLinkCooldownKeyPrefix+hex.EncodeToString(emailHash[:]), 1, magicLinkCooldown).Result()
+	if err != nil {
+		return fmt.Errorf("failed to send login link")
+	}
+	if !allowed {
+		return nil
+	}
+
+	ttl := time.Duration(s.cfg.Auth.MagicLinkExpireMinutes) * time.Minute
+	token, _, err := s.jwtService.GenerateActionToken(user, TokenPurposeMagicLink, ttl)
+	if err != nil {
+		return fmt.Errorf("failed to generate login link: %w", err)
+	}
+
+	link := s.cfg.Auth.MagicLinkURL + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi,\n\nOpen the link below to log in:\n\n%s\n\nThe link expires in %d minutes and can only be used once. If you did not request it, you can ignore this email.",
+		link, s.cfg.Auth.MagicLinkExpireMinutes)
+
+	if err := s.emailService.Send(ctx, email, "Your login link", body); err != nil {
+		return fmt.Errorf("failed to send login link")
+	}
+
+	logger.Info("Magic link email enqueued",
+		logger.Uint("user_id", user.ID),
+	)
+	return nil
+}
+
+// Redeem validates a login link and revokes it so it cannot be used again.
+// UserID in the returned claims is zero when the link was issued to an unregistered address.
+func (s *MagicLinkService) Redeem(ctx context.Context, token string) (*ActionClaims, error) {
+	claims, err := s.jwtService.ValidateActionToken(token, TokenPurposeMagicLink)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired login link")
+	}
+
+	// Claiming is atomic, so two concurrent requests with the same link cannot both log in
+	claimed, err := s.revocationService.Claim(ctx, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		logger.Error("Failed to claim magic link token",
+			logger.String("email", claims.Email),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to verify login link")
+	}
+	if !claimed {
+		return nil, fmt.Errorf("login link has already been used")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"linke/config"
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+const (
+	// mfaTokenTTL is how long a user has to enter their second factor after the password check
+	mfaTokenTTL = 5 * time.Minute
+	// mfaMaxAttempts is how many wrong codes a pending MFA token tolerates before it is revoked
+	mfaMaxAttempts = 5
+	// recoveryCodeCount is how many recovery codes are generated at a time
+	recoveryCodeCount = 10
+
+	mfaAttemptsKeyPrefix = "mfa_attempts:"
+)
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"` // Token returned by /auth/login when MFA is required
+	Code     string `json:"code" binding:"required"`      // TOTP code or recovery code
+}
+
+type MFAStatusResponse struct {
+	TOTPEnabled            bool       `json:"totp_enabled"`
+	TOTPEnabledAt          *time.Time `json:"totp_enabled_at,omitempty"`
+	EnrollmentRequired     bool       `json:"enrollment_required"` // Admin routes stay blocked until TOTP is enabled
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`      // Base32 secret for manual entry
+	OtpauthURI string `json:"otpauth_uri"` // otpauth:// URI, usually rendered as a QR code
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // Shown once, store them somewhere safe
+}
+
+type MFAService struct {
+	db           *gorm.DB
+	cfg          *config.Config
+	client       *redis.Client
+	secrets      *totpSecretBox
+	auditService *AuditService
+}
+
+func NewMFAService(db *gorm.DB, cfg *config.Config, client *redis.Client, auditService *AuditService) (*MFAService, error) {
+	secrets, err := newTOTPSecretBox(cfg.MFA.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAService{
+		db:           db,
+		cfg:          cfg,
+		client:       client,
+		secrets:      secrets,
+		auditService: auditService,
+	}, nil
+}
+
+// IsEnrollmentRequired reports whether the user must enable TOTP before using admin routes.
+// Every role other than the default user role counts as privileged.
+func (s *MFAService) IsEnrollmentRequired(user *model.User) bool {
+	return s.cfg.MFA.RequireForAdmins && user.Role != model.UserRoleUser && user.IsLocalAccount() && !user.IsTOTPEnabled()
+}
+
+// GetStatus returns the user's two-factor authentication status
+func (s *MFAService) GetStatus(ctx context.Context, user *model.User) (*MFAStatusResponse, error) {
+	var remaining int64
+	if err := s.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", user.ID).
+		Count(&remaining).Error; err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return &MFAStatusResponse{
+		TOTPEnabled:            user.IsTOTPEnabled(),
+		TOTPEnabledAt:          user.TOTPEnabledAt,
+		EnrollmentRequired:     s.IsEnrollmentRequired(user),
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// BeginTOTPEnrollment generates a new pending TOTP secret for the user.
+// The secret only takes effect once ConfirmTOTPEnrollment succeeds.
+func (s *MFAService) BeginTOTPEnrollment(ctx context.Context, user *model.User) (*TOTPEnrollmentResponse, error) {
+	if !user.IsLocalAccount() {
+		return nil, fmt.Errorf("two-factor authentication is only available for local accounts")
+	}
+	if user.IsTOTPEnabled() {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	sealed, err := s.secrets.seal(user.ID, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(user).Update("totp_secret", sealed).Error; err != nil {
+		logger.Error("Failed to store pending TOTP secret",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to start enrollment")
+	}
+
+	return &TOTPEnrollmentResponse{
+		Secret:     secret,
+		OtpauthURI: totpURI(s.cfg.MFA.Issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables TOTP after checking a code from the pending secret
+// and returns a fresh set of recovery codes
+func (s *MFAService) ConfirmTOTPEnrollment(ctx context.Context, user *model.User, code string) ([]string, error) {
+	if user.IsTOTPEnabled() {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+	if user.TOTPSecret == "" {
+		return nil, fmt.Errorf("no pending enrollment, start TOTP setup first")
+	}
+
+	secret, err := s.totpSecret(user)
+	if err != nil {
+		logger.Error("Failed to decrypt pending TOTP secret",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("pending enrollment cannot be read, start TOTP setup again")
+	}
+	step, ok := validateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("invalid verification code")
+	}
+
+	var codes []string
+	now := time.Now()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(map[string]interface{}{
+			"totp_enabled_at":     now,
+			"totp_last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		logger.Error("Failed to enable TOTP",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to enable two-factor authentication")
+	}
+
+	logger.Info("TOTP enabled",
+		logger.Uint("user_id", user.ID),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserMFAEnable,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID,
+		Before:     map[string]interface{}{"totp_enabled_at": nil},
+		After:      map[string]interface{}{"totp_enabled_at": now},
+	})
+	return codes, nil
+}
+
+// DisableTOTP turns off two-factor authentication after verifying a current code
+func (s *MFAService) DisableTOTP(ctx context.Context, user *model.User, code string) error {
+	if !user.IsTOTPEnabled() {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	if err := s.VerifyCode(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err := s.removeTOTP(ctx, user.ID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserMFADisable,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID,
+		Before:     map[string]interface{}{"totp_enabled_at": user.TOTPEnabledAt},
+		After:      map[string]interface{}{"totp_enabled_at": nil},
+	})
+	return nil
+}
+
+// ResetTOTP removes the user's TOTP secret and recovery codes without verification,
+// e.g. when an admin helps a user who lost their authenticator
+func (s *MFAService) ResetTOTP(ctx context.Context, userID uint) error {
+	var user model.User
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("user not found")
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.removeTOTP(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserMFAReset,
+		TargetType: model.AuditTargetUser,
+		TargetID:   userID,
+		Before:     map[string]interface{}{"totp_enabled_at": user.TOTPEnabledAt},
+		After:      map[string]interface{}{"totp_enabled_at": nil},
+	})
+	return nil
+}
+
+// removeTOTP deletes the user's TOTP secret and recovery codes
+func (s *MFAService) removeTOTP(ctx context.Context, userID uint) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"totp_secret":         "",
+			"totp_enabled_at":     nil,
+			"totp_last_used_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error
+	})
+	if err != nil {
+		logger.Error("Failed to disable TOTP",
+			logger.Uint("user_id", userID),
+			logger.Error2("error", err),
+		)
+		return fmt.Errorf("failed to disable two-factor authentication")
+	}
+
+	logger.Info("TOTP disabled",
+		logger.Uint("user_id", userID),
+	)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after verifying a current code
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, user *model.User, code string) ([]string, error) {
+	if !user.IsTOTPEnabled() {
+		return nil, fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	if err := s.VerifyCode(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		logger.Error("Failed to regenerate recovery codes",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to regenerate recovery codes")
+	}
+	return codes, nil
+}
+
+// VerifyCode checks a TOTP code or, failing that, redeems a recovery code.
+// Each TOTP time step and each recovery code can only be used once.
+func (s *MFAService) VerifyCode(ctx context.Context, user *model.User, code string) error {
+	if !user.IsTOTPEnabled() {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	// Recovery codes keep working if the secret cannot be decrypted, e.g. after the key changed
+	if secret, err := s.totpSecret(user); err != nil {
+		logger.Error("Failed to decrypt TOTP secret",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+	} else if step, ok := validateTOTP(secret, code, time.Now()); ok {
+		// Conditional update so concurrent requests cannot both accept the same code
+		result := s.db.WithContext(ctx).Model(&model.User{}).
+			Where("id = ? AND totp_last_used_step < ?", user.ID, step).
+			Update("totp_last_used_step", step)
+		if result.Error != nil {
+			return fmt.Errorf("failed to verify code: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("verification code has already been used")
+		}
+		user.TOTPLastUsedStep = step
+		return nil
+	}
+
+	result := s.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to verify code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("invalid verification code")
+	}
+
+	logger.Info("Recovery code used",
+		logger.Uint("user_id", user.ID),
+	)
+	return nil
+}
+
+// RegisterFailedAttempt counts a wrong code for a pending MFA token and reports
+// whether the token has run out of attempts
+func (s *MFAService) RegisterFailedAttempt(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	key := mfaAttemptsKeyPrefix + jti
+
+	attempts, err := s.client.Incr(ctx, key).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to record attempt: %w", err)
+	}
+	if attempts == 1 {
+		s.client.ExpireAt(ctx, key, expiresAt)
+	}
+	return attempts >= mfaMaxAttempts, nil
+}
+
+// totpSecret decrypts the user's stored TOTP secret
+func (s *MFAService) totpSecret(user *model.User) (string, error) {
+	return s.secrets.open(user.ID, user.TOTPSecret)
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores a new set
+func (s *MFAService) replaceRecoveryCodes(db *gorm.DB, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]*model.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, &model.RecoveryCode{
+			UserID:   userID,
+			CodeHash: hashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := db.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code formatted as "xxxxx-xxxxx"
+func generateRecoveryCode() (string, error) {
+	bytes := make([]byte, 7)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(totpEncoding.EncodeToString(bytes))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode strips separators and case so codes can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -15,14 +15,51 @@ import (
 	"time"
 
 	"linke/config"
+	"linke/internal/logger"
 
+	"github.com/go-redis/redis/v8"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/github"
 	"golang.org/x/oauth2/google"
 )
 
+const (
+	// oauthStateTTL bounds how long a user can take to complete the provider's login page
+	oauthStateTTL = 10 * time.Minute
+
+	oauthStateKeyPrefix = "oauth_state:"
+)
+
+// reservedProviderKeys cannot be used for OIDC providers because they clash with built-in
+// providers or with other routes under /auth
+var reservedProviderKeys = map[string]bool{
+	"google":       true,
+	"github":       true,
+	"telegram":     true,
+	"local":        true,
+	"passkey":      true,
+	"magic_link":   true,
+	"providers":    true,
+	"profile":      true,
+	"verify-email": true,
+}
+
 type OAuthService struct {
-	cfg *config.Config
+	cfg    *config.Config
+	client *redis.Client
+
+	oidcProviders map[string]*OIDCProvider
+	oidcOrder     []string // Provider keys in configuration order
+}
+
+// OAuthState is stored in Redis for each login attempt, keyed by the random state parameter.
+// It is consumed by the callback, so each state can only be used once.
+type OAuthState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"` // PKCE verifier whose S256 challenge was sent to the provider
+	ReturnTo     string `json:"return_to,omitempty"`
+	Nonce        string `json:"nonce,omitempty"`        // Expected in the ID token of OIDC providers
+	LinkUserID   uint   `json:"link_user_id,omitempty"` // Set when an authenticated user links the provider account instead of logging in
 }
 
 type UserInfo struct {
@@ -32,6 +69,8 @@ type UserInfo struct {
 	Username string `json:"username"`
 	Avatar   string `json:"avatar"`
 	Provider string `json:"provider"`
+	// EmailVerified is only reported by OIDC providers
+	EmailVerified bool `json:"email_verified,omitempty"`
 }
 
 type TelegramUser struct {
@@ -44,39 +83,173 @@ type TelegramUser struct {
 	Hash      string `json:"hash"`
 }
 
-func NewOAuthService(cfg *config.Config) *OAuthService {
-	return &OAuthService{
-		cfg: cfg,
+func NewOAuthService(cfg *config.Config, client *redis.Client) *OAuthService {
+	o := &OAuthService{
+		cfg:           cfg,
+		client:        client,
+		oidcProviders: make(map[string]*OIDCProvider),
+	}
+
+	for _, providerCfg := range cfg.OAuth2.OIDCProviders {
+		if reservedProviderKeys[providerCfg.Key] {
+			logger.Error("Ignoring OIDC provider with reserved key", logger.String("provider", providerCfg.Key))
+			continue
+		}
+		if _, exists := o.oidcProviders[providerCfg.Key]; exists {
+			logger.Error("Ignoring duplicate OIDC provider", logger.String("provider", providerCfg.Key))
+			continue
+		}
+		o.oidcProviders[providerCfg.Key] = NewOIDCProvider(providerCfg, nil)
+		o.oidcOrder = append(o.oidcOrder, providerCfg.Key)
 	}
+
+	return o
 }
 
-func (o *OAuthService) GetAuthURL(provider, state string) (string, error) {
-	config := o.getOAuth2Config(provider)
-	if config == nil {
-		return "", fmt.Errorf("unsupported provider: %s", provider)
+// OIDCProviders returns the configured generic OpenID Connect providers
+func (o *OAuthService) OIDCProviders() []*OIDCProvider {
+	providers := make([]*OIDCProvider, 0, len(o.oidcOrder))
+	for _, key := range o.oidcOrder {
+		providers = append(providers, o.oidcProviders[key])
 	}
+	return providers
+}
 
-	return config.AuthCodeURL(state, oauth2.AccessTypeOffline), nil
+// IsOIDCProvider reports whether provider is one of the configured OpenID Connect providers
+func (o *OAuthService) IsOIDCProvider(provider string) bool {
+	_, ok := o.oidcProviders[provider]
+	return ok
 }
 
-func (o *OAuthService) ExchangeCodeForToken(ctx context.Context, provider, code string) (*oauth2.Token, error) {
-	config := o.getOAuth2Config(provider)
-	if config == nil {
-		return nil, fmt.Errorf("unsupported provider: %s", provider)
+// BeginAuth starts a login with the provider. It stores a random state together with a
+// PKCE code verifier and the optional return URL, and returns the provider's authorization URL.
+func (o *OAuthService) BeginAuth(ctx context.Context, provider, returnTo string) (string, error) {
+	return o.begin(ctx, &OAuthState{Provider: provider, ReturnTo: returnTo})
+}
+
+// BeginLink starts linking a provider account to the user. The callback attaches the
+// provider account to the user instead of logging in.
+func (o *OAuthService) BeginLink(ctx context.Context, provider string, userID uint, returnTo string) (string, error) {
+	return o.begin(ctx, &OAuthState{Provider: provider, ReturnTo: returnTo, LinkUserID: userID})
+}
+
+func (o *OAuthService) begin(ctx context.Context, stored *OAuthState) (string, error) {
+	provider := stored.Provider
+	config, err := o.oauth2Config(ctx, provider)
+	if err != nil {
+		return "", err
+	}
+
+	if stored.ReturnTo != "" && !o.IsAllowedReturnURL(stored.ReturnTo) {
+		return "", fmt.Errorf("return_to is not an allowed URL")
 	}
 
-	return config.Exchange(ctx, code)
+	state, err := generateOpaqueToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+
+	stored.CodeVerifier = oauth2.GenerateVerifier()
+	opts := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(stored.CodeVerifier)}
+
+	// OIDC providers echo the nonce in the ID token, binding it to this login attempt
+	if o.IsOIDCProvider(provider) {
+		if stored.Nonce, err = generateOpaqueToken(32); err != nil {
+			return "", fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", stored.Nonce))
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := o.client.Set(ctx, oauthStateKeyPrefix+state, data, oauthStateTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to store state: %w", err)
+	}
+
+	return config.AuthCodeURL(state, opts...), nil
 }
 
-func (o *OAuthService) GetUserInfo(ctx context.Context, provider string, token *oauth2.Token) (*UserInfo, error) {
+// ConsumeState looks up and deletes the state of a login attempt. It fails if the state is
+// unknown, expired, already used or was issued for a different provider.
+func (o *OAuthService) ConsumeState(ctx context.Context, provider, state string) (*OAuthState, error) {
+	if state == "" {
+		return nil, fmt.Errorf("invalid state parameter")
+	}
+
+	data, err := o.client.GetDel(ctx, oauthStateKeyPrefix+state).Bytes()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("invalid or expired state parameter")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state: %w", err)
+	}
+
+	var stored OAuthState
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid state parameter")
+	}
+	if stored.Provider != provider {
+		return nil, fmt.Errorf("invalid state parameter")
+	}
+
+	return &stored, nil
+}
+
+// IsAllowedReturnURL reports whether the SPA may be redirected to rawURL after login.
+// The URL must be absolute, use http(s) and match the origin, and path prefix if given,
+// of one of the configured allowed return URLs.
+func (o *OAuthService) IsAllowedReturnURL(rawURL string) bool {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" || target.User != nil {
+		return false
+	}
+
+	for _, allowed := range o.cfg.OAuth2.AllowedReturnURLs {
+		base, err := url.Parse(allowed)
+		if err != nil {
+			continue
+		}
+		if !strings.EqualFold(base.Scheme, target.Scheme) || !strings.EqualFold(base.Host, target.Host) {
+			continue
+		}
+
+		prefix := strings.TrimSuffix(base.Path, "/")
+		if prefix == "" || target.Path == prefix || strings.HasPrefix(target.Path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *OAuthService) ExchangeCodeForToken(ctx context.Context, provider, code, codeVerifier string) (*oauth2.Token, error) {
+	config, err := o.oauth2Config(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	if oidc, ok := o.oidcProviders[provider]; ok {
+		ctx = oidc.Context(ctx)
+	}
+	return config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+}
+
+// GetUserInfo fetches the profile of the user who logged in. For OIDC providers the ID token
+// in token is verified and must carry nonce.
+func (o *OAuthService) GetUserInfo(ctx context.Context, provider string, token *oauth2.Token, nonce string) (*UserInfo, error) {
 	switch provider {
 	case "google":
 		return o.getGoogleUserInfo(ctx, token)
 	case "github":
 		return o.getGitHubUserInfo(ctx, token)
-	default:
-		return nil, fmt.Errorf("unsupported provider: %s", provider)
 	}
+
+	if oidc, ok := o.oidcProviders[provider]; ok {
+		return oidc.GetUserInfo(ctx, token, nonce)
+	}
+	return nil, fmt.Errorf("unsupported provider: %s", provider)
 }
 
 func (o *OAuthService) VerifyTelegramAuth(data map[string]string) (*UserInfo, error) {
@@ -130,6 +303,22 @@ func (o *OAuthService) GetTelegramLoginURL() string {
 		url.QueryEscape(o.cfg.OAuth2.TelegramRedirectURL))
 }
 
+// oauth2Config returns the OAuth2 configuration of a built-in or OIDC provider
+func (o *OAuthService) oauth2Config(ctx context.Context, provider string) (*oauth2.Config, error) {
+	if oidc, ok := o.oidcProviders[provider]; ok {
+		if !oidc.Enabled() {
+			return nil, fmt.Errorf("provider %s is not configured", provider)
+		}
+		return oidc.OAuth2Config(ctx)
+	}
+
+	config := o.getOAuth2Config(provider)
+	if config == nil {
+		return nil, fmt.Errorf("unsupported provider: %s", provider)
+	}
+	return config, nil
+}
+
 func (o *OAuthService) getOAuth2Config(provider string) *oauth2.Config {
 	switch provider {
 	case "google":
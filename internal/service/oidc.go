@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"linke/config"
+	"linke/internal/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// oidcDiscoveryTTL is how long discovery documents and signing keys are cached
+	oidcDiscoveryTTL = time.Hour
+
+	// oidcKeyRefreshInterval limits JWKS refetches triggered by unknown key IDs
+	oidcKeyRefreshInterval = time.Minute
+
+	oidcHTTPTimeout = 10 * time.Second
+)
+
+// oidcSigningMethods are the ID token algorithms accepted from providers
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// oidcDiscovery is the subset of the OpenID Provider Metadata that is used
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider is a generic OpenID Connect provider configured from the environment.
+// Endpoints are discovered from the issuer's .well-known/openid-configuration and ID
+// tokens are verified against the issuer's JWKS.
+type OIDCProvider struct {
+	cfg        config.OIDCProviderConfig
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	discoveredAt  time.Time
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(cfg config.OIDCProviderConfig, httpClient *http.Client) *OIDCProvider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: oidcHTTPTimeout}
+	}
+	return &OIDCProvider{
+		cfg:        cfg,
+		httpClient: httpClient,
+	}
+}
+
+// Key returns the provider key used in URLs and stored as the user's provider
+func (p *OIDCProvider) Key() string {
+	return p.cfg.Key
+}
+
+// DisplayName returns the human readable provider name
+func (p *OIDCProvider) DisplayName() string {
+	return p.cfg.DisplayName
+}
+
+// Enabled reports whether the provider has the settings required to log in
+func (p *OIDCProvider) Enabled() bool {
+	return p.cfg.Issuer != "" && p.cfg.ClientID != ""
+}
+
+// OAuth2Config returns the OAuth2 client configuration using the discovered endpoints
+func (p *OIDCProvider) OAuth2Config(ctx context.Context) (*oauth2.Config, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       p.cfg.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}, nil
+}
+
+// Context returns ctx carrying the provider's HTTP client, for use with oauth2.Config methods
+func (p *OIDCProvider) Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+}
+
+// GetUserInfo verifies the ID token returned with the OAuth2 token and maps its claims to
+// UserInfo. Claims missing from the ID token are filled in from the userinfo endpoint.
+func (p *OIDCProvider) GetUserInfo(ctx context.Context, token *oauth2.Token, nonce string) (*UserInfo, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("no id_token in token response")
+	}
+
+	claims, err := p.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if claimString(claims, p.cfg.EmailClaim) == "" {
+		if err := p.mergeUserinfo(ctx, token, claims); err != nil {
+			logger.Warn("Failed to fetch OIDC userinfo",
+				logger.String("provider", p.cfg.Key),
+				logger.Error2("error", err),
+			)
+		}
+	}
+
+	userInfo := &UserInfo{
+		ID:       claimString(claims, p.cfg.SubjectClaim),
+		Email:    claimString(claims, p.cfg.EmailClaim),
+		Name:     claimString(claims, p.cfg.NameClaim),
+		Username: claimString(claims, p.cfg.UsernameClaim),
+		Avatar:   claimString(claims, p.cfg.AvatarClaim),
+		Provider: p.cfg.Key,
+	}
+	if verified, ok := claims["email_verified"].(bool); ok {
+		userInfo.EmailVerified = verified
+	}
+	if userInfo.ID == "" {
+		return nil, fmt.Errorf("id_token has no %s claim", p.cfg.SubjectClaim)
+	}
+
+	return userInfo, nil
+}
+
+// VerifyIDToken checks the ID token signature against the issuer's JWKS and validates
+// the issuer, audience, expiry and nonce claims
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (jwt.MapClaims, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, discovery.JWKSURI, kid)
+	},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	// With several audiences the token must have been issued to this client
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.cfg.ClientID {
+			return nil, fmt.Errorf("invalid id_token: authorized party mismatch")
+		}
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); nonce == "" || tokenNonce != nonce {
+		return nil, fmt.Errorf("invalid id_token: nonce mismatch")
+	}
+
+	return claims, nil
+}
+
+// mergeUserinfo adds claims from the userinfo endpoint that are missing in claims
+func (p *OIDCProvider) mergeUserinfo(ctx context.Context, token *oauth2.Token, claims jwt.MapClaims) error {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return err
+	}
+	if discovery.UserinfoEndpoint == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.UserinfoEndpoint, nil)
+	if err != nil {
+		return err
+	}
+	token.SetAuthHeader(req)
+
+	var userinfo map[string]interface{}
+	if err := p.getJSON(req, &userinfo); err != nil {
+		return err
+	}
+
+	// The userinfo response must belong to the same subject as the ID token
+	if sub, _ := userinfo["sub"].(string); sub != claims["sub"] {
+		return fmt.Errorf("userinfo subject mismatch")
+	}
+
+	for name, value := range userinfo {
+		if _, exists := claims[name]; !exists {
+			claims[name] = value
+		}
+	}
+	return nil
+}
+
+func (p *OIDCProvider) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveredAt) < oidcDiscoveryTTL {
+		return p.discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var discovery oidcDiscovery
+	if err := p.getJSON(req, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider %s: %w", p.cfg.Key, err)
+	}
+
+	// The issuer in the document must match the configured one exactly (OIDC Discovery 4.3)
+	if discovery.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("OIDC provider %s: issuer mismatch, got %q", p.cfg.Key, discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC provider %s: incomplete discovery document", p.cfg.Key)
+	}
+
+	p.discovery = &discovery
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+// getKey returns the signing key with the given kid, refetching the JWKS when the key is
+// unknown so that provider key rotation is picked up
+func (p *OIDCProvider) getKey(ctx context.Context, jwksURI, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stale := time.Since(p.keysFetchedAt) > oidcDiscoveryTTL
+	if key, ok := p.lookupKey(kid); ok && !stale {
+		return key, nil
+	}
+	if !stale && time.Since(p.keysFetchedAt) < oidcKeyRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks JWKS
+	if err := p.getJSON(req, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for i := range jwks.Keys {
+		jwk := &jwks.Keys[i]
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwkToPublicKey(jwk)
+		if err != nil {
+			logger.Debug("Skipping unsupported OIDC signing key",
+				logger.String("provider", p.cfg.Key),
+				logger.String("kid", jwk.Kid),
+				logger.Error2("error", err),
+			)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a key by kid; tokens without a kid are accepted only if the set has a single key
+func (p *OIDCProvider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *OIDCProvider) getJSON(req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", req.URL.Redacted(), resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// claimString returns a string claim, or an empty string if it is missing or not a string
+func claimString(claims jwt.MapClaims, name string) string {
+	if name == "" {
+		return ""
+	}
+	value, _ := claims[name].(string)
+	return value
+}
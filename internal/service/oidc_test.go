@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"linke/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const testOIDCClientID = "linke-client"
+
+// fakeIssuer is an OpenID provider serving discovery, JWKS and userinfo from an httptest.Server
+type fakeIssuer struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	keys     map[string]ed25519.PrivateKey
+	jwksHits int
+	userinfo map[string]interface{}
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	issuer := &fakeIssuer{keys: make(map[string]ed25519.PrivateKey)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeTestJSON(w, oidcDiscovery{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			UserinfoEndpoint:      issuer.server.URL + "/userinfo",
+			JWKSURI:               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		issuer.jwksHits++
+
+		var jwks JWKS
+		for kid, key := range issuer.keys {
+			jwk, err := publicKeyToJWK(key.Public())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			jwk.Kid = kid
+			jwk.Use = "sig"
+			jwks.Keys = append(jwks.Keys, *jwk)
+		}
+		writeTestJSON(w, jwks)
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		writeTestJSON(w, issuer.userinfo)
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	issuer.addKey(t, "key-1")
+	return issuer
+}
+
+func writeTestJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (f *fakeIssuer) addKey(t *testing.T, kid string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys[kid] = key
+}
+
+func (f *fakeIssuer) hits() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.jwksHits
+}
+
+// claims returns valid ID token claims for nonce
+func (f *fakeIssuer) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            f.server.URL,
+		"sub":            "subject-1",
+		"aud":            testOIDCClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"name":           "Alice",
+	}
+}
+
+// sign signs claims with the issuer's key kid
+func (f *fakeIssuer) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	f.mu.Lock()
+	key := f.keys[kid]
+	f.mu.Unlock()
+	return signTestIDToken(t, kid, key, claims)
+}
+
+func signTestIDToken(t *testing.T, kid string, key ed25519.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("sign id_token: %v", err)
+	}
+	return raw
+}
+
+func (f *fakeIssuer) provider() *OIDCProvider {
+	return NewOIDCProvider(config.OIDCProviderConfig{
+		Key:           "fake",
+		DisplayName:   "Fake",
+		Issuer:        f.server.URL,
+		ClientID:      testOIDCClientID,
+		SubjectClaim:  "sub",
+		EmailClaim:    "email",
+		NameClaim:     "name",
+		UsernameClaim: "preferred_username",
+		AvatarClaim:   "picture",
+	}, f.server.Client())
+}
+
+func oauth2TokenWithIDToken(rawIDToken string) *oauth2.Token {
+	return (&oauth2.Token{AccessToken: "access-token", TokenType: "Bearer"}).
+		WithExtra(map[string]interface{}{"id_token": rawIDToken})
+}
+
+func TestOIDCGetUserInfo(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	p := issuer.provider()
+
+	token := oauth2TokenWithIDToken(issuer.sign(t, "key-1", issuer.claims("nonce-1")))
+	userInfo, err := p.GetUserInfo(context.Background(), token, "nonce-1")
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	if userInfo.ID != "subject-1" || userInfo.Email != "alice@example.com" || !userInfo.EmailVerified ||
+		userInfo.Name != "Alice" || userInfo.Provider != "fake" {
+		t.Fatalf("GetUserInfo = %+v", userInfo)
+	}
+}
+
+func TestOIDCVerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	p := issuer.provider()
+	ctx := context.Background()
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	withClaim := func(name string, value interface{}) jwt.MapClaims {
+		claims := issuer.claims("nonce-1")
+		claims[name] = value
+		return claims
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+		want  string
+	}{
+		{"bad signature", signTestIDToken(t, "key-1", otherKey, issuer.claims("nonce-1")), "nonce-1", "signature is invalid"},
+		{"wrong audience", issuer.sign(t, "key-1", withClaim("aud", "other-client")), "nonce-1", "invalid audience"},
+		{"wrong issuer", issuer.sign(t, "key-1", withClaim("iss", "https://evil.example.com")), "nonce-1", "invalid issuer"},
+		{"expired", issuer.sign(t, "key-1", withClaim("exp", time.Now().Add(-time.Hour).Unix())), "nonce-1", "expired"},
+		{"nonce mismatch", issuer.sign(t, "key-1", issuer.claims("nonce-1")), "nonce-2", "nonce mismatch"},
+		{"missing nonce", issuer.sign(t, "key-1", issuer.claims("")), "", "nonce mismatch"},
+		{"unauthorized party", issuer.sign(t, "key-1", withClaim("aud", []string{testOIDCClientID, "other-client"})), "nonce-1", "authorized party mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.VerifyIDToken(ctx, tt.token, tt.nonce)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("VerifyIDToken error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCUnknownKeyRefetchesJWKS(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	p := issuer.provider()
+	ctx := context.Background()
+
+	if _, err := p.VerifyIDToken(ctx, issuer.sign(t, "key-1", issuer.claims("nonce-1")), "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if hits := issuer.hits(); hits != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", hits)
+	}
+
+	// The issuer rotates to a new key
+	issuer.addKey(t, "key-2")
+	rotated := issuer.sign(t, "key-2", issuer.claims("nonce-1"))
+
+	// Refetches triggered by unknown key IDs are rate limited
+	if _, err := p.VerifyIDToken(ctx, rotated, "nonce-1"); err == nil || !strings.Contains(err.Error(), "unknown signing key") {
+		t.Fatalf("VerifyIDToken error = %v, want unknown signing key", err)
+	}
+	if hits := issuer.hits(); hits != 1 {
+		t.Fatalf("JWKS fetched %d times within the refresh interval, want 1", hits)
+	}
+
+	p.mu.Lock()
+	p.keysFetchedAt = time.Now().Add(-2 * oidcKeyRefreshInterval)
+	p.mu.Unlock()
+
+	if _, err := p.VerifyIDToken(ctx, rotated, "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken after key rotation: %v", err)
+	}
+	if hits := issuer.hits(); hits != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", hits)
+	}
+
+	// Keys still in the set are served from the cache
+	if _, err := p.VerifyIDToken(ctx, issuer.sign(t, "key-1", issuer.claims("nonce-1")), "nonce-1"); err != nil {
+		t.Fatalf("VerifyIDToken with cached key: %v", err)
+	}
+	if hits := issuer.hits(); hits != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", hits)
+	}
+}
+
+func TestOIDCEmailFromUserinfo(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	p := issuer.provider()
+
+	claims := issuer.claims("nonce-1")
+	delete(claims, "email")
+	delete(claims, "email_verified")
+	token := oauth2TokenWithIDToken(issuer.sign(t, "key-1", claims))
+
+	issuer.userinfo = map[string]interface{}{
+		"sub":            "subject-1",
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"name":           "Userinfo Name",
+	}
+	userInfo, err := p.GetUserInfo(context.Background(), token, "nonce-1")
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	if userInfo.Email != "alice@example.com" || !userInfo.EmailVerified {
+		t.Fatalf("GetUserInfo email = %q (verified %v), want it from userinfo", userInfo.Email, userInfo.EmailVerified)
+	}
+	// Claims present in the ID token win over userinfo
+	if userInfo.Name != "Alice" {
+		t.Fatalf("GetUserInfo name = %q, want the ID token claim", userInfo.Name)
+	}
+
+	// Userinfo for another subject is ignored
+	issuer.userinfo["sub"] = "subject-2"
+	userInfo, err = p.GetUserInfo(context.Background(), token, "nonce-1")
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	if userInfo.Email != "" {
+		t.Fatalf("GetUserInfo email = %q from userinfo of another subject", userInfo.Email)
+	}
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	p := issuer.provider()
+	p.cfg.Issuer = strings.TrimSuffix(issuer.server.URL, "/") + "/"
+
+	if _, err := p.OAuth2Config(context.Background()); err == nil {
+		t.Fatal("OAuth2Config accepted a discovery document for another issuer")
+	}
+}
@@ -0,0 +1,563 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrNotOrganizationMember is returned when the user is not a member of the organization,
+	// or the organization does not exist
+	ErrNotOrganizationMember = errors.New("you are not a member of this organization")
+
+	// ErrOrganizationMemberNotFound is returned when the user to act on is not a member of the organization
+	ErrOrganizationMemberNotFound = errors.New("member not found")
+
+	// ErrAlreadyOrganizationMember is returned when joining an organization the user is already a member of
+	ErrAlreadyOrganizationMember = errors.New("you are already a member of this organization")
+
+	// ErrOrganizationOwner is returned when the owner tries to leave, or someone tries to remove
+	// or demote the owner. Ownership has to be transferred first.
+	ErrOrganizationOwner = errors.New("the owner cannot leave or be removed, transfer ownership first")
+
+	// ErrOrganizationRoleTooLow is returned when the member's role in the organization does not allow the action
+	ErrOrganizationRoleTooLow = errors.New("your role in the organization does not allow this")
+)
+
+// OrganizationService manages organizations, their members and the active organization of sessions
+type OrganizationService struct {
+	db                *gorm.DB
+	inviteCodeService *InviteCodeService
+	auditService      *AuditService
+}
+
+// CreateOrganizationRequest represents the request to create an organization
+type CreateOrganizationRequest struct {
+	Name        string `json:"name" binding:"required,max=100" example:"Acme"`
+	Description string `json:"description" binding:"max=255" example:"The Acme team"`
+}
+
+// UpdateOrganizationRequest represents the request to rename an organization and/or change its description
+type UpdateOrganizationRequest struct {
+	Name        string `json:"name" binding:"omitempty,max=100" example:"Acme"`
+	Description string `json:"description" binding:"omitempty,max=255" example:"The Acme team"`
+}
+
+// UpdateMemberRoleRequest represents the request to change a member's role in the organization
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=admin member" example:"admin"`
+}
+
+// TransferOwnershipRequest represents the request to make another member the owner
+type TransferOwnershipRequest struct {
+	UserID uint `json:"user_id" binding:"required" example:"2"`
+}
+
+// SwitchOrganizationRequest represents the request to change the active organization of the session
+type SwitchOrganizationRequest struct {
+	OrganizationID uint `json:"organization_id" example:"1"` // 0 to clear the active organization
+}
+
+// JoinOrganizationRequest represents the request to join an organization with an invite code
+type JoinOrganizationRequest struct {
+	Code string `json:"code" binding:"required" example:"a1b2c3d4e5f6789012345678901234567890abcd"`
+}
+
+func NewOrganizationService(db *gorm.DB, inviteCodeService *InviteCodeService, auditService *AuditService) *OrganizationService {
+	return &OrganizationService{
+		db:                db,
+		inviteCodeService: inviteCodeService,
+		auditService:      auditService,
+	}
+}
+
+// Create creates an organization owned by the user
+func (s *OrganizationService) Create(ctx context.Context, userID uint, req *CreateOrganizationRequest) (*model.Organization, error) {
+	organization := &model.Organization{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		OwnerID:     userID,
+	}
+	if organization.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return fmt.Errorf("failed to create organization: %w", err)
+		}
+
+		owner := &model.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         userID,
+			Role:           model.OrganizationRoleOwner,
+		}
+		if err := tx.Create(owner).Error; err != nil {
+			return fmt.Errorf("failed to add organization owner: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Organization created",
+		logger.Uint("organization_id", organization.ID),
+		logger.Uint("owner_id", userID),
+	)
+	return organization, nil
+}
+
+// ListForUser returns the organizations the user is a member of, with the user's role in each
+func (s *OrganizationService) ListForUser(ctx context.Context, userID uint) ([]*model.OrganizationResponse, error) {
+	var memberships []*model.OrganizationMember
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+
+	result := make([]*model.OrganizationResponse, 0, len(memberships))
+	if len(memberships) == 0 {
+		return result, nil
+	}
+
+	organizationIDs := make([]uint, 0, len(memberships))
+	for _, membership := range memberships {
+		organizationIDs = append(organizationIDs, membership.OrganizationID)
+	}
+
+	var organizations []*model.Organization
+	if err := s.db.WithContext(ctx).Where("id IN ?", organizationIDs).Find(&organizations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	organizationMap := make(map[uint]*model.Organization, len(organizations))
+	for _, organization := range organizations {
+		organizationMap[organization.ID] = organization
+	}
+
+	for _, membership := range memberships {
+		organization, exists := organizationMap[membership.OrganizationID]
+		if !exists {
+			continue
+		}
+		resp := organization.ToResponse()
+		resp.Role = membership.Role
+		result = append(result, resp)
+	}
+	return result, nil
+}
+
+// GetMembership returns the user's membership in the organization with the organization loaded
+func (s *OrganizationService) GetMembership(ctx context.Context, organizationID, userID uint) (*model.OrganizationMember, error) {
+	var membership model.OrganizationMember
+	err := s.db.WithContext(ctx).Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&membership).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotOrganizationMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+
+	var organization model.Organization
+	err = s.db.WithContext(ctx).First(&organization, organizationID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotOrganizationMember
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	membership.Organization = &organization
+	return &membership, nil
+}
+
+// Update renames the organization and/or changes its description
+func (s *OrganizationService) Update(ctx context.Context, organization *model.Organization, req *UpdateOrganizationRequest) (*model.Organization, error) {
+	updates := map[string]interface{}{}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		updates["name"] = name
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		updates["description"] = description
+	}
+	if len(updates) == 0 {
+		return organization, nil
+	}
+
+	if err := s.db.WithContext(ctx).Model(organization).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+
+	logger.Info("Organization updated",
+		logger.Uint("organization_id", organization.ID),
+	)
+	return organization, nil
+}
+
+// Delete deletes the organization. Only the owner can delete it. Its members are removed,
+// its invite codes are disabled and sessions that had it active are left without an
+// active organization.
+func (s *OrganizationService) Delete(ctx context.Context, actor *model.OrganizationMember) error {
+	var organization model.Organization
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&organization, actor.OrganizationID).Error; err != nil {
+			return ErrNotOrganizationMember
+		}
+		if organization.OwnerID != actor.UserID {
+			return ErrOrganizationRoleTooLow
+		}
+
+		if err := tx.Where("organization_id = ?", organization.ID).Delete(&model.OrganizationMember{}).Error; err != nil {
+			return fmt.Errorf("failed to remove organization members: %w", err)
+		}
+		if err := tx.Model(&model.InviteCode{}).
+			Where("organization_id = ?", organization.ID).
+			Update("status", model.InviteCodeStatusDisabled).Error; err != nil {
+			return fmt.Errorf("failed to disable organization invite codes: %w", err)
+		}
+		if err := tx.Model(&model.Session{}).
+			Where("organization_id = ?", organization.ID).
+			Update("organization_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to clear active organization of sessions: %w", err)
+		}
+		if err := tx.Delete(&organization).Error; err != nil {
+			return fmt.Errorf("failed to delete organization: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Organization deleted",
+		logger.Uint("organization_id", actor.OrganizationID),
+		logger.Uint("user_id", actor.UserID),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionOrganizationDelete,
+		TargetType: model.AuditTargetOrganization,
+		TargetID:   organization.ID,
+		Before:     organization.ToResponse(),
+	})
+	return nil
+}
+
+// ListMembers returns the members of the organization with their users loaded, owner first
+func (s *OrganizationService) ListMembers(ctx context.Context, organizationID uint) ([]*model.OrganizationMember, error) {
+	var members []*model.OrganizationMember
+	if err := s.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Order("created_at ASC").
+		Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	if len(members) == 0 {
+		return members, nil
+	}
+
+	userIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		userIDs = append(userIDs, member.UserID)
+	}
+
+	var users []*model.User
+	if err := s.db.WithContext(ctx).Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to load members: %w", err)
+	}
+	userMap := make(map[uint]*model.User, len(users))
+	for _, user := range users {
+		userMap[user.ID] = user
+	}
+
+	ordered := make([]*model.OrganizationMember, 0, len(members))
+	for _, member := range members {
+		member.User = userMap[member.UserID]
+		if member.Role == model.OrganizationRoleOwner {
+			ordered = append([]*model.OrganizationMember{member}, ordered...)
+			continue
+		}
+		ordered = append(ordered, member)
+	}
+	return ordered, nil
+}
+
+// UpdateMemberRole changes the role of another member to admin or member. The actor has to
+// outrank the member's current role and hold at least the new role, so admins can promote
+// members but only the owner can demote admins.
+func (s *OrganizationService) UpdateMemberRole(ctx context.Context, actor *model.OrganizationMember, userID uint, role string) (*model.OrganizationMember, error) {
+	if role != model.OrganizationRoleAdmin && role != model.OrganizationRoleMember {
+		return nil, fmt.Errorf("invalid role: %s", role)
+	}
+	if userID == actor.UserID {
+		return nil, fmt.Errorf("you cannot change your own role")
+	}
+
+	var member *model.OrganizationMember
+	var previousRole string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, target, err := lockMembers(tx, actor.OrganizationID, actor.UserID, userID)
+		if err != nil {
+			return err
+		}
+		if target.Role == model.OrganizationRoleOwner {
+			return ErrOrganizationOwner
+		}
+		if !outranks(current, target.Role) || !current.HasRole(role) {
+			return ErrOrganizationRoleTooLow
+		}
+		previousRole = target.Role
+
+		if err := tx.Model(target).Update("role", role).Error; err != nil {
+			return fmt.Errorf("failed to update member role: %w", err)
+		}
+		member = target
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Organization member role updated",
+		logger.Uint("organization_id", actor.OrganizationID),
+		logger.Uint("actor_id", actor.UserID),
+		logger.Uint("user_id", userID),
+		logger.String("role", role),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionOrganizationMemberRole,
+		TargetType: model.AuditTargetOrganization,
+		TargetID:   actor.OrganizationID,
+		Before:     map[string]interface{}{"user_id": userID, "role": previousRole},
+		After:      map[string]interface{}{"user_id": userID, "role": role},
+	})
+	return member, nil
+}
+
+// RemoveMember removes another member from the organization. The actor has to outrank the member.
+func (s *OrganizationService) RemoveMember(ctx context.Context, actor *model.OrganizationMember, userID uint) error {
+	if userID == actor.UserID {
+		return fmt.Errorf("use leave to remove yourself from the organization")
+	}
+
+	var removed model.OrganizationMember
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, target, err := lockMembers(tx, actor.OrganizationID, actor.UserID, userID)
+		if err != nil {
+			return err
+		}
+		if target.Role == model.OrganizationRoleOwner {
+			return ErrOrganizationOwner
+		}
+		if !outranks(current, target.Role) {
+			return ErrOrganizationRoleTooLow
+		}
+
+		removed = *target
+		return removeMember(tx, target)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Organization member removed",
+		logger.Uint("organization_id", actor.OrganizationID),
+		logger.Uint("actor_id", actor.UserID),
+		logger.Uint("user_id", userID),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionOrganizationMemberRemove,
+		TargetType: model.AuditTargetOrganization,
+		TargetID:   actor.OrganizationID,
+		Before:     map[string]interface{}{"user_id": userID, "role": removed.Role},
+	})
+	return nil
+}
+
+// Leave removes the member from the organization. The owner has to transfer ownership first.
+func (s *OrganizationService) Leave(ctx context.Context, member *model.OrganizationMember) error {
+	var current model.OrganizationMember
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("organization_id = ? AND user_id = ?", member.OrganizationID, member.UserID).
+			First(&current).Error; err != nil {
+			return ErrNotOrganizationMember
+		}
+		if current.Role == model.OrganizationRoleOwner {
+			return ErrOrganizationOwner
+		}
+
+		return removeMember(tx, &current)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Organization member left",
+		logger.Uint("organization_id", member.OrganizationID),
+		logger.Uint("user_id", member.UserID),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionOrganizationMemberLeave,
+		TargetType: model.AuditTargetOrganization,
+		TargetID:   member.OrganizationID,
+		Before:     map[string]interface{}{"user_id": member.UserID, "role": current.Role},
+	})
+	return nil
+}
+
+// TransferOwnership makes another member the owner of the organization. The previous owner stays on as an admin.
+func (s *OrganizationService) TransferOwnership(ctx context.Context, actor *model.OrganizationMember, userID uint) error {
+	if userID == actor.UserID {
+		return fmt.Errorf("you already own this organization")
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var organization model.Organization
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&organization, actor.OrganizationID).Error; err != nil {
+			return ErrNotOrganizationMember
+		}
+		if organization.OwnerID != actor.UserID {
+			return ErrOrganizationRoleTooLow
+		}
+
+		current, target, err := lockMembers(tx, actor.OrganizationID, actor.UserID, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&organization).Update("owner_id", userID).Error; err != nil {
+			return fmt.Errorf("failed to transfer ownership: %w", err)
+		}
+		if err := tx.Model(target).Update("role", model.OrganizationRoleOwner).Error; err != nil {
+			return fmt.Errorf("failed to update new owner: %w", err)
+		}
+		if err := tx.Model(current).Update("role", model.OrganizationRoleAdmin).Error; err != nil {
+			return fmt.Errorf("failed to update previous owner: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Organization ownership transferred",
+		logger.Uint("organization_id", actor.OrganizationID),
+		logger.Uint("previous_owner_id", actor.UserID),
+		logger.Uint("owner_id", userID),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionOrganizationTransfer,
+		TargetType: model.AuditTargetOrganization,
+		TargetID:   actor.OrganizationID,
+		Before:     map[string]uint{"owner_id": actor.UserID},
+		After:      map[string]uint{"owner_id": userID},
+	})
+	return nil
+}
+
+// Join adds the user to the organization of an organization invite code, recording the use of the code
+func (s *OrganizationService) Join(ctx context.Context, userID uint, code string, client ClientInfo) (*model.OrganizationMember, error) {
+	inviteCode, err := s.inviteCodeService.ValidateInviteCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if inviteCode.OrganizationID == nil {
+		return nil, fmt.Errorf("this invite code does not belong to an organization")
+	}
+
+	organizationID := *inviteCode.OrganizationID
+	if _, err := s.GetMembership(ctx, organizationID, userID); err == nil {
+		return nil, ErrAlreadyOrganizationMember
+	} else if !errors.Is(err, ErrNotOrganizationMember) {
+		return nil, err
+	}
+
+	if _, err := s.inviteCodeService.UseInviteCode(ctx, code, userID, client.IPAddress, client.UserAgent); err != nil {
+		return nil, err
+	}
+
+	logger.Info("User joined organization",
+		logger.Uint("organization_id", organizationID),
+		logger.Uint("user_id", userID),
+		logger.Uint("invite_code_id", inviteCode.ID),
+	)
+	return s.GetMembership(ctx, organizationID, userID)
+}
+
+// SetActiveOrganization makes the organization the active one of the user's session, so that
+// access tokens issued for the session carry it. organizationID 0 clears it.
+func (s *OrganizationService) SetActiveOrganization(ctx context.Context, userID, sessionID, organizationID uint) error {
+	var value interface{}
+	if organizationID != 0 {
+		if _, err := s.GetMembership(ctx, organizationID, userID); err != nil {
+			return err
+		}
+		value = organizationID
+	}
+
+	result := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("organization_id", value)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("session has been revoked")
+	}
+	return nil
+}
+
+// lockMembers locks and returns the memberships of the actor and the target user in the organization
+func lockMembers(tx *gorm.DB, organizationID, actorID, userID uint) (*model.OrganizationMember, *model.OrganizationMember, error) {
+	var members []*model.OrganizationMember
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND user_id IN ?", organizationID, []uint{actorID, userID}).
+		Find(&members).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get members: %w", err)
+	}
+
+	var actor, target *model.OrganizationMember
+	for _, member := range members {
+		switch member.UserID {
+		case actorID:
+			actor = member
+		case userID:
+			target = member
+		}
+	}
+	if actor == nil {
+		return nil, nil, ErrNotOrganizationMember
+	}
+	if target == nil {
+		return nil, nil, ErrOrganizationMemberNotFound
+	}
+	return actor, target, nil
+}
+
+// removeMember deletes the membership and clears the organization from the member's sessions
+func removeMember(tx *gorm.DB, member *model.OrganizationMember) error {
+	if err := tx.Delete(member).Error; err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	if err := tx.Model(&model.Session{}).
+		Where("user_id = ? AND organization_id = ?", member.UserID, member.OrganizationID).
+		Update("organization_id", nil).Error; err != nil {
+		return fmt.Errorf("failed to clear active organization of sessions: %w", err)
+	}
+	return nil
+}
+
+// outranks reports whether the member's role is more privileged than role
+func outranks(member *model.OrganizationMember, role string) bool {
+	return model.OrganizationRoleRank(member.Role) > model.OrganizationRoleRank(role)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"linke/internal/model"
+)
+
+func TestOrganizationJoinAndLeave(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	auditService := NewAuditService(db)
+	inviteCodeService := NewInviteCodeService(db, auditService)
+	organizationService := NewOrganizationService(db, inviteCodeService, auditService)
+
+	owner := newTestUser(t, db, "owner@example.com")
+	user := newTestUser(t, db, "member@example.com")
+
+	organization, err := organizationService.Create(ctx, owner.ID, &CreateOrganizationRequest{Name: "Acme"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	inviteCode, err := inviteCodeService.CreateOrganizationInviteCode(ctx, owner.ID, organization.ID, &CreateInviteCodeRequest{MaxUses: 5})
+	if err != nil {
+		t.Fatalf("CreateOrganizationInviteCode: %v", err)
+	}
+
+	member, err := organizationService.Join(ctx, user.ID, inviteCode.Code, ClientInfo{})
+	if err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if member.Role != model.OrganizationRoleMember {
+		t.Fatalf("Join role = %q, want %q", member.Role, model.OrganizationRoleMember)
+	}
+
+	if _, err := organizationService.Join(ctx, user.ID, inviteCode.Code, ClientInfo{}); !errors.Is(err, ErrAlreadyOrganizationMember) {
+		t.Fatalf("second Join error = %v, want ErrAlreadyOrganizationMember", err)
+	}
+	// A concurrent join that got past the membership check in Join
+	if _, err := inviteCodeService.UseInviteCode(ctx, inviteCode.Code, user.ID, "", ""); !errors.Is(err, ErrAlreadyOrganizationMember) {
+		t.Fatalf("UseInviteCode error = %v, want ErrAlreadyOrganizationMember", err)
+	}
+
+	var stored model.InviteCode
+	if err := db.First(&stored, inviteCode.ID).Error; err != nil {
+		t.Fatalf("load invite code: %v", err)
+	}
+	if stored.UsedCount != 1 {
+		t.Fatalf("invite code used %d times for one membership, want 1", stored.UsedCount)
+	}
+	var usages int64
+	if err := db.Model(&model.InviteCodeUsage{}).Where("invite_code_id = ?", inviteCode.ID).Count(&usages).Error; err != nil || usages != 1 {
+		t.Fatalf("invite code usage records = %d, %v, want 1", usages, err)
+	}
+
+	// The actor of requests is set by the auth middleware
+	if err := organizationService.Leave(WithAuditActor(ctx, user.ID), member); err != nil {
+		t.Fatalf("Leave: %v", err)
+	}
+	if _, err := organizationService.GetMembership(ctx, organization.ID, user.ID); !errors.Is(err, ErrNotOrganizationMember) {
+		t.Fatalf("GetMembership after Leave error = %v, want ErrNotOrganizationMember", err)
+	}
+
+	for _, action := range []string{model.AuditActionOrganizationMemberJoin, model.AuditActionOrganizationMemberLeave} {
+		var event model.AuditEvent
+		if err := db.Where("action = ?", action).First(&event).Error; err != nil {
+			t.Fatalf("%s audit event: %v", action, err)
+		}
+		if event.ActorID == nil || *event.ActorID != user.ID || event.TargetID != organization.ID {
+			t.Fatalf("%s audit event = %+v", action, event)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"linke/config"
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// passkeyCeremonyTTL is how long a started registration or login ceremony stays valid
+	passkeyCeremonyTTL = 5 * time.Minute
+
+	passkeyCeremonyKeyPrefix = "webauthn_ceremony:"
+)
+
+type PasskeyRegisterFinishRequest struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`                     // Returned by the begin step
+	Name       string          `json:"name" binding:"max=100"`                             // Optional label for the passkey
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"` // PublicKeyCredential from navigator.credentials.create()
+}
+
+type PasskeyLoginFinishRequest struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`                     // Returned by the begin step
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"` // PublicKeyCredential from navigator.credentials.get()
+}
+
+// PasskeyCeremonyResponse carries the options to pass to the browser WebAuthn API
+// and the ID the client must send back with the finish step
+type PasskeyCeremonyResponse struct {
+	CeremonyID string      `json:"ceremony_id"`
+	Options    interface{} `json:"options"` // PublicKeyCredentialCreationOptions or PublicKeyCredentialRequestOptions
+}
+
+// passkeyCeremony is the server side state of a ceremony, kept in Redis until the finish step
+type passkeyCeremony struct {
+	UserID  uint                 `json:"user_id"` // Zero for login ceremonies
+	Session webauthn.SessionData `json:"session"`
+}
+
+// PasskeyService implements the WebAuthn relying party: passkey registration for
+// signed-in users and passwordless login with discoverable credentials
+type PasskeyService struct {
+	db           *gorm.DB
+	client       *redis.Client
+	webAuthn     *webauthn.WebAuthn
+	auditService *AuditService
+}
+
+func NewPasskeyService(db *gorm.DB, cfg *config.Config, client *redis.Client, auditService *AuditService) (*PasskeyService, error) {
+	origins := cfg.WebAuthn.RPOrigins
+	if len(origins) == 0 {
+		origins = []string{"http://localhost:8080"}
+	}
+
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: cfg.WebAuthn.RPDisplayName,
+		RPOrigins:     origins,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebAuthn configuration: %w", err)
+	}
+
+	return &PasskeyService{
+		db:           db,
+		client:       client,
+		webAuthn:     webAuthn,
+		auditService: auditService,
+	}, nil
+}
+
+// BeginRegistration starts a registration ceremony for a new passkey on the user's account
+func (s *PasskeyService) BeginRegistration(ctx context.Context, user *model.User) (*PasskeyCeremonyResponse, error) {
+	account, err := s.loadAccount(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, session, err := s.webAuthn.BeginRegistration(account,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+		webauthn.WithExclusions(webauthn.Credentials(account.WebAuthnCredentials()).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin passkey registration: %w", err)
+	}
+
+	ceremonyID, err := s.saveCeremony(ctx, user.ID, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyCeremonyResponse{
+		CeremonyID: ceremonyID,
+		Options:    creation,
+	}, nil
+}
+
+// FinishRegistration verifies the authenticator's attestation and stores the new passkey
+func (s *PasskeyService) FinishRegistration(ctx context.Context, user *model.User, req *PasskeyRegisterFinishRequest) (*model.WebAuthnCredential, error) {
+	ceremony, err := s.takeCeremony(ctx, req.CeremonyID)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.UserID != user.ID {
+		return nil, fmt.Errorf("invalid or expired ceremony")
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential: %w", err)
+	}
+
+	account, err := s.loadAccount(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := s.webAuthn.CreateCredential(account, ceremony.Session, parsed)
+	if err != nil {
+		logger.Warn("Passkey registration verification failed",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("passkey verification failed")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	record := &model.WebAuthnCredential{
+		UserID:          user.ID,
+		Name:            name,
+		CredentialID:    base64.RawURLEncoding.EncodeToString(credential.ID),
+		PublicKey:       credential.PublicKey,
+		Algorithm:       credential.Attestation.PublicKeyAlgorithm,
+		AttestationType: credential.AttestationType,
+		Transports:      strings.Join(transports, ","),
+		AAGUID:          formatAAGUID(credential.Authenticator.AAGUID),
+		SignCount:       credential.Authenticator.SignCount,
+		Attachment:      string(credential.Authenticator.Attachment),
+		UserVerified:    credential.Flags.UserVerified,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+
+	if err := s.db.WithContext(ctx).Create(record).Error; err != nil {
+		logger.Error("Failed to store passkey",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("failed to store passkey")
+	}
+
+	logger.Info("Passkey registered",
+		logger.Uint("user_id", user.ID),
+		logger.Uint("credential_id", record.ID),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionPasskeyRegister,
+		TargetType: model.AuditTargetPasskey,
+		TargetID:   record.ID,
+		After:      record.ToResponse(),
+	})
+	return record, nil
+}
+
+// ListCredentials lists the user's passkeys, newest first
+func (s *PasskeyService) ListCredentials(ctx context.Context, userID uint) ([]*model.WebAuthnCredential, error) {
+	var credentials []*model.WebAuthnCredential
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&credentials).Error; err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	return credentials, nil
+}
+
+// DeleteCredential removes one of the user's passkeys, unless it is the last way the user can log in
+func (s *PasskeyService) DeleteCredential(ctx context.Context, userID, id uint) error {
+	var credential model.WebAuthnCredential
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return fmt.Errorf("user not found")
+		}
+
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&credential).Error; err != nil {
+			return fmt.Errorf("passkey not found")
+		}
+
+		methods, err := countLoginMethods(tx, &user)
+		if err != nil {
+			return err
+		}
+		if methods <= 1 {
+			return ErrLastLoginMethod
+		}
+
+		if err := tx.Delete(&credential).Error; err != nil {
+			return fmt.Errorf("failed to delete passkey: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Passkey deleted",
+		logger.Uint("user_id", userID),
+		logger.Uint("credential_id", id),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionPasskeyDelete,
+		TargetType: model.AuditTargetPasskey,
+		TargetID:   id,
+		Before:     credential.ToResponse(),
+	})
+	return nil
+}
+
+// BeginLogin starts a passwordless login ceremony. No user is identified up front;
+// the authenticator offers its discoverable credentials for this relying party.
+func (s *PasskeyService) BeginLogin(ctx context.Context) (*PasskeyCeremonyResponse, error) {
+	assertion, session, err := s.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin passkey login: %w", err)
+	}
+
+	ceremonyID, err := s.saveCeremony(ctx, 0, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyCeremonyResponse{
+		CeremonyID: ceremonyID,
+		Options:    assertion,
+	}, nil
+}
+
+// FinishLogin verifies the assertion and returns the user owning the passkey
+func (s *PasskeyService) FinishLogin(ctx context.Context, req *PasskeyLoginFinishRequest) (*model.User, error) {
+	ceremony, err := s.takeCeremony(ctx, req.CeremonyID)
+	if err != nil {
+		return nil, err
+	}
+	if ceremony.UserID != 0 {
+		return nil, fmt.Errorf("invalid or expired ceremony")
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential: %w", err)
+	}
+
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, err := userIDFromHandle(userHandle)
+		if err != nil {
+			return nil, err
+		}
+
+		var user model.User
+		if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+			return nil, fmt.Errorf("user not found")
+		}
+		return s.loadAccount(ctx, &user)
+	}
+
+	account, credential, err := s.webAuthn.ValidatePasskeyLogin(handler, ceremony.Session, parsed)
+	if err != nil {
+		logger.Warn("Passkey login verification failed",
+			logger.Error2("error", err),
+		)
+		return nil, fmt.Errorf("passkey verification failed")
+	}
+
+	if credential.Authenticator.CloneWarning {
+		logger.Warn("Passkey signature counter did not increase, possible cloned authenticator",
+			logger.String("credential_id", base64.RawURLEncoding.EncodeToString(credential.ID)),
+		)
+		return nil, fmt.Errorf("passkey verification failed")
+	}
+
+	user := account.(*passkeyAccount).user
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&model.WebAuthnCredential{}).
+		Where("credential_id = ?", base64.RawURLEncoding.EncodeToString(credential.ID)).
+		Updates(map[string]interface{}{
+			"sign_count":    credential.Authenticator.SignCount,
+			"backup_state":  credential.Flags.BackupState,
+			"user_verified": credential.Flags.UserVerified,
+			"last_used_at":  now,
+		}).Error; err != nil {
+		logger.Error("Failed to update passkey after login",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+	}
+
+	return user, nil
+}
+
+// loadAccount wraps the user and their stored passkeys for the WebAuthn library
+func (s *PasskeyService) loadAccount(ctx context.Context, user *model.User) (*passkeyAccount, error) {
+	credentials, err := s.ListCredentials(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &passkeyAccount{user: user}
+	for _, record := range credentials {
+		credentialID, err := base64.RawURLEncoding.DecodeString(record.CredentialID)
+		if err != nil {
+			continue
+		}
+
+		var transports []protocol.AuthenticatorTransport
+		if record.Transports != "" {
+			for _, transport := range strings.Split(record.Transports, ",") {
+				transports = append(transports, protocol.AuthenticatorTransport(transport))
+			}
+		}
+
+		account.credentials = append(account.credentials, webauthn.Credential{
+			ID:              credentialID,
+			PublicKey:       record.PublicKey,
+			AttestationType: record.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				UserPresent:    true,
+				UserVerified:   record.UserVerified,
+				BackupEligible: record.BackupEligible,
+				BackupState:    record.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:     parseAAGUID(record.AAGUID),
+				SignCount:  record.SignCount,
+				Attachment: protocol.AuthenticatorAttachment(record.Attachment),
+			},
+		})
+	}
+	return account, nil
+}
+
+// saveCeremony stores the ceremony state in Redis and returns its ID
+func (s *PasskeyService) saveCeremony(ctx context.Context, userID uint, session *webauthn.SessionData) (string, error) {
+	ceremonyID, err := generateOpaqueToken(24)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ceremony id: %w", err)
+	}
+
+	data, err := json.Marshal(&passkeyCeremony{UserID: userID, Session: *session})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode ceremony: %w", err)
+	}
+
+	if err := s.client.Set(ctx, passkeyCeremonyKeyPrefix+ceremonyID, data, passkeyCeremonyTTL).Err(); err != nil {
+		return "", fmt.Errorf("failed to store ceremony: %w", err)
+	}
+	return ceremonyID, nil
+}
+
+// takeCeremony loads and deletes the ceremony state so each ceremony can only be finished once
+func (s *PasskeyService) takeCeremony(ctx context.Context, ceremonyID string) (*passkeyCeremony, error) {
+	data, err := s.client.GetDel(ctx, passkeyCeremonyKeyPrefix+ceremonyID).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, fmt.Errorf("invalid or expired ceremony")
+		}
+		return nil, fmt.Errorf("failed to load ceremony: %w", err)
+	}
+
+	var ceremony passkeyCeremony
+	if err := json.Unmarshal(data, &ceremony); err != nil {
+		return nil, fmt.Errorf("failed to decode ceremony: %w", err)
+	}
+	return &ceremony, nil
+}
+
+// passkeyAccount adapts model.User to the webauthn.User interface
+type passkeyAccount struct {
+	user        *model.User
+	credentials []webauthn.Credential
+}
+
+// WebAuthnID returns the user handle stored on the authenticator, the big-endian user ID
+func (a *passkeyAccount) WebAuthnID() []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(a.user.ID))
+	return handle
+}
+
+func (a *passkeyAccount) WebAuthnName() string {
+	return a.user.Email
+}
+
+func (a *passkeyAccount) WebAuthnDisplayName() string {
+	if a.user.Name != "" {
+		return a.user.Name
+	}
+	return a.user.Email
+}
+
+func (a *passkeyAccount) WebAuthnCredentials() []webauthn.Credential {
+	return a.credentials
+}
+
+func userIDFromHandle(handle []byte) (uint, error) {
+	if len(handle) != 8 {
+		return 0, fmt.Errorf("invalid user handle")
+	}
+	return uint(binary.BigEndian.Uint64(handle)), nil
+}
+
+// formatAAGUID formats a 16 byte AAGUID in the usual UUID notation
+func formatAAGUID(aaguid []byte) string {
+	if len(aaguid) != 16 || bytes.Equal(aaguid, make([]byte, 16)) {
+		return ""
+	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", aaguid[0:4], aaguid[4:6], aaguid[6:8], aaguid[8:10], aaguid[10:16])
+}
+
+func parseAAGUID(aaguid string) []byte {
+	if aaguid == "" {
+		return make([]byte, 16)
+	}
+	parsed, err := hex.DecodeString(strings.ReplaceAll(aaguid, "-", ""))
+	if err != nil {
+		return make([]byte, 16)
+	}
+	return parsed
+}
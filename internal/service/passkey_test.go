@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"linke/config"
+	"linke/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/glebarez/sqlite"
+	"github.com/go-redis/redis/v8"
+	"github.com/go-webauthn/webauthn/protocol"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:8080"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	// A named in-memory database with a shared cache, so that every connection of the pool
+	// sees the same database and queries can run while a transaction is open
+	dsn := "file:" + strings.ReplaceAll(t.Name(), "/", "_") + "?mode=memory&cache=shared"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&model.User{}, &model.UserIdentity{}, &model.WebAuthnCredential{}, &model.AuditEvent{},
+		&model.Organization{}, &model.OrganizationMember{}, &model.InviteCode{}, &model.InviteCodeUsage{}, &model.Session{}, &model.RecoveryCode{}); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	return db
+}
+
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func newTestUser(t *testing.T, db *gorm.DB, email string) *model.User {
+	t.Helper()
+	user := &model.User{Email: email, Provider: model.ProviderLocal, Status: model.UserStatusActive}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func newTestPasskeyService(t *testing.T) (*PasskeyService, *gorm.DB) {
+	t.Helper()
+	db := newTestDB(t)
+	cfg := &config.Config{WebAuthn: config.WebAuthnConfig{
+		RPID:          testRPID,
+		RPDisplayName: "Linke",
+		RPOrigins:     []string{testOrigin},
+	}}
+	service, err := NewPasskeyService(db, cfg, newTestRedis(t), NewAuditService(db))
+	if err != nil {
+		t.Fatalf("NewPasskeyService: %v", err)
+	}
+	return service, db
+}
+
+// virtualAuthenticator is a software authenticator holding a single ES256 discoverable credential
+type virtualAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newVirtualAuthenticator(t *testing.T) *virtualAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	credentialID := make([]byte, 32)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatalf("generate credential id: %v", err)
+	}
+	return &virtualAuthenticator{key: key, credentialID: credentialID}
+}
+
+// authenticatorData builds authenticator data with the user present and verified flags set,
+// followed by attestedCredential when it is not empty
+func (a *virtualAuthenticator) authenticatorData(attestedCredential []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	flags := byte(protocol.FlagUserPresent | protocol.FlagUserVerified)
+	if len(attestedCredential) > 0 {
+		flags |= byte(protocol.FlagAttestedCredentialData)
+	}
+
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attestedCredential...)
+}
+
+func (a *virtualAuthenticator) clientData(t *testing.T, ceremonyType, challenge string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremonyType,
+		"challenge": challenge,
+		"origin":    testOrigin,
+	})
+	if err != nil {
+		t.Fatalf("encode client data: %v", err)
+	}
+	return data
+}
+
+// create answers navigator.credentials.create() with a "none" attestation
+func (a *virtualAuthenticator) create(t *testing.T, options *protocol.CredentialCreation) json.RawMessage {
+	t.Helper()
+	a.userHandle = options.Response.User.ID.(protocol.URLEncodedBase64)
+
+	publicKey, err := cbor.Marshal(map[int]interface{}{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("encode public key: %v", err)
+	}
+
+	attested := make([]byte, 16) // zero AAGUID
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, publicKey...)
+
+	attestation, err := cbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authenticatorData(attested),
+	})
+	if err != nil {
+		t.Fatalf("encode attestation: %v", err)
+	}
+
+	return a.marshalCredential(t, map[string]interface{}{
+		"clientDataJSON":    a.clientData(t, "webauthn.create", options.Response.Challenge.String()),
+		"attestationObject": attestation,
+	})
+}
+
+// get answers navigator.credentials.get() with an assertion for userHandle
+func (a *virtualAuthenticator) get(t *testing.T, options *protocol.CredentialAssertion, userHandle []byte) json.RawMessage {
+	t.Helper()
+	authData := a.authenticatorData(nil)
+	clientData := a.clientData(t, "webauthn.get", options.Response.Challenge.String())
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("sign assertion: %v", err)
+	}
+
+	return a.marshalCredential(t, map[string]interface{}{
+		"clientDataJSON":    clientData,
+		"authenticatorData": authData,
+		"signature":         signature,
+		"userHandle":        userHandle,
+	})
+}
+
+// marshalCredential encodes a PublicKeyCredential the way browsers serialize it, with binary
+// fields of the response base64url encoded
+func (a *virtualAuthenticator) marshalCredential(t *testing.T, response map[string]interface{}) json.RawMessage {
+	t.Helper()
+	encoded := make(map[string]string, len(response))
+	for field, value := range response {
+		encoded[field] = base64.RawURLEncoding.EncodeToString(value.([]byte))
+	}
+
+	id := base64.RawURLEncoding.EncodeToString(a.credentialID)
+	data, err := json.Marshal(map[string]interface{}{
+		"id":       id,
+		"rawId":    id,
+		"type":     "public-key",
+		"response": encoded,
+	})
+	if err != nil {
+		t.Fatalf("encode credential: %v", err)
+	}
+	return data
+}
+
+// registerPasskey runs a full registration ceremony for user with authenticator
+func registerPasskey(t *testing.T, s *PasskeyService, user *model.User, authenticator *virtualAuthenticator) *model.WebAuthnCredential {
+	t.Helper()
+	ctx := context.Background()
+
+	begin, err := s.BeginRegistration(ctx, user)
+	if err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+	credential, err := s.FinishRegistration(ctx, user, &PasskeyRegisterFinishRequest{
+		CeremonyID: begin.CeremonyID,
+		Name:       "Virtual authenticator",
+		Credential: authenticator.create(t, begin.Options.(*protocol.CredentialCreation)),
+	})
+	if err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	return credential
+}
+
+// loginWithPasskey runs a login ceremony in which authenticator asserts userHandle
+func loginWithPasskey(t *testing.T, s *PasskeyService, authenticator *virtualAuthenticator, userHandle []byte) (*model.User, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	begin, err := s.BeginLogin(ctx)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	return s.FinishLogin(ctx, &PasskeyLoginFinishRequest{
+		CeremonyID: begin.CeremonyID,
+		Credential: authenticator.get(t, begin.Options.(*protocol.CredentialAssertion), userHandle),
+	})
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	s, db := newTestPasskeyService(t)
+	user := newTestUser(t, db, "alice@example.com")
+	authenticator := newVirtualAuthenticator(t)
+
+	credential := registerPasskey(t, s, user, authenticator)
+	if credential.UserID != user.ID || credential.Name != "Virtual authenticator" {
+		t.Fatalf("stored credential = %+v", credential)
+	}
+	if credential.CredentialID != base64.RawURLEncoding.EncodeToString(authenticator.credentialID) {
+		t.Fatalf("stored credential id = %s", credential.CredentialID)
+	}
+
+	var events int64
+	if err := db.Model(&model.AuditEvent{}).
+		Where("action = ? AND target_id = ?", model.AuditActionPasskeyRegister, credential.ID).
+		Count(&events).Error; err != nil || events != 1 {
+		t.Fatalf("passkey.register audit events = %d, %v", events, err)
+	}
+
+	authenticator.signCount = 1
+	loggedIn, err := loginWithPasskey(t, s, authenticator, authenticator.userHandle)
+	if err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+	if loggedIn.ID != user.ID {
+		t.Fatalf("FinishLogin returned user %d, want %d", loggedIn.ID, user.ID)
+	}
+
+	var stored model.WebAuthnCredential
+	if err := db.First(&stored, credential.ID).Error; err != nil {
+		t.Fatalf("load credential: %v", err)
+	}
+	if stored.SignCount != 1 || stored.LastUsedAt == nil {
+		t.Fatalf("credential after login: sign count %d, last used %v", stored.SignCount, stored.LastUsedAt)
+	}
+}
+
+func TestPasskeyCeremonyCannotBeReused(t *testing.T) {
+	s, db := newTestPasskeyService(t)
+	user := newTestUser(t, db, "alice@example.com")
+	authenticator := newVirtualAuthenticator(t)
+	ctx := context.Background()
+
+	begin, err := s.BeginRegistration(ctx, user)
+	if err != nil {
+		t.Fatalf("BeginRegistration: %v", err)
+	}
+	req := &PasskeyRegisterFinishRequest{
+		CeremonyID: begin.CeremonyID,
+		Credential: authenticator.create(t, begin.Options.(*protocol.CredentialCreation)),
+	}
+	if _, err := s.FinishRegistration(ctx, user, req); err != nil {
+		t.Fatalf("FinishRegistration: %v", err)
+	}
+	if _, err := s.FinishRegistration(ctx, user, req); err == nil {
+		t.Fatal("FinishRegistration accepted a finished ceremony")
+	}
+}
+
+func TestPasskeyLoginRejectsStaleSignCount(t *testing.T) {
+	s, db := newTestPasskeyService(t)
+	user := newTestUser(t, db, "alice@example.com")
+	authenticator := newVirtualAuthenticator(t)
+	registerPasskey(t, s, user, authenticator)
+
+	authenticator.signCount = 5
+	if _, err := loginWithPasskey(t, s, authenticator, authenticator.userHandle); err != nil {
+		t.Fatalf("FinishLogin: %v", err)
+	}
+
+	// A counter that did not increase points at a cloned authenticator
+	for _, signCount := range []uint32{5, 3} {
+		authenticator.signCount = signCount
+		if _, err := loginWithPasskey(t, s, authenticator, authenticator.userHandle); err == nil {
+			t.Fatalf("FinishLogin accepted sign count %d after 5", signCount)
+		}
+	}
+}
+
+func TestPasskeyLoginRejectsUserHandleMismatch(t *testing.T) {
+	s, db := newTestPasskeyService(t)
+	alice := newTestUser(t, db, "alice@example.com")
+	bob := newTestUser(t, db, "bob@example.com")
+	authenticator := newVirtualAuthenticator(t)
+	registerPasskey(t, s, alice, authenticator)
+	authenticator.signCount = 1
+
+	// Alice's credential presented as Bob's
+	bobHandle := (&passkeyAccount{user: bob}).WebAuthnID()
+	if _, err := loginWithPasskey(t, s, authenticator, bobHandle); err == nil {
+		t.Fatal("FinishLogin accepted a credential owned by another user")
+	}
+
+	unknownHandle := (&passkeyAccount{user: &model.User{ID: 9999}}).WebAuthnID()
+	if _, err := loginWithPasskey(t, s, authenticator, unknownHandle); err == nil {
+		t.Fatal("FinishLogin accepted an unknown user handle")
+	}
+
+	if _, err := loginWithPasskey(t, s, authenticator, []byte("bad")); err == nil {
+		t.Fatal("FinishLogin accepted a malformed user handle")
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"linke/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported password hashing algorithms
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// PasswordHasher hashes and verifies passwords. Verify accepts hashes of every supported
+// algorithm, and NeedsRehash reports whether a stored hash should be replaced because it
+// uses a different algorithm or weaker parameters than the current configuration.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(encodedHash, password string) (bool, error)
+	NeedsRehash(encodedHash string) bool
+}
+
+// NewPasswordHasher returns a hasher that creates hashes with the configured algorithm
+// and still verifies hashes created with the others
+func NewPasswordHasher(cfg *config.Config) (PasswordHasher, error) {
+	argon := &argon2idHasher{
+		time:    uint32(cfg.Password.Argon2Time),
+		memory:  uint32(cfg.Password.Argon2MemoryKiB),
+		threads: uint8(cfg.Password.Argon2Threads),
+	}
+	bcr := &bcryptHasher{cost: cfg.Password.BcryptCost}
+
+	switch strings.ToLower(cfg.Password.HashAlgorithm) {
+	case PasswordHashArgon2id, "":
+		if argon.time < 1 || argon.memory < 8*uint32(argon.threads) || argon.threads < 1 {
+			return nil, fmt.Errorf("invalid argon2id parameters")
+		}
+		return &multiHasher{current: argon, argon2id: argon, bcrypt: bcr}, nil
+	case PasswordHashBcrypt:
+		if bcr.cost < bcrypt.MinCost || bcr.cost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		return &multiHasher{current: bcr, argon2id: argon, bcrypt: bcr}, nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", cfg.Password.HashAlgorithm)
+	}
+}
+
+// multiHasher dispatches to the algorithm a stored hash was created with
+type multiHasher struct {
+	current  PasswordHasher
+	argon2id *argon2idHasher
+	bcrypt   *bcryptHasher
+}
+
+func (h *multiHasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h *multiHasher) Verify(encodedHash, password string) (bool, error) {
+	hasher := h.hasherFor(encodedHash)
+	if hasher == nil {
+		return false, fmt.Errorf("unknown password hash format")
+	}
+	return hasher.Verify(encodedHash, password)
+}
+
+func (h *multiHasher) NeedsRehash(encodedHash string) bool {
+	hasher := h.hasherFor(encodedHash)
+	if hasher != h.current {
+		return true
+	}
+	return hasher.NeedsRehash(encodedHash)
+}
+
+func (h *multiHasher) hasherFor(encodedHash string) PasswordHasher {
+	switch {
+	case strings.HasPrefix(encodedHash, "$argon2id$"):
+		return h.argon2id
+	case strings.HasPrefix(encodedHash, "$2a$"), strings.HasPrefix(encodedHash, "$2b$"), strings.HasPrefix(encodedHash, "$2y$"):
+		return h.bcrypt
+	default:
+		return nil
+	}
+}
+
+// argon2idHasher encodes hashes in the PHC string format:
+// $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<hash>
+type argon2idHasher struct {
+	time    uint32
+	memory  uint32 // KiB
+	threads uint8
+}
+
+type argon2idParams struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.time, h.memory, h.threads, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.memory, h.time, h.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idHasher) Verify(encodedHash, password string) (bool, error) {
+	params, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	key := argon2.IDKey([]byte(password), params.salt, params.time, params.memory, params.threads, uint32(len(params.key)))
+	return subtle.ConstantTimeCompare(key, params.key) == 1, nil
+}
+
+func (h *argon2idHasher) NeedsRehash(encodedHash string) bool {
+	params, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return true
+	}
+	return params.time < h.time || params.memory < h.memory || params.threads < h.threads || len(params.key) < argon2KeyLength
+}
+
+func decodeArgon2id(encodedHash string) (*argon2idParams, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2id version")
+	}
+
+	params := &argon2idParams{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return nil, fmt.Errorf("invalid argon2id parameters")
+	}
+
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id salt")
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(params.key) == 0 {
+		return nil, fmt.Errorf("invalid argon2id hash")
+	}
+	return params, nil
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *bcryptHasher) Verify(encodedHash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *bcryptHasher) NeedsRehash(encodedHash string) bool {
+	cost, err := bcrypt.Cost([]byte(encodedHash))
+	if err != nil {
+		return true
+	}
+	return cost < h.cost
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"linke/config"
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// bcryptMaxPasswordBytes is the length after which bcrypt silently ignores input
+const bcryptMaxPasswordBytes = 72
+
+// PasswordPolicyService enforces the password rules shared by registration,
+// password change and password reset
+type PasswordPolicyService struct {
+	db       *gorm.DB
+	cfg      *config.Config
+	hasher   PasswordHasher
+	breached *breachedPasswordList
+}
+
+func NewPasswordPolicyService(db *gorm.DB, cfg *config.Config, hasher PasswordHasher) (*PasswordPolicyService, error) {
+	s := &PasswordPolicyService{
+		db:     db,
+		cfg:    cfg,
+		hasher: hasher,
+	}
+
+	if cfg.Password.BreachedListFile != "" {
+		list, err := loadBreachedPasswordList(cfg.Password.BreachedListFile)
+		if err != nil {
+			return nil, err
+		}
+		s.breached = list
+
+		logger.Info("Breached password list loaded",
+			logger.String("file", cfg.Password.BreachedListFile),
+			logger.Int("hashes", list.hashes),
+		)
+	}
+
+	return s, nil
+}
+
+// Validate checks a new password against the length, character class and breached list rules
+func (s *PasswordPolicyService) Validate(password string) error {
+	minLength := s.cfg.Password.MinLength
+	maxLength := s.cfg.Password.MaxLength
+	if maxLength <= 0 || (maxLength > bcryptMaxPasswordBytes && strings.EqualFold(s.cfg.Password.HashAlgorithm, PasswordHashBcrypt)) {
+		maxLength = bcryptMaxPasswordBytes
+	}
+
+	if len([]rune(password)) < minLength {
+		return fmt.Errorf("password must be at least %d characters", minLength)
+	}
+	if len(password) > maxLength {
+		return fmt.Errorf("password must be at most %d bytes", maxLength)
+	}
+
+	if classes := characterClasses(password); classes < s.cfg.Password.MinCharacterClasses {
+		return fmt.Errorf("password must contain at least %d of: lowercase letters, uppercase letters, digits, symbols", s.cfg.Password.MinCharacterClasses)
+	}
+
+	if s.breached != nil && s.breached.Contains(password) {
+		return fmt.Errorf("password has appeared in a data breach, please choose a different one")
+	}
+
+	return nil
+}
+
+// CheckReuse rejects the user's current password and the previous ones kept in the history
+func (s *PasswordPolicyService) CheckReuse(ctx context.Context, user *model.User, password string) error {
+	if s.cfg.Password.HistorySize <= 0 {
+		return nil
+	}
+
+	if user.Password != "" && s.matches(user.Password, password) {
+		return fmt.Errorf("new password must be different from your current password")
+	}
+
+	var history []*model.PasswordHistory
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", user.ID).
+		Order("created_at DESC, id DESC").
+		Limit(s.cfg.Password.HistorySize - 1).
+		Find(&history).Error; err != nil {
+		logger.Error("Failed to get password history",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return fmt.Errorf("failed to check password history")
+	}
+
+	for _, entry := range history {
+		if s.matches(entry.PasswordHash, password) {
+			return fmt.Errorf("password was used recently, please choose a different one")
+		}
+	}
+	return nil
+}
+
+// Remember stores the hash of a password that is being replaced and drops history
+// entries that fall out of the configured size. It runs on the given transaction.
+func (s *PasswordPolicyService) Remember(tx *gorm.DB, userID uint, oldHash string) error {
+	keep := s.cfg.Password.HistorySize - 1
+	if oldHash == "" || keep <= 0 {
+		return nil
+	}
+
+	if err := tx.Create(&model.PasswordHistory{
+		UserID:       userID,
+		PasswordHash: oldHash,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to save password history: %w", err)
+	}
+
+	var ids []uint
+	if err := tx.Model(&model.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+	if len(ids) > keep {
+		if err := tx.Delete(&model.PasswordHistory{}, ids[keep:]).Error; err != nil {
+			return fmt.Errorf("failed to prune password history: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *PasswordPolicyService) matches(encodedHash, password string) bool {
+	ok, err := s.hasher.Verify(encodedHash, password)
+	return err == nil && ok
+}
+
+// characterClasses counts how many of lowercase, uppercase, digit and symbol characters are present
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"linke/config"
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// passwordResetCooldown limits how often a reset email can be sent to the same user
+	passwordResetCooldown = time.Minute
+
+	passwordResetCooldownKeyPrefix = "password_reset_cooldown:"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"` // Checked against the password policy
+}
+
+// PasswordResetService issues and redeems password reset tokens for local accounts
+type PasswordResetService struct {
+	db             *gorm.DB
+	cfg            *config.Config
+	client         *redis.Client
+	userService    *UserService
+	sessionService *SessionService
+	emailService   *EmailService
+	passwordPolicy *PasswordPolicyService
+	passwordHasher PasswordHasher
+	auditService   *AuditService
+}
+
+func NewPasswordResetService(db *gorm.DB, cfg *config.Config, client *redis.Client, userService *UserService, sessionService *SessionService, emailService *EmailService, passwordPolicy *PasswordPolicyService, passwordHasher PasswordHasher, auditService *AuditService) *PasswordResetService {
+	return &PasswordResetService{
+		db:             db,
+		cfg:            cfg,
+		client:         client,
+		userService:    userService,
+		sessionService: sessionService,
+		emailService:   emailService,
+		passwordPolicy: passwordPolicy,
+		passwordHasher: passwordHasher,
+		auditService:   auditService,
+	}
+}
+
+// ForgotPassword emails a reset link to the local account with the given address.
+// It does not reveal whether the address is registered, so callers should always
+// report success unless an internal error occurred.
+func (s *PasswordResetService) ForgotPassword(ctx context.Context, email string, client ClientInfo) error {
+	user, err := s.userService.GetActiveUserByEmail(ctx, email)
+	if err != nil || !user.IsLocalAccount() {
+		logger.Info("Password reset requested for unknown or non-local account",
+			logger.String("email", email),
+		)
+		return nil
+	}
+
+	allowed, err := s.client.SetNX(ctx, fmt.Sprintf("%s%d", passwordResetCooldownKeyPrefix, user.ID), 1, passwordResetCooldown).Result()
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email")
+	}
+	if !allowed {
+		return nil
+	}
+
+	rawToken, err := generateOpaqueToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+
+	ttl := time.Duration(s.cfg.Auth.PasswordResetExpireMinutes) * time.Minute
+	resetToken := &model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(rawToken),
+		ExpiresAt: time.Now().Add(ttl),
+		IPAddress: client.IPAddress,
+		UserAgent: truncate(client.UserAgent, 500),
+	}
+	if err := s.db.WithContext(ctx).Create(resetToken).Error; err != nil {
+		logger.Error("Failed to create password reset token",
+			logger.Uint("user_id", user.ID),
+			logger.Error2("error", err),
+		)
+		return fmt.Errorf("failed to send password reset email")
+	}
+
+	link := s.cfg.Auth.PasswordResetURL + "?token=" + url.QueryEscape(rawToken)
+	body := fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %d minutes and can only be used once. If you did not request a password reset, you can ignore this email.",
+		user.Name, link, s.cfg.Auth.PasswordResetExpireMinutes)
+
+	if err := s.emailService.Send(ctx, user.Email, "Reset your password", body); err != nil {
+		return fmt.Errorf("failed to send password reset email")
+	}
+
+	logger.Info("Password reset email enqueued",
+		logger.Uint("user_id", user.ID),
+	)
+	return nil
+}
+
+// ResetPassword redeems a reset token, sets the new password and signs the user out
+// everywhere. Every other outstanding reset token of the user is invalidated as well.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, rawToken, newPassword string) error {
+	if err := s.passwordPolicy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(newPassword)
+	if err != nil {
+		logger.Error("Failed to hash new password", logger.Error2("error", err))
+		return fmt.Errorf("failed to process new password")
+	}
+
+	var userID uint
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var resetToken model.PasswordResetToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hashToken(rawToken)).
+			First(&resetToken).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("invalid or expired reset token")
+			}
+			return fmt.Errorf("failed to get reset token: %w", err)
+		}
+
+		if resetToken.IsUsed() || resetToken.IsExpired() {
+			return fmt.Errorf("invalid or expired reset token")
+		}
+
+		var user model.User
+		if err := tx.Where("id = ? AND status = ?", resetToken.UserID, model.UserStatusActive).First(&user).Error; err != nil {
+			return fmt.Errorf("user not found or inactive")
+		}
+		if !user.IsLocalAccount() {
+			return fmt.Errorf("password reset is only available for local accounts")
+		}
+
+		if err := s.passwordPolicy.CheckReuse(ctx, &user, newPassword); err != nil {
+			return err
+		}
+		if err := s.passwordPolicy.Remember(tx, user.ID, user.Password); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&model.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", now).Error; err != nil {
+			return fmt.Errorf("failed to redeem reset token: %w", err)
+		}
+
+		// Bumping the token version invalidates every access token issued so far
+		updates := map[string]interface{}{
+			"password":      hashedPassword,
+			"token_version": gorm.Expr("token_version + 1"),
+		}
+		// Receiving the reset email proves ownership of the address
+		if !user.IsEmailVerified() {
+			updates["email_verified_at"] = now
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+
+		userID = user.ID
+		return nil
+	})
+	if err != nil {
+		logger.Warn("Password reset failed",
+			logger.Error2("error", err),
+		)
+		return err
+	}
+
+	if err := s.sessionService.RevokeAllSessions(ctx, userID); err != nil {
+		logger.Error("Failed to revoke sessions after password reset",
+			logger.Uint("user_id", userID),
+			logger.Error2("error", err),
+		)
+	}
+
+	logger.Info("Password reset successfully",
+		logger.Uint("user_id", userID),
+	)
+	// The request is not authenticated; redeeming the emailed token identifies the actor
+	s.auditService.Record(WithAuditActor(ctx, userID), AuditEntry{
+		Action:     model.AuditActionUserPasswordReset,
+		TargetType: model.AuditTargetUser,
+		TargetID:   userID,
+	})
+	return nil
+}
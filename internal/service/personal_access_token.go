@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// personalAccessTokenTouchInterval limits how often last-used information is written
+	personalAccessTokenTouchInterval = time.Minute
+
+	// personalAccessTokenPrefixLength is the number of raw token characters stored for display
+	personalAccessTokenPrefixLength = 11
+
+	maxPersonalAccessTokensPerUser = 50
+)
+
+type PersonalAccessTokenService struct {
+	db           *gorm.DB
+	auditService *AuditService
+}
+
+// CreatePersonalAccessTokenRequest represents the request to create a personal access token
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100" example:"CI deploy"`
+	Scopes        []string `json:"scopes" binding:"required,min=1" example:"tasks:write"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0,max=3650" example:"90"` // 0 for a token that never expires
+}
+
+// UpdatePersonalAccessTokenRequest represents the request to rename a personal access token or change its scopes
+type UpdatePersonalAccessTokenRequest struct {
+	Name   string   `json:"name" binding:"omitempty,max=100" example:"CI deploy"`
+	Scopes []string `json:"scopes" example:"tasks:read"`
+}
+
+func NewPersonalAccessTokenService(db *gorm.DB, auditService *AuditService) *PersonalAccessTokenService {
+	return &PersonalAccessTokenService{db: db, auditService: auditService}
+}
+
+// Create issues a new token for the user. The raw token is returned only here.
+func (s *PersonalAccessTokenService) Create(ctx context.Context, userID uint, req *CreatePersonalAccessTokenRequest) (string, *model.PersonalAccessToken, error) {
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&model.PersonalAccessToken{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	if count >= maxPersonalAccessTokensPerUser {
+		return "", nil, fmt.Errorf("too many tokens, delete unused tokens first")
+	}
+
+	secret, err := generateOpaqueToken(32)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	raw := model.PersonalAccessTokenPrefix + secret
+
+	token := &model.PersonalAccessToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		TokenHash: hashToken(raw),
+		Prefix:    raw[:personalAccessTokenPrefixLength],
+		Scopes:    strings.Join(scopes, ","),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.db.WithContext(ctx).Create(token).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	logger.Info("Personal access token created",
+		logger.Uint("user_id", userID),
+		logger.Uint("token_id", token.ID),
+		logger.String("scopes", token.Scopes),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionAccessTokenCreate,
+		TargetType: model.AuditTargetPersonalAccessToken,
+		TargetID:   token.ID,
+		After:      token.ToResponse(),
+	})
+	return raw, token, nil
+}
+
+// List returns the user's tokens, most recent first
+func (s *PersonalAccessTokenService) List(ctx context.Context, userID uint) ([]*model.PersonalAccessToken, error) {
+	var tokens []*model.PersonalAccessToken
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Get returns one of the user's tokens
+func (s *PersonalAccessTokenService) Get(ctx context.Context, userID, id uint) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("token not found")
+	}
+	return &token, nil
+}
+
+// Update renames a token and/or replaces its scopes
+func (s *PersonalAccessTokenService) Update(ctx context.Context, token *model.PersonalAccessToken, req *UpdatePersonalAccessTokenRequest) (*model.PersonalAccessToken, error) {
+	updates := map[string]interface{}{}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		updates["name"] = name
+	}
+	if req.Scopes != nil {
+		scopes, err := normalizeScopes(req.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		updates["scopes"] = strings.Join(scopes, ",")
+	}
+	if len(updates) == 0 {
+		return token, nil
+	}
+
+	before := token.ToResponse()
+	if err := s.db.WithContext(ctx).Model(token).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update token: %w", err)
+	}
+
+	logger.Info("Personal access token updated",
+		logger.Uint("user_id", token.UserID),
+		logger.Uint("token_id", token.ID),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionAccessTokenUpdate,
+		TargetType: model.AuditTargetPersonalAccessToken,
+		TargetID:   token.ID,
+		Before:     before,
+		After:      token.ToResponse(),
+	})
+	return token, nil
+}
+
+// Delete revokes one of the user's tokens
+func (s *PersonalAccessTokenService) Delete(ctx context.Context, userID, id uint) error {
+	token, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.PersonalAccessToken{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("token not found")
+	}
+
+	logger.Info("Personal access token deleted",
+		logger.Uint("user_id", userID),
+		logger.Uint("token_id", id),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionAccessTokenDelete,
+		TargetType: model.AuditTargetPersonalAccessToken,
+		TargetID:   id,
+		Before:     token.ToResponse(),
+	})
+	return nil
+}
+
+// Authenticate looks up a raw token and records its use. It fails for unknown and expired tokens.
+func (s *PersonalAccessTokenService) Authenticate(ctx context.Context, raw, ipAddress string) (*model.PersonalAccessToken, error) {
+	if !strings.HasPrefix(raw, model.PersonalAccessTokenPrefix) {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	var token model.PersonalAccessToken
+	err := s.db.WithContext(ctx).Where("token_hash = ?", hashToken(raw)).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("invalid token")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up token: %w", err)
+	}
+
+	if token.IsExpired() {
+		return nil, fmt.Errorf("token has expired")
+	}
+
+	s.touch(ctx, &token, ipAddress)
+	return &token, nil
+}
+
+// touch updates the last-used time and IP address, at most once per interval
+func (s *PersonalAccessTokenService) touch(ctx context.Context, token *model.PersonalAccessToken, ipAddress string) {
+	if token.LastUsedAt != nil && time.Since(*token.LastUsedAt) < personalAccessTokenTouchInterval && ipAddress == token.LastUsedIP {
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"last_used_at": now, "last_used_ip": ipAddress}
+	if err := s.db.WithContext(ctx).Model(token).UpdateColumns(updates).Error; err != nil {
+		logger.Warn("Failed to update personal access token usage",
+			logger.Uint("token_id", token.ID),
+			logger.Error2("error", err),
+		)
+	}
+}
+
+// normalizeScopes validates requested scopes and removes duplicates
+func normalizeScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	seen := make(map[string]bool, len(requested))
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if !isKnownScope(scope) {
+			return nil, fmt.Errorf("unknown scope: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+func isKnownScope(scope string) bool {
+	for _, known := range model.PersonalAccessTokenScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
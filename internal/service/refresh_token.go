@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"linke/config"
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefreshTokenService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+func NewRefreshTokenService(db *gorm.DB, cfg *config.Config) *RefreshTokenService {
+	return &RefreshTokenService{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// Issue creates the first refresh token of a new token family for the session
+// and returns the raw token value.
+func (s *RefreshTokenService) Issue(ctx context.Context, userID, sessionID uint) (string, *model.RefreshToken, error) {
+	return s.issue(s.db.WithContext(ctx), userID, sessionID, "")
+}
+
+// Rotate exchanges a refresh token for a new one in the same family.
+// Presenting a token that was already rotated or revoked is treated as token theft
+// and revokes the whole family.
+func (s *RefreshTokenService) Rotate(ctx context.Context, rawToken string) (string, *model.RefreshToken, error) {
+	var newRaw string
+	var newToken *model.RefreshToken
+	var reusedFamily string
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current model.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hashToken(rawToken)).
+			First(&current).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("invalid refresh token")
+			}
+			return fmt.Errorf("failed to get refresh token: %w", err)
+		}
+
+		if current.IsRotated() || current.IsRevoked() {
+			reusedFamily = current.FamilyID
+			return fmt.Errorf("refresh token has already been used")
+		}
+
+		if current.IsExpired() {
+			return fmt.Errorf("refresh token has expired")
+		}
+
+		now := time.Now()
+		if err := tx.Model(&current).Update("rotated_at", now).Error; err != nil {
+			return fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+
+		raw, token, err := s.issue(tx, current.UserID, current.SessionID, current.FamilyID)
+		if err != nil {
+			return err
+		}
+		newRaw, newToken = raw, token
+		return nil
+	})
+
+	if reusedFamily != "" {
+		logger.Warn("Refresh token reuse detected, revoking token family",
+			logger.String("family_id", reusedFamily),
+		)
+		if revokeErr := s.RevokeFamily(ctx, reusedFamily); revokeErr != nil {
+			logger.Error("Failed to revoke refresh token family",
+				logger.String("family_id", reusedFamily),
+				logger.Error2("error", revokeErr),
+			)
+		}
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return newRaw, newToken, nil
+}
+
+// RevokeFamily revokes every active token in the given family together with the session it belongs to
+func (s *RefreshTokenService) RevokeFamily(ctx context.Context, familyID string) error {
+	now := time.Now()
+
+	var sessionIDs []uint
+	if err := s.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("family_id = ?", familyID).
+		Distinct().Pluck("session_id", &sessionIDs).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	if len(sessionIDs) > 0 {
+		if err := s.db.WithContext(ctx).Model(&model.Session{}).
+			Where("id IN ? AND revoked_at IS NULL", sessionIDs).
+			Update("revoked_at", now).Error; err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+	return nil
+}
+
+// RevokeSession revokes every active refresh token bound to the session
+func (s *RefreshTokenService) RevokeSession(ctx context.Context, sessionID uint) error {
+	if err := s.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// Revoke revokes the family of the given raw refresh token, e.g. on logout
+func (s *RefreshTokenService) Revoke(ctx context.Context, rawToken string) error {
+	var token model.RefreshToken
+	if err := s.db.WithContext(ctx).Where("token_hash = ?", hashToken(rawToken)).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("invalid refresh token")
+		}
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return s.RevokeFamily(ctx, token.FamilyID)
+}
+
+// RevokeAllForUser revokes every active refresh token belonging to the user
+func (s *RefreshTokenService) RevokeAllForUser(ctx context.Context, userID uint) error {
+	if err := s.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
+
+func (s *RefreshTokenService) issue(db *gorm.DB, userID, sessionID uint, familyID string) (string, *model.RefreshToken, error) {
+	raw, err := generateOpaqueToken(32)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	if familyID == "" {
+		familyID, err = generateOpaqueToken(24)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to generate token family: %w", err)
+		}
+	}
+
+	token := &model.RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: hashToken(raw),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(time.Duration(s.cfg.JWT.RefreshExpireHours) * time.Hour),
+	}
+
+	if err := db.Create(token).Error; err != nil {
+		logger.Error("Failed to store refresh token",
+			logger.Uint("user_id", userID),
+			logger.Error2("error", err),
+		)
+		return "", nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return raw, token, nil
+}
+
+// generateOpaqueToken returns a URL-safe random string built from n random bytes
+func generateOpaqueToken(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// hashToken returns the SHA-256 hex digest used to look up an opaque token
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
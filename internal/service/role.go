@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rolePermissionCacheTTL bounds how long permission changes made by another instance take to apply
+const rolePermissionCacheTTL = 30 * time.Second
+
+var (
+	// ErrRoleNotFound is returned when a role does not exist
+	ErrRoleNotFound = errors.New("role not found")
+
+	// ErrRoleExists is returned when creating a role with a name that is already taken
+	ErrRoleExists = errors.New("a role with this name already exists")
+
+	// ErrRoleInUse is returned when deleting a role that is still assigned to users
+	ErrRoleInUse = errors.New("role is still assigned to users")
+
+	// ErrBuiltInRole is returned when deleting one of the built-in roles
+	ErrBuiltInRole = errors.New("built-in roles cannot be deleted")
+
+	// ErrPermissionNotHeld is returned when an actor tries to grant, revoke or assign permissions
+	// they do not have themselves
+	ErrPermissionNotHeld = errors.New("you can only grant permissions you hold yourself")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// RoleService manages roles and answers permission checks for users
+type RoleService struct {
+	db           *gorm.DB
+	auditService *AuditService
+
+	mu    sync.RWMutex
+	cache map[string]*rolePermissionCacheEntry
+}
+
+type rolePermissionCacheEntry struct {
+	permissions map[string]bool
+	loadedAt    time.Time
+}
+
+// CreateRoleRequest represents the request to create a role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=20" example:"support"`
+	Description string   `json:"description" binding:"max=255" example:"Customer support"`
+	Permissions []string `json:"permissions" example:"users:read"`
+}
+
+// UpdateRoleRequest represents the request to change a role's description and/or permissions
+type UpdateRoleRequest struct {
+	Description string   `json:"description" binding:"omitempty,max=255" example:"Customer support"`
+	Permissions []string `json:"permissions" example:"users:read"`
+}
+
+// UserPermissionsResponse lists the role and permissions of a user
+type UserPermissionsResponse struct {
+	Role        string   `json:"role" example:"user"`
+	Permissions []string `json:"permissions" example:"tasks:enqueue"`
+}
+
+func NewRoleService(db *gorm.DB, auditService *AuditService) *RoleService {
+	return &RoleService{
+		db:           db,
+		auditService: auditService,
+		cache:        make(map[string]*rolePermissionCacheEntry),
+	}
+}
+
+// HasPermission reports whether the user's role grants permission. Inactive users have no permissions.
+func (s *RoleService) HasPermission(ctx context.Context, user *model.User, permission string) (bool, error) {
+	if !user.IsActive() {
+		return false, nil
+	}
+
+	permissions, err := s.rolePermissions(ctx, user.Role)
+	if err != nil {
+		return false, err
+	}
+	return permissions[permission], nil
+}
+
+// GetUserPermissions returns the permissions granted to the user through their role
+func (s *RoleService) GetUserPermissions(ctx context.Context, user *model.User) (*UserPermissionsResponse, error) {
+	result := &UserPermissionsResponse{Role: user.Role, Permissions: []string{}}
+	if !user.IsActive() {
+		return result, nil
+	}
+
+	permissions, err := s.rolePermissions(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range model.AllPermissions {
+		if permissions[permission] {
+			result.Permissions = append(result.Permissions, permission)
+		}
+	}
+	return result, nil
+}
+
+// ListRoles returns all roles, built-in roles first
+func (s *RoleService) ListRoles(ctx context.Context) ([]*model.Role, error) {
+	var roles []*model.Role
+	if err := s.db.WithContext(ctx).Order("built_in DESC, name ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+// GetRole returns a role by ID
+func (s *RoleService) GetRole(ctx context.Context, id uint) (*model.Role, error) {
+	var role model.Role
+	err := s.db.WithContext(ctx).First(&role, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return &role, nil
+}
+
+// CreateRole creates a custom role. The actor must hold every permission the role grants.
+func (s *RoleService) CreateRole(ctx context.Context, actor *model.User, req *CreateRoleRequest) (*model.Role, error) {
+	name := strings.TrimSpace(req.Name)
+	if !roleNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("role name must start with a lowercase letter and contain only lowercase letters, digits, '-' and '_'")
+	}
+
+	permissions, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requirePermissions(ctx, actor, permissions); err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&model.Role{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check role name: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrRoleExists
+	}
+
+	role := &model.Role{
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		Permissions: strings.Join(permissions, ","),
+	}
+	if err := s.db.WithContext(ctx).Create(role).Error; err != nil {
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	s.invalidate(role.Name)
+	logger.Info("Role created",
+		logger.Uint("actor_id", actor.ID),
+		logger.String("role", role.Name),
+		logger.String("permissions", role.Permissions),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionRoleCreate,
+		TargetType: model.AuditTargetRole,
+		TargetID:   role.ID,
+		After:      role.ToResponse(),
+	})
+	return role, nil
+}
+
+// UpdateRole changes a role's description and/or replaces its permissions. The actor must hold
+// every permission the role grants before and after the change. The permissions of the
+// built-in admin role cannot be changed.
+func (s *RoleService) UpdateRole(ctx context.Context, actor *model.User, id uint, req *UpdateRoleRequest) (*model.Role, error) {
+	role, err := s.GetRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		updates["description"] = description
+	}
+	if req.Permissions != nil {
+		if role.Name == model.UserRoleAdmin {
+			return nil, fmt.Errorf("the permissions of the admin role cannot be changed")
+		}
+
+		permissions, err := normalizePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.requirePermissions(ctx, actor, append(role.PermissionList(), permissions...)); err != nil {
+			return nil, err
+		}
+		updates["permissions"] = strings.Join(permissions, ",")
+	}
+	if len(updates) == 0 {
+		return role, nil
+	}
+
+	before := role.ToResponse()
+	if err := s.db.WithContext(ctx).Model(role).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	s.invalidate(role.Name)
+	logger.Info("Role updated",
+		logger.Uint("actor_id", actor.ID),
+		logger.String("role", role.Name),
+		logger.String("permissions", role.Permissions),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionRoleUpdate,
+		TargetType: model.AuditTargetRole,
+		TargetID:   role.ID,
+		Before:     before,
+		After:      role.ToResponse(),
+	})
+	return role, nil
+}
+
+// DeleteRole deletes a custom role that is not assigned to any user
+func (s *RoleService) DeleteRole(ctx context.Context, actor *model.User, id uint) error {
+	role, err := s.GetRole(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return ErrBuiltInRole
+	}
+	if err := s.requirePermissions(ctx, actor, role.PermissionList()); err != nil {
+		return err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Soft-deleted users count too, they keep their role when restored
+		var count int64
+		if err := tx.Unscoped().Model(&model.User{}).Where("role = ?", role.Name).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count users of role: %w", err)
+		}
+		if count > 0 {
+			return ErrRoleInUse
+		}
+
+		if err := tx.Delete(role).Error; err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidate(role.Name)
+	logger.Info("Role deleted",
+		logger.Uint("actor_id", actor.ID),
+		logger.String("role", role.Name),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionRoleDelete,
+		TargetType: model.AuditTargetRole,
+		TargetID:   role.ID,
+		Before:     role.ToResponse(),
+	})
+	return nil
+}
+
+// AssignRole changes the role of a user. The actor must hold every permission of both the
+// user's current role and the new one, and cannot change their own role.
+func (s *RoleService) AssignRole(ctx context.Context, actor *model.User, userID uint, roleName string) (*model.User, error) {
+	if actor.ID == userID {
+		return nil, fmt.Errorf("you cannot change your own role")
+	}
+
+	var role model.Role
+	err := s.db.WithContext(ctx).Where("name = ?", roleName).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	if err := s.requirePermissions(ctx, actor, role.PermissionList()); err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	var previousRole string
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return fmt.Errorf("user not found")
+		}
+		if user.Role == role.Name {
+			return nil
+		}
+		previousRole = user.Role
+
+		// Taking a role away needs the same permissions as handing it out
+		if err := s.RequireRolePermissions(ctx, actor, user.Role); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&user).Update("role", role.Name).Error; err != nil {
+			return fmt.Errorf("failed to update user role: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if previousRole == "" {
+		return &user, nil
+	}
+
+	logger.Info("User role updated successfully",
+		logger.Uint("actor_id", actor.ID),
+		logger.Uint("user_id", userID),
+		logger.String("new_role", role.Name),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserRoleAssign,
+		TargetType: model.AuditTargetUser,
+		TargetID:   userID,
+		Before:     map[string]string{"role": previousRole},
+		After:      map[string]string{"role": role.Name},
+	})
+	return &user, nil
+}
+
+// RequireRolePermissions fails with ErrPermissionNotHeld unless the actor holds every permission of the named role
+func (s *RoleService) RequireRolePermissions(ctx context.Context, actor *model.User, roleName string) error {
+	granted, err := s.rolePermissions(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	permissions := make([]string, 0, len(granted))
+	for permission := range granted {
+		permissions = append(permissions, permission)
+	}
+	return s.requirePermissions(ctx, actor, permissions)
+}
+
+// requirePermissions fails with ErrPermissionNotHeld unless the actor holds every one of permissions
+func (s *RoleService) requirePermissions(ctx context.Context, actor *model.User, permissions []string) error {
+	for _, permission := range permissions {
+		ok, err := s.HasPermission(ctx, actor, permission)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrPermissionNotHeld, permission)
+		}
+	}
+	return nil
+}
+
+// rolePermissions returns the permission set of the named role, from the cache when it is fresh.
+// Unknown roles have no permissions.
+func (s *RoleService) rolePermissions(ctx context.Context, name string) (map[string]bool, error) {
+	s.mu.RLock()
+	entry, ok := s.cache[name]
+	s.mu.RUnlock()
+	if ok && time.Since(entry.loadedAt) < rolePermissionCacheTTL {
+		return entry.permissions, nil
+	}
+
+	var role model.Role
+	err := s.db.WithContext(ctx).Where("name = ?", name).First(&role).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load role: %w", err)
+	}
+
+	permissions := make(map[string]bool)
+	for _, permission := range role.PermissionList() {
+		permissions[permission] = true
+	}
+
+	s.mu.Lock()
+	s.cache[name] = &rolePermissionCacheEntry{permissions: permissions, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return permissions, nil
+}
+
+func (s *RoleService) invalidate(name string) {
+	s.mu.Lock()
+	delete(s.cache, name)
+	s.mu.Unlock()
+}
+
+// normalizePermissions validates requested permissions and removes duplicates
+func normalizePermissions(requested []string) ([]string, error) {
+	seen := make(map[string]bool, len(requested))
+	permissions := make([]string, 0, len(requested))
+	for _, permission := range requested {
+		permission = strings.TrimSpace(permission)
+		if !isKnownPermission(permission) {
+			return nil, fmt.Errorf("unknown permission: %s", permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions, nil
+}
+
+func isKnownPermission(permission string) bool {
+	for _, known := range model.AllPermissions {
+		if permission == known {
+			return true
+		}
+	}
+	return false
+}
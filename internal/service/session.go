@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"linke/internal/logger"
+	"linke/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// sessionTouchInterval limits how often LastSeenAt is written for an active session
+const sessionTouchInterval = 5 * time.Minute
+
+// ClientInfo describes the client a login or token request came from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+type SessionService struct {
+	db                  *gorm.DB
+	refreshTokenService *RefreshTokenService
+	auditService        *AuditService
+}
+
+func NewSessionService(db *gorm.DB, refreshTokenService *RefreshTokenService, auditService *AuditService) *SessionService {
+	return &SessionService{
+		db:                  db,
+		refreshTokenService: refreshTokenService,
+		auditService:        auditService,
+	}
+}
+
+// CreateSession records a new login for the user
+func (s *SessionService) CreateSession(ctx context.Context, userID uint, provider string, client ClientInfo) (*model.Session, error) {
+	session := &model.Session{
+		UserID:     userID,
+		Provider:   provider,
+		Device:     describeDevice(client.UserAgent),
+		UserAgent:  truncate(client.UserAgent, 255),
+		IPAddress:  client.IPAddress,
+		LastSeenAt: time.Now(),
+	}
+
+	if err := s.create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// CreateImpersonationSession records a session in which the impersonator acts as the user.
+// The session ends at expiresAt and is listed among the user's sessions.
+func (s *SessionService) CreateImpersonationSession(ctx context.Context, userID, impersonatorID uint, expiresAt time.Time, client ClientInfo) (*model.Session, error) {
+	session := &model.Session{
+		UserID:         userID,
+		Provider:       model.SessionProviderImpersonation,
+		ImpersonatorID: &impersonatorID,
+		Device:         describeDevice(client.UserAgent),
+		UserAgent:      truncate(client.UserAgent, 255),
+		IPAddress:      client.IPAddress,
+		LastSeenAt:     time.Now(),
+		ExpiresAt:      &expiresAt,
+	}
+
+	if err := s.create(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *SessionService) create(ctx context.Context, session *model.Session) error {
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		logger.Error("Failed to create session",
+			logger.Uint("user_id", session.UserID),
+			logger.Error2("error", err),
+		)
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// GetActiveSession retrieves a session that has neither been revoked nor expired
+func (s *SessionService) GetActiveSession(ctx context.Context, id uint) (*model.Session, error) {
+	var session model.Session
+	if err := s.db.WithContext(ctx).
+		Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", time.Now()).
+		First(&session, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("session not found or revoked")
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &session, nil
+}
+
+// Touch updates the session's last activity time and IP address, at most once per sessionTouchInterval
+func (s *SessionService) Touch(ctx context.Context, session *model.Session, ipAddress string) {
+	if time.Since(session.LastSeenAt) < sessionTouchInterval && (ipAddress == "" || ipAddress == session.IPAddress) {
+		return
+	}
+
+	updates := map[string]interface{}{"last_seen_at": time.Now()}
+	if ipAddress != "" {
+		updates["ip_address"] = ipAddress
+	}
+
+	if err := s.db.WithContext(ctx).Model(session).Updates(updates).Error; err != nil {
+		logger.Warn("Failed to update session activity",
+			logger.Uint("session_id", session.ID),
+			logger.Error2("error", err),
+		)
+	}
+}
+
+// ListActiveSessions lists the user's sessions that have neither been revoked nor expired, most recent first
+func (s *SessionService) ListActiveSessions(ctx context.Context, userID uint) ([]*model.Session, error) {
+	var sessions []*model.Session
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a session belonging to the user along with its refresh tokens
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID uint) error {
+	result := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		logger.Error("Failed to revoke session",
+			logger.Uint("session_id", sessionID),
+			logger.Error2("error", result.Error),
+		)
+		return fmt.Errorf("failed to revoke session: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	if err := s.refreshTokenService.RevokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	logger.Info("Session revoked",
+		logger.Uint("user_id", userID),
+		logger.Uint("session_id", sessionID),
+	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionSessionRevoke,
+		TargetType: model.AuditTargetSession,
+		TargetID:   sessionID,
+		Before:     map[string]uint{"user_id": userID},
+	})
+	return nil
+}
+
+// RevokeAllSessions revokes every active session of the user
+func (s *SessionService) RevokeAllSessions(ctx context.Context, userID uint) error {
+	if err := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return s.refreshTokenService.RevokeAllForUser(ctx, userID)
+}
+
+// describeDevice derives a short "Browser on OS" description from a user agent string
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	ua := strings.ToLower(userAgent)
+
+	platform := "Unknown OS"
+	switch {
+	case strings.Contains(ua, "iphone"):
+		platform = "iPhone"
+	case strings.Contains(ua, "ipad"):
+		platform = "iPad"
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os x"), strings.Contains(ua, "macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+
+	browser := ""
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/"), strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	case strings.Contains(ua, "okhttp"), strings.Contains(ua, "dart"), strings.Contains(ua, "cfnetwork"):
+		browser = "App"
+	}
+
+	if browser == "" {
+		return platform
+	}
+	return browser + " on " + platform
+}
+
+// truncate shortens s to at most max bytes
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const tokenRevocationKeyPrefix = "revoked_jti:"
+
+// TokenRevocationService keeps a Redis-backed deny list of access token IDs (jti).
+// Entries expire together with the token they revoke, so the list never outgrows
+// the set of tokens that could still be presented.
+type TokenRevocationService struct {
+	client *redis.Client
+}
+
+func NewTokenRevocationService(client *redis.Client) *TokenRevocationService {
+	return &TokenRevocationService{
+		client: client,
+	}
+}
+
+// Revoke adds a token ID to the revocation list until the token's expiry time
+func (s *TokenRevocationService) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return fmt.Errorf("token has no jti claim")
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		// Token is already expired, nothing to revoke
+		return nil
+	}
+
+	if err := s.client.Set(ctx, tokenRevocationKeyPrefix+jti, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// Claim atomically revokes a single-use token ID until the token's expiry time. It returns false
+// if the token was already revoked, i.e. another request used it first.
+func (s *TokenRevocationService) Claim(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	if jti == "" {
+		return false, fmt.Errorf("token has no jti claim")
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return false, nil
+	}
+
+	claimed, err := s.client.SetNX(ctx, tokenRevocationKeyPrefix+jti, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim token: %w", err)
+	}
+	return claimed, nil
+}
+
+// IsRevoked checks if a token ID is on the revocation list
+func (s *TokenRevocationService) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	count, err := s.client.Exists(ctx, tokenRevocationKeyPrefix+jti).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"linke/config"
+	"linke/internal/model"
+)
+
+// rfc6238Secret is the SHA-1 seed of RFC 6238 Appendix B, "12345678901234567890", base32 encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// The last six digits of the eight digit SHA-1 values in RFC 6238 Appendix B
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		code, err := totpCode(rfc6238Secret, totpStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("totpCode(%d): %v", tt.unix, err)
+		}
+		if code != tt.code {
+			t.Errorf("totpCode(%d) = %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := totpStep(now)
+
+	for offset := int64(-3); offset <= 3; offset++ {
+		code, err := totpCode(rfc6238Secret, current+offset)
+		if err != nil {
+			t.Fatalf("totpCode: %v", err)
+		}
+
+		step, ok := validateTOTP(rfc6238Secret, code, now)
+		inWindow := offset >= -totpSkewSteps && offset <= totpSkewSteps
+		if ok != inWindow {
+			t.Errorf("validateTOTP with a code %d steps off = %v, want %v", offset, ok, inWindow)
+		}
+		if ok && step != current+offset {
+			t.Errorf("validateTOTP step = %d, want %d", step, current+offset)
+		}
+	}
+
+	if _, ok := validateTOTP(rfc6238Secret, "12345", now); ok {
+		t.Error("validateTOTP accepted a code with too few digits")
+	}
+}
+
+func newTestMFAService(t *testing.T) (*MFAService, *model.User) {
+	t.Helper()
+	db := newTestDB(t)
+	cfg := &config.Config{MFA: config.MFAConfig{
+		Issuer:    "Linke",
+		SecretKey: "test-key-with-at-least-32-characters",
+	}}
+	s, err := NewMFAService(db, cfg, newTestRedis(t), NewAuditService(db))
+	if err != nil {
+		t.Fatalf("NewMFAService: %v", err)
+	}
+	return s, newTestUser(t, db, "alice@example.com")
+}
+
+// enableTOTP enrolls user and returns the plaintext secret
+func enableTOTP(t *testing.T, s *MFAService, user *model.User) string {
+	t.Helper()
+	ctx := context.Background()
+
+	enrollment, err := s.BeginTOTPEnrollment(ctx, user)
+	if err != nil {
+		t.Fatalf("BeginTOTPEnrollment: %v", err)
+	}
+	if err := s.db.First(user, user.ID).Error; err != nil {
+		t.Fatalf("reload user: %v", err)
+	}
+
+	code, err := totpCode(enrollment.Secret, totpStep(time.Now()))
+	if err != nil {
+		t.Fatalf("totpCode: %v", err)
+	}
+	if _, err := s.ConfirmTOTPEnrollment(ctx, user, code); err != nil {
+		t.Fatalf("ConfirmTOTPEnrollment: %v", err)
+	}
+	if err := s.db.First(user, user.ID).Error; err != nil {
+		t.Fatalf("reload user: %v", err)
+	}
+	return enrollment.Secret
+}
+
+func TestTOTPSecretEncryptedAtRest(t *testing.T) {
+	s, user := newTestMFAService(t)
+	secret := enableTOTP(t, s, user)
+
+	if user.TOTPSecret == "" || strings.Contains(user.TOTPSecret, secret) {
+		t.Fatalf("stored TOTP secret %q is not encrypted", user.TOTPSecret)
+	}
+	if opened, err := s.secrets.open(user.ID, user.TOTPSecret); err != nil || opened != secret {
+		t.Fatalf("open = %q, %v, want the enrolled secret", opened, err)
+	}
+	// The ciphertext is bound to the user it belongs to
+	if _, err := s.secrets.open(user.ID+1, user.TOTPSecret); err == nil {
+		t.Fatal("secret of one user decrypted for another")
+	}
+
+	if _, err := newTOTPSecretBox("too-short"); err == nil {
+		t.Fatal("newTOTPSecretBox accepted a short key")
+	}
+}
+
+func TestVerifyCodeRejectsReplay(t *testing.T) {
+	ctx := context.Background()
+	s, user := newTestMFAService(t)
+	secret := enableTOTP(t, s, user)
+
+	// Confirming the enrollment used up the step of its code
+	current := user.TOTPLastUsedStep
+	code, err := totpCode(secret, current)
+	if err != nil {
+		t.Fatalf("totpCode: %v", err)
+	}
+	if err := s.VerifyCode(ctx, user, code); err == nil {
+		t.Fatal("VerifyCode accepted the code used to confirm enrollment")
+	}
+
+	next, err := totpCode(secret, current+1)
+	if err != nil {
+		t.Fatalf("totpCode: %v", err)
+	}
+	if err := s.VerifyCode(ctx, user, next); err != nil {
+		t.Fatalf("VerifyCode: %v", err)
+	}
+	if user.TOTPLastUsedStep != current+1 {
+		t.Fatalf("TOTPLastUsedStep = %d, want %d", user.TOTPLastUsedStep, current+1)
+	}
+	if err := s.VerifyCode(ctx, user, next); err == nil {
+		t.Fatal("VerifyCode accepted the same code twice")
+	}
+
+	// Codes from steps before the last used one stay rejected while they are inside the skew window
+	previous, err := totpCode(secret, current-1)
+	if err != nil {
+		t.Fatalf("totpCode: %v", err)
+	}
+	if err := s.VerifyCode(ctx, user, previous); err == nil {
+		t.Fatal("VerifyCode accepted a code older than the last used one")
+	}
+}
@@ -13,12 +13,14 @@ import (
 )
 
 type UserService struct {
-	db *gorm.DB
+	db           *gorm.DB
+	auditService *AuditService
 }
 
-func NewUserService(db *gorm.DB) *UserService {
+func NewUserService(db *gorm.DB, auditService *AuditService) *UserService {
 	return &UserService{
-		db: db,
+		db:           db,
+		auditService: auditService,
 	}
 }
 
@@ -105,7 +107,11 @@ func (s *UserService) GetActiveUserByEmail(ctx context.Context, email string) (*
 
 // UpdateUser updates a user
 func (s *UserService) UpdateUser(ctx context.Context, user *model.User) error {
-	if err := s.db.WithContext(ctx).Save(user).Error; err != nil {
+	before := s.findUserForAudit(ctx, user.ID)
+
+	// Only profile fields are written. Roles go through RoleService.AssignRole, and credentials,
+	// token_version and TOTP columns through their own flows, so a stale or partial user cannot reset them.
+	if err := s.db.WithContext(ctx).Model(user).Select(userProfileFields).Updates(user).Error; err != nil {
 		logger.Error("Failed to update user",
 			logger.Uint("user_id", user.ID),
 			logger.Error2("error", err),
@@ -116,11 +122,45 @@ func (s *UserService) UpdateUser(ctx context.Context, user *model.User) error {
 	logger.Info("User updated successfully",
 		logger.Uint("user_id", user.ID),
 	)
+
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserUpdate,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID,
+		Before:     before,
+		After:      s.findUserForAudit(ctx, user.ID),
+	})
+	return nil
+}
+
+// userProfileFields are the columns UpdateUser writes
+var userProfileFields = []string{"email", "username", "name", "avatar", "updated_at"}
+
+// BumpTokenVersion increments a user's token version, invalidating all previously issued access tokens
+func (s *UserService) BumpTokenVersion(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Update("token_version", gorm.Expr("token_version + 1"))
+	if result.Error != nil {
+		logger.Error("Failed to bump token version",
+			logger.Uint("user_id", id),
+			logger.Error2("error", result.Error),
+		)
+		return fmt.Errorf("failed to bump token version: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("User token version bumped",
+		logger.Uint("user_id", id),
+	)
 	return nil
 }
 
 // SoftDeleteUser performs soft delete on a user
 func (s *UserService) SoftDeleteUser(ctx context.Context, id uint) error {
+	before := s.findUserForAudit(ctx, id)
 	result := s.db.WithContext(ctx).Delete(&model.User{}, id)
 	if result.Error != nil {
 		logger.Error("Failed to soft delete user",
@@ -137,11 +177,20 @@ func (s *UserService) SoftDeleteUser(ctx context.Context, id uint) error {
 	logger.Info("User soft deleted successfully",
 		logger.Uint("user_id", id),
 	)
+
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserDelete,
+		TargetType: model.AuditTargetUser,
+		TargetID:   id,
+		Before:     before,
+		After:      s.findUserForAudit(ctx, id),
+	})
 	return nil
 }
 
 // RestoreUser restores a soft deleted user
 func (s *UserService) RestoreUser(ctx context.Context, id uint) error {
+	before := s.findUserForAudit(ctx, id)
 	result := s.db.WithContext(ctx).Unscoped().Model(&model.User{}).Where("id = ?", id).Update("deleted_at", nil)
 	if result.Error != nil {
 		logger.Error("Failed to restore user",
@@ -158,6 +207,14 @@ func (s *UserService) RestoreUser(ctx context.Context, id uint) error {
 	logger.Info("User restored successfully",
 		logger.Uint("user_id", id),
 	)
+
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserRestore,
+		TargetType: model.AuditTargetUser,
+		TargetID:   id,
+		Before:     before,
+		After:      s.findUserForAudit(ctx, id),
+	})
 	return nil
 }
 
@@ -203,6 +260,7 @@ func (s *UserService) ListDeletedUsers(ctx context.Context, limit, offset int) (
 
 // HardDeleteUser permanently deletes a user
 func (s *UserService) HardDeleteUser(ctx context.Context, id uint) error {
+	before := s.findUserForAudit(ctx, id)
 	result := s.db.WithContext(ctx).Unscoped().Delete(&model.User{}, id)
 	if result.Error != nil {
 		logger.Error("Failed to hard delete user",
@@ -219,6 +277,13 @@ func (s *UserService) HardDeleteUser(ctx context.Context, id uint) error {
 	logger.Warn("User permanently deleted",
 		logger.Uint("user_id", id),
 	)
+
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserHardDelete,
+		TargetType: model.AuditTargetUser,
+		TargetID:   id,
+		Before:     before,
+	})
 	return nil
 }
 
@@ -262,6 +327,7 @@ func (s *UserService) UpdateUserStatus(ctx context.Context, id uint, status stri
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	before := user
 	user.Status = status
 	if err := s.db.WithContext(ctx).Save(&user).Error; err != nil {
 		logger.Error("Failed to update user status",
@@ -276,33 +342,14 @@ func (s *UserService) UpdateUserStatus(ctx context.Context, id uint, status stri
 		logger.Uint("user_id", id),
 		logger.String("new_status", status),
 	)
-	return &user, nil
-}
-
-// UpdateUserRole updates a user's role
-func (s *UserService) UpdateUserRole(ctx context.Context, id uint, role string) (*model.User, error) {
-	var user model.User
-	if err := s.db.WithContext(ctx).First(&user, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
-		}
-		return nil, fmt.Errorf("failed to get user: %w", err)
-	}
-
-	user.Role = role
-	if err := s.db.WithContext(ctx).Save(&user).Error; err != nil {
-		logger.Error("Failed to update user role",
-			logger.Uint("user_id", id),
-			logger.String("role", role),
-			logger.Error2("error", err),
-		)
-		return nil, fmt.Errorf("failed to update user role: %w", err)
-	}
 
-	logger.Info("User role updated successfully",
-		logger.Uint("user_id", id),
-		logger.String("new_role", role),
-	)
+	s.auditService.Record(ctx, AuditEntry{
+		Action:     model.AuditActionUserStatusUpdate,
+		TargetType: model.AuditTargetUser,
+		TargetID:   id,
+		Before:     &before,
+		After:      &user,
+	})
 	return &user, nil
 }
 
@@ -382,15 +429,16 @@ func (s *UserService) BatchDeleteUsers(ctx context.Context, ids []uint) (*BatchO
 		return nil, fmt.Errorf("failed to validate users: %w", err)
 	}
 
-	// Create map of existing user IDs
-	existingIDs := make(map[uint]bool)
-	for _, user := range existingUsers {
-		existingIDs[user.ID] = true
+	// Create map of existing users
+	existingByID := make(map[uint]*model.User)
+	for i := range existingUsers {
+		existingByID[existingUsers[i].ID] = &existingUsers[i]
 	}
 
 	// Delete existing users and track failed IDs
 	for _, id := range ids {
-		if !existingIDs[id] {
+		before, exists := existingByID[id]
+		if !exists {
 			result.FailedIDs = append(result.FailedIDs, id)
 			continue
 		}
@@ -407,6 +455,13 @@ func (s *UserService) BatchDeleteUsers(ctx context.Context, ids []uint) (*BatchO
 
 		if deleteResult.RowsAffected > 0 {
 			result.DeletedCount++
+			s.auditService.Record(ctx, AuditEntry{
+				Action:     model.AuditActionUserDelete,
+				TargetType: model.AuditTargetUser,
+				TargetID:   id,
+				Before:     before,
+				After:      s.findUserForAudit(ctx, id),
+			})
 		} else {
 			result.FailedIDs = append(result.FailedIDs, id)
 		}
@@ -430,15 +485,16 @@ func (s *UserService) BatchRestoreUsers(ctx context.Context, ids []uint) (*Batch
 		return nil, fmt.Errorf("failed to validate deleted users: %w", err)
 	}
 
-	// Create map of existing deleted user IDs
-	deletedIDs := make(map[uint]bool)
-	for _, user := range deletedUsers {
-		deletedIDs[user.ID] = true
+	// Create map of existing deleted users
+	deletedByID := make(map[uint]*model.User)
+	for i := range deletedUsers {
+		deletedByID[deletedUsers[i].ID] = &deletedUsers[i]
 	}
 
 	// Restore deleted users and track failed IDs
 	for _, id := range ids {
-		if !deletedIDs[id] {
+		before, deleted := deletedByID[id]
+		if !deleted {
 			result.FailedIDs = append(result.FailedIDs, id)
 			continue
 		}
@@ -455,6 +511,13 @@ func (s *UserService) BatchRestoreUsers(ctx context.Context, ids []uint) (*Batch
 
 		if restoreResult.RowsAffected > 0 {
 			result.RestoredCount++
+			s.auditService.Record(ctx, AuditEntry{
+				Action:     model.AuditActionUserRestore,
+				TargetType: model.AuditTargetUser,
+				TargetID:   id,
+				Before:     before,
+				After:      s.findUserForAudit(ctx, id),
+			})
 		} else {
 			result.FailedIDs = append(result.FailedIDs, id)
 		}
@@ -492,4 +555,14 @@ func (s *UserService) ListUsersByProvider(ctx context.Context, provider string,
 	}
 
 	return users, total, nil
-}
\ No newline at end of file
+}
+
+// findUserForAudit loads a user, including soft deleted ones, to capture its state in the audit log.
+// It returns nil if the user cannot be loaded.
+func (s *UserService) findUserForAudit(ctx context.Context, id uint) *model.User {
+	var user model.User
+	if err := s.db.WithContext(ctx).Unscoped().First(&user, id).Error; err != nil {
+		return nil
+	}
+	return &user
+}